@@ -0,0 +1,153 @@
+package migrate
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
+	bunmigrate "github.com/uptrace/bun/migrate"
+
+	"github.com/penguin-statistics/backend-next/internal/config"
+	"github.com/penguin-statistics/backend-next/internal/infra"
+	"github.com/penguin-statistics/backend-next/internal/migrations"
+	"github.com/penguin-statistics/backend-next/internal/pkg/logger"
+)
+
+const usage = `Usage: backend migrate [flags] <command>
+
+Manages the database schema, using the database configured for the service.
+
+Commands:
+  up             apply every migration not applied yet
+  down           roll back the last group of migrations applied together
+  status         list migrations and whether they are applied
+  create <name>  create empty up and down SQL migrations named <name>
+  seed           load sample zones, stages, items, time ranges and drop infos
+
+Flags:
+`
+
+// Run runs the migrate command with the given arguments, not including the command name, and exits on failure.
+func Run(args []string) {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dir := flags.String("dir", "internal/migrations", "directory to create migrations in, for create")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	command := flags.Arg(0)
+	if command == "create" {
+		// creating migrations only writes files, so no database is needed
+		if flags.NArg() != 2 {
+			flags.Usage()
+			os.Exit(2)
+		}
+		if err := create(*dir, flags.Arg(1)); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to create migration:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	conf, err := config.Parse()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logger.Configure(conf)
+
+	db, err := infra.Postgres(conf)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to connect to database")
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		err = migrations.Up(ctx, db)
+	case "down":
+		err = down(ctx, db)
+	case "status":
+		err = status(ctx, db)
+	case "seed":
+		err = seed(ctx, db)
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal().Err(err).Str("command", command).Msg("failed to migrate database")
+	}
+}
+
+func create(dir string, name string) error {
+	files, err := bunmigrate.NewMigrator(nil, bunmigrate.NewMigrations(bunmigrate.WithMigrationsDirectory(dir))).
+		CreateSQLMigrations(context.Background(), name)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		fmt.Println("created", f.Path)
+	}
+	return nil
+}
+
+func down(ctx context.Context, db *bun.DB) error {
+	migrator := migrations.NewMigrator(db)
+	if err := migrator.Init(ctx); err != nil {
+		return err
+	}
+	group, err := migrator.Rollback(ctx)
+	if err != nil {
+		return err
+	}
+	if group.IsZero() {
+		log.Info().Msg("no migrations to roll back")
+		return nil
+	}
+	log.Info().Str("group", group.String()).Msg("database schema rolled back")
+	return nil
+}
+
+func status(ctx context.Context, db *bun.DB) error {
+	migrator := migrations.NewMigrator(db)
+	if err := migrator.Init(ctx); err != nil {
+		return err
+	}
+	ms, err := migrator.MigrationsWithStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTATUS\tGROUP\tMIGRATED AT")
+	for _, m := range ms {
+		if m.IsApplied() {
+			fmt.Fprintf(w, "%s\tapplied\t%d\t%s\n", m.Name, m.GroupID, m.MigratedAt.Format(time.RFC3339))
+		} else {
+			fmt.Fprintf(w, "%s\tpending\t\t\n", m.Name)
+		}
+	}
+	return w.Flush()
+}
+
+func seed(ctx context.Context, db *bun.DB) error {
+	if err := migrations.Seed(ctx, db); err != nil {
+		return err
+	}
+	log.Info().Msg("sample data loaded")
+	return nil
+}
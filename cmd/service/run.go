@@ -13,11 +13,15 @@ import (
 
 	"github.com/penguin-statistics/backend-next/internal/config"
 	"github.com/penguin-statistics/backend-next/internal/pkg/async"
+	"github.com/penguin-statistics/backend-next/internal/service"
 )
 
-func run(app *fiber.App, conf *config.Config, lc fx.Lifecycle) {
+func run(app *fiber.App, conf *config.Config, warmup *service.Warmup, lc fx.Lifecycle) {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
+			// the health check reports this instance unavailable until the warm-up is over
+			warmup.Start()
+
 			ln, err := net.Listen("tcp", conf.Address)
 			if err != nil {
 				return err
@@ -8,6 +8,7 @@ import (
 	"github.com/penguin-statistics/backend-next/internal/config"
 	controllermeta "github.com/penguin-statistics/backend-next/internal/controller/meta"
 	controllerv2 "github.com/penguin-statistics/backend-next/internal/controller/v2"
+	controllerv3 "github.com/penguin-statistics/backend-next/internal/controller/v3"
 	"github.com/penguin-statistics/backend-next/internal/infra"
 	"github.com/penguin-statistics/backend-next/internal/model/cache"
 	"github.com/penguin-statistics/backend-next/internal/pkg/crypto"
@@ -20,6 +21,7 @@ import (
 	"github.com/penguin-statistics/backend-next/internal/service"
 	"github.com/penguin-statistics/backend-next/internal/util/reportverifs"
 	"github.com/penguin-statistics/backend-next/internal/workers/calcwkr"
+	"github.com/penguin-statistics/backend-next/internal/workers/exportwkr"
 	"github.com/penguin-statistics/backend-next/internal/workers/reportwkr"
 )
 
@@ -27,6 +29,7 @@ func ProvideOptions(includeSwagger bool) []fx.Option {
 	opts := []fx.Option{
 		// Misc
 		fx.Provide(config.Parse),
+		fx.Provide(config.NewReloader),
 		fx.Provide(flake.New),
 		fx.Provide(httpserver.Create),
 		fx.Provide(svr.CreateEndpointGroups),
@@ -37,7 +40,10 @@ func ProvideOptions(includeSwagger bool) []fx.Option {
 			infra.NATS,
 			infra.Redis,
 			infra.Postgres,
+			infra.PostgresReplica,
 			infra.GeoIPDatabase,
+			infra.LeaderElector,
+			infra.FlakeNodeLease,
 		),
 
 		// Verifiers
@@ -57,7 +63,9 @@ func ProvideOptions(includeSwagger bool) []fx.Option {
 			repo.NewStage,
 			repo.NewNotice,
 			repo.NewAccount,
+			repo.NewFormula,
 			repo.NewActivity,
+			repo.NewGachaBox,
 			repo.NewDropInfo,
 			repo.NewProperty,
 			repo.NewTimeRange,
@@ -67,6 +75,7 @@ func ProvideOptions(includeSwagger bool) []fx.Option {
 			repo.NewTrendElement,
 			repo.NewDropReportExtra,
 			repo.NewDropMatrixElement,
+			repo.NewDropMatrixSnapshot,
 			repo.NewDropPatternElement,
 			repo.NewPatternMatrixElement,
 		),
@@ -79,21 +88,28 @@ func ProvideOptions(includeSwagger bool) []fx.Option {
 			service.NewGeoIP,
 			service.NewTrend,
 			service.NewAdmin,
+			service.NewWarmup,
+			service.NewExport,
 			service.NewHealth,
 			service.NewNotice,
 			service.NewReport,
 			service.NewAccount,
 			service.NewFormula,
+			service.NewPlanner,
 			service.NewActivity,
+			service.NewGachaBox,
 			service.NewDropInfo,
 			service.NewShortURL,
+			service.NewFurniture,
 			service.NewTimeRange,
 			service.NewSiteStats,
 			service.NewDropMatrix,
 			service.NewDropReport,
+			service.NewCachePurge,
 			service.NewTrendElement,
 			service.NewPatternMatrix,
 			service.NewDropMatrixElement,
+			service.NewDropMatrixSnapshot,
 			service.NewDropPatternElement,
 			service.NewPatternMatrixElement,
 		),
@@ -101,8 +117,10 @@ func ProvideOptions(includeSwagger bool) []fx.Option {
 		// Global Singleton Inits
 		fx.Invoke(logger.Configure),
 		fx.Invoke(infra.SentryInit),
+		fx.Invoke(infra.Migrate),
 		fx.Invoke(cache.Initialize),
 		fx.Invoke(observability.Launch),
+		fx.Invoke(observability.SetupTracing),
 
 		// Controllers (v2)
 		fx.Invoke(
@@ -120,16 +138,34 @@ func ProvideOptions(includeSwagger bool) []fx.Option {
 			controllerv2.RegisterShortURL,
 		),
 
+		// Controllers (v3)
+		fx.Invoke(
+			controllerv3.RegisterExportController,
+			controllerv3.RegisterPlannerController,
+			controllerv3.RegisterGachaBoxController,
+			controllerv3.RegisterFurnitureController,
+			controllerv3.RegisterItemDropsController,
+			controllerv3.RegisterSiteStatsController,
+		),
+
 		// Controllers (meta)
 		fx.Invoke(
 			controllermeta.RegisterMeta,
 			controllermeta.RegisterIndex,
 			controllermeta.RegisterAdmin,
+			controllermeta.RegisterAdminJobs,
+			controllermeta.RegisterAdminCache,
+			controllermeta.RegisterAdminConfig,
+			controllermeta.RegisterAdminMatrix,
+			controllermeta.RegisterAdminFormula,
+			controllermeta.RegisterAdminGachaBox,
 		),
 
 		// Workers
+		fx.Provide(calcwkr.New),
 		fx.Invoke(calcwkr.Start),
 		fx.Invoke(reportwkr.Start),
+		fx.Invoke(exportwkr.Start),
 
 		// fx Extra Options
 		fx.StartTimeout(1 * time.Second),
@@ -1,13 +1,21 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/kelseyhightower/envconfig"
 )
 
+// Config is the configuration of the backend. Every setting is read from the environment variable named after the
+// field, such as PENGUIN_V3_WORKER_INTERVAL for WorkerInterval, and may also be set in the optional configuration
+// file pointed to by PENGUIN_V3_CONFIG_FILE, with environment variables taking precedence over the file.
+//
+// Settings tagged with reload:"true" are applied without a restart when the configuration file changes; see
+// Reloader. Settings tagged with secret:"true" are redacted whenever the configuration is shown.
 type Config struct {
 	// Address is the listen address would listen on.
 	Address string
@@ -20,14 +28,47 @@ type Config struct {
 	// TracingEnabled to indicate whether to enable OpenTelemetry tracing.
 	TracingEnabled bool `split_words:"true"`
 
+	// TracingOTLPEndpoint is the host and port of the OpenTelemetry collector spans are exported to over OTLP/HTTP,
+	// such as the OTLP receiver of the OpenTelemetry Collector or of Jaeger.
+	TracingOTLPEndpoint string `split_words:"true" default:"localhost:4318"`
+
+	// TracingOTLPInsecure is a flag to indicate whether to export spans over plain HTTP instead of HTTPS.
+	TracingOTLPInsecure bool `split_words:"true"`
+
+	// TracingOTLPHeaders are additional headers sent along with exported spans, usually for authentication, in the
+	// form of "key1:value1,key2:value2".
+	TracingOTLPHeaders map[string]string `split_words:"true" secret:"true"`
+
+	// TracingEnvironment is the deployment environment spans are tagged with, such as dev, staging or prod.
+	TracingEnvironment string `split_words:"true" default:"dev"`
+
+	// TracingSampleRatio is the ratio of traces sampled, from 0 to 1. Traces continued from an upstream span follow
+	// the sampling decision of their parent instead.
+	TracingSampleRatio float64 `split_words:"true" default:"1"`
+
 	// infrastructure components connection instructions
 
 	// PostgresDSN is the data source name for the PostgreSQL database. See
 	// https://bun.uptrace.dev/postgres/#pgdriver for more details on how to construct a PostgreSQL DSN.
-	PostgresDSN string `required:"true" split_words:"true"`
+	PostgresDSN string `required:"true" split_words:"true" secret:"true"`
+
+	// PostgresReplicaDSN is the data source name for an optional streaming replica of the PostgreSQL database. When
+	// set, heavy read-only aggregation queries, such as the drop matrix, trend and personal or advanced queries, are
+	// sent to the replica as long as its replication lag is at most PostgresReplicaMaxLag, and to the primary otherwise.
+	PostgresReplicaDSN string `split_words:"true" secret:"true"`
+
+	// PostgresReplicaMaxLag is the maximum replication lag of the replica for queries to be routed to it.
+	PostgresReplicaMaxLag time.Duration `required:"true" split_words:"true" default:"30s"`
+
+	// PostgresReplicaLagCheckInterval is the interval at which the replication lag of the replica is checked.
+	PostgresReplicaLagCheckInterval time.Duration `required:"true" split_words:"true" default:"5s"`
 
 	BunDebugVerbose bool `split_words:"true"`
 
+	// MigrateOnStartup to indicate whether to apply pending database migrations on startup, before serving. Instances
+	// starting at the same time migrate one after another. Migrations may also be applied with `backend migrate up`.
+	MigrateOnStartup bool `split_words:"true"`
+
 	// NatsURL is the URL of the NATS server. See https://pkg.go.dev/github.com/nats-io/nats.go#Connect
 	// for more information on how to construct a NATS URL.
 	NatsURL string `required:"true" split_words:"true" default:"nats://127.0.0.1:4222"`
@@ -35,18 +76,26 @@ type Config struct {
 	// RedisURL is the URL of the Redis server, and by default uses redis db 1, to avoid potential collision
 	// with the previous running backend instance. See https://pkg.go.dev/github.com/go-redis/redis/v8#ParseURL
 	// for more information on how to construct a Redis URL.
-	RedisURL string `required:"true" split_words:"true" default:"redis://127.0.0.1:6379/1"`
+	RedisURL string `required:"true" split_words:"true" default:"redis://127.0.0.1:6379/1" secret:"true"`
+
+	// CacheRemoteEnabled is a flag to indicate whether to share caches among instances through Redis, in addition
+	// to keeping them in process memory.
+	CacheRemoteEnabled bool `split_words:"true"`
+
+	// CacheRemoteLockTTL describes how long an instance computing a missing cache value holds its lock at most,
+	// and therefore how long other instances wait for it before computing the value themselves.
+	CacheRemoteLockTTL time.Duration `required:"true" split_words:"true" default:"1m"`
 
 	// SentryDSN is the DSN of the Sentry server. See https://pkg.go.dev/github.com/getsentry/sentry-go#ClientOptions
-	SentryDSN string `split_words:"true"`
+	SentryDSN string `split_words:"true" secret:"true"`
 
 	// RecognitionEncryptionPrivateKey is the private key used to decrypt the recognition data.
 	// Normal contributors should not need to change this: when left empty, recognition report is simply disabled.
-	RecognitionEncryptionPrivateKey []byte `split_words:"true"`
+	RecognitionEncryptionPrivateKey []byte `split_words:"true" secret:"true"`
 
 	// RecognitionEncryptionIV is a pre-defined IV used to encrypt the recognition data.
 	// Normal contributors should not need to change this: when left empty, recognition report is simply disabled.
-	RecognitionEncryptionIV []int `split_words:"true"`
+	RecognitionEncryptionIV []int `split_words:"true" secret:"true"`
 
 	// HTTPServerShutdownTimeout is the timeout for the HTTP server to shut down gracefully.
 	HTTPServerShutdownTimeout time.Duration `required:"true" split_words:"true" default:"60s"`
@@ -54,33 +103,111 @@ type Config struct {
 	// GeoIPDBPath is the path to the GeoIP2 database.
 	GeoIPDBPath string `required:"true" split_words:"true" default:"vendors/maxmind/assets/geolite2/GeoLite2-Country.mmdb"`
 
-	// WorkerInterval describes the interval in-between different batches
-	WorkerInterval time.Duration `required:"true" split_words:"true" default:"10m"`
+	// WorkerInterval describes the default interval in-between different runs of a job
+	WorkerInterval time.Duration `required:"true" split_words:"true" default:"10m" reload:"true"`
+
+	// WorkerJobIntervals overrides WorkerInterval for specific job types, in the form of
+	// "dropMatrix:10m,trend:1h". Available job types are: dropMatrix, patternMatrix, trend, siteStats, furniture.
+	WorkerJobIntervals map[string]time.Duration `split_words:"true" reload:"true"`
 
-	// WorkerSeparation describes the separation time in-between different microtasks
+	// WorkerSeparation describes the separation time in-between the first runs of different jobs
 	WorkerSeparation time.Duration `required:"true" split_words:"true" default:"3s"`
 
-	// WorkerTimeout describes the timeout for a single batch to run
-	WorkerTimeout time.Duration `required:"true" split_words:"true" default:"10m"`
+	// WorkerTimeout describes the timeout for a single attempt of a job to run
+	WorkerTimeout time.Duration `required:"true" split_words:"true" default:"10m" reload:"true"`
 
-	// WorkerEnabled is a flag to indicate whether to enable the worker.
+	// WorkerJobMaxRetries is the number of times a failed attempt of a job is retried before the run is considered failed
+	WorkerJobMaxRetries int `split_words:"true" default:"2" reload:"true"`
+
+	// WorkerJobRetryBackoff describes the wait before the first retry of a job, which doubles for every following retry
+	WorkerJobRetryBackoff time.Duration `split_words:"true" default:"30s" reload:"true"`
+
+	// WorkerConcurrency is the number of jobs allowed to run at the same time
+	WorkerConcurrency int `split_words:"true" default:"1"`
+
+	// WorkerEnabled is a flag to indicate whether to enable the worker. When enabled on multiple instances, they elect
+	// a leader among themselves and only the leader runs jobs, including manually triggered ones.
 	WorkerEnabled bool `split_words:"true"`
 
+	// WorkerMaxAge describes how long ago the latest successful run of any calculation worker job of a server may be
+	// at most, before the readiness check reports the worker as degraded for that server. It is not checked while
+	// the worker is not enabled on any instance.
+	WorkerMaxAge time.Duration `required:"true" split_words:"true" default:"1h" reload:"true"`
+
+	// WorkerLeaseTTL describes how long the leadership of the worker lasts without being renewed, which bounds the
+	// time it takes for another instance to take over once the leader is gone.
+	WorkerLeaseTTL time.Duration `required:"true" split_words:"true" default:"15s"`
+
+	// SnowflakeNodeID is the node ID of the snowflake IDs generated by this instance, such as request IDs and report
+	// task IDs, from 0 to 1023. It must be unique among instances, and startup fails if another instance uses it
+	// already. When left to -1, a free node ID is leased automatically.
+	SnowflakeNodeID int64 `split_words:"true" default:"-1"`
+
+	// SnowflakeNodeLeaseTTL describes how long the lease of the snowflake node ID lasts without being renewed. The
+	// instance exits once the lease is about to expire without having been renewed, e.g. while redis is unreachable.
+	SnowflakeNodeLeaseTTL time.Duration `required:"true" split_words:"true" default:"30s"`
+
 	// AdminKey is the key used to authenticate the admin API.
-	AdminKey string `split_words:"true"`
+	AdminKey string `split_words:"true" secret:"true"`
 
 	// MatrixWorkerSourceCategories is a list of categories that the matrix worker will run for.
-	// Available categories are: all, automated, manual.
-	MatrixWorkerSourceCategories []string `required:"true" split_words:"true" default:"all"`
+	// Available categories are: all, automated, manual. The saved elements of categories removed from the list are
+	// deleted upon the next run of the matrix worker.
+	MatrixWorkerSourceCategories []string `required:"true" split_words:"true" default:"all" reload:"true"`
+
+	// ExportEnabled is a flag to indicate whether to enable the open data export worker.
+	ExportEnabled bool `split_words:"true"`
+
+	// ExportDir is the local directory the open data exports are written to and served from.
+	ExportDir string `required:"true" split_words:"true" default:"exports"`
+
+	// ExportInterval describes the interval in-between different export runs
+	ExportInterval time.Duration `required:"true" split_words:"true" default:"6h"`
+
+	// ExportReportsBackfillDays is the number of past days an export run looks back for days whose drop reports have
+	// not been exported yet, e.g. as the export worker was down over midnight, and exports them as well.
+	ExportReportsBackfillDays int `split_words:"true" default:"7"`
+
+	// DropMatrixSnapshotRetention describes how long drop matrix snapshots are kept. The latest snapshot of every
+	// server and source category is kept regardless. Set to 0 to keep snapshots forever.
+	DropMatrixSnapshotRetention time.Duration `split_words:"true" default:"2160h" reload:"true"`
+
+	// ReportMaxPending is the number of reports pending in the consumer at most, before the readiness check reports
+	// the report consumer as degraded.
+	ReportMaxPending uint64 `split_words:"true" default:"1000" reload:"true"`
+
+	// AdvancedQueryLimit is the number of advanced queries a client may send within AdvancedQueryLimitWindow.
+	AdvancedQueryLimit int `split_words:"true" default:"30" reload:"true"`
+
+	// AdvancedQueryLimitWindow describes the window AdvancedQueryLimit applies to.
+	AdvancedQueryLimitWindow time.Duration `split_words:"true" default:"5m" reload:"true"`
+
+	// WarmupTargets is a list of cached values precomputed on startup, before the instance reports itself ready.
+	// Available targets are: items, stages, zones, shimItems, shimStages, shimZones, shimDropMatrix,
+	// shimPatternMatrix, shimTrend. Set it to an empty string to skip the warm-up.
+	WarmupTargets []string `split_words:"true" default:"items,stages,zones,shimItems,shimStages,shimZones,shimDropMatrix,shimPatternMatrix,shimTrend"`
+
+	// WarmupConcurrency is the number of warm-up tasks allowed to run at the same time
+	WarmupConcurrency int `split_words:"true" default:"4"`
+
+	// WarmupTimeout describes how long the warm-up may take at most, after which the instance reports itself ready
+	// anyway, in a degraded state, while unfinished warm-up tasks keep running
+	WarmupTimeout time.Duration `required:"true" split_words:"true" default:"2m"`
+
+	// ConfigFileCheckInterval describes the interval in-between checks of the configuration file for changes. The
+	// configuration is also reloaded upon SIGHUP.
+	ConfigFileCheckInterval time.Duration `required:"true" split_words:"true" default:"30s"`
 }
 
 func Parse() (*Config, error) {
-	var config Config
-	err := envconfig.Process("penguin_v3", &config)
+	config, _, err := load(os.Getenv(FileEnv))
 	if err != nil {
-		_ = envconfig.Usage("penguin_v3", &config)
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			_ = envconfig.Usage(envPrefix, &Config{})
+		}
 		return nil, fmt.Errorf("failed to parse configuration: %w. More info on how to configure this backend is located at https://pkg.go.dev/github.com/penguin-statistics/backend-next/internal/config#Config", err)
 	}
 
-	return &config, nil
+	return config, nil
 }
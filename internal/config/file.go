@@ -0,0 +1,297 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/kelseyhightower/envconfig"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	envPrefix = "penguin_v3"
+
+	// FileEnv is the environment variable pointing to the optional configuration file, which is either in YAML
+	// (.yaml, .yml) or TOML (.toml). Its keys are the names of the environment variables in lowercase and without
+	// the PENGUIN_V3_ prefix, such as worker_interval. Lists may be given either as lists or as comma separated
+	// strings, and maps either as maps or as strings in the form of "key1:value1,key2:value2".
+	FileEnv = "PENGUIN_V3_CONFIG_FILE"
+)
+
+// field describes how a field of Config is configured
+type field struct {
+	// Name is the name of the field in Config
+	Name string
+	// Key is the name of the environment variable setting the field
+	Key string
+	Tag reflect.StructTag
+}
+
+var (
+	fieldsOnce sync.Once
+	fieldsList []field
+	fieldsMap  map[string]field
+)
+
+// fields returns every field of Config, along with the environment variable setting it, as envconfig names it.
+func fields() []field {
+	fieldsOnce.Do(func() {
+		var buf bytes.Buffer
+		tmpl := template.Must(template.New("fields").Parse("{{range .}}{{.Name}} {{.Key}}\n{{end}}"))
+		if err := envconfig.Usaget(envPrefix, &Config{}, &buf, tmpl); err != nil {
+			panic(err)
+		}
+
+		t := reflect.TypeOf(Config{})
+		fieldsMap = make(map[string]field)
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			parts := strings.Fields(line)
+			f, ok := t.FieldByName(parts[0])
+			if !ok {
+				continue
+			}
+			fd := field{Name: parts[0], Key: parts[1], Tag: f.Tag}
+			fieldsList = append(fieldsList, fd)
+			fieldsMap[fd.Name] = fd
+		}
+	})
+	return fieldsList
+}
+
+// keyOf returns the environment variable setting the field of Config
+func keyOf(name string) string {
+	fields()
+	if f, ok := fieldsMap[name]; ok {
+		return f.Key
+	}
+	return name
+}
+
+// load parses the configuration from the environment, layered over the configuration file if path is not empty,
+// and validates it. It also returns the values read from the file, keyed by environment variable.
+func load(path string) (*Config, map[string]string, error) {
+	fileValues, err := readFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	known := make(map[string]struct{}, len(fields()))
+	for _, f := range fields() {
+		known[f.Key] = struct{}{}
+	}
+	for key := range fileValues {
+		if _, ok := known[key]; !ok {
+			return nil, nil, fmt.Errorf("unknown key %q in configuration file %s", strings.ToLower(strings.TrimPrefix(key, strings.ToUpper(envPrefix)+"_")), path)
+		}
+	}
+
+	var config Config
+	if err := process(&config, fileValues); err != nil {
+		return nil, nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return &config, fileValues, nil
+}
+
+// process sets every field of config from its environment variable, or else from the configuration file, or else
+// from its default. Values are decoded in the same way as envconfig decodes environment variables, without
+// exposing the values of the configuration file in the environment.
+func process(config *Config, fileValues map[string]string) error {
+	value := reflect.ValueOf(config).Elem()
+	for _, f := range fields() {
+		v, ok := os.LookupEnv(f.Key)
+		if !ok {
+			v, ok = fileValues[f.Key]
+		}
+		if !ok && f.Tag.Get("default") != "" {
+			v, ok = f.Tag.Get("default"), true
+		}
+		if !ok {
+			if isTrue(f.Tag.Get("required")) {
+				return fmt.Errorf("required key %s missing value", f.Key)
+			}
+			continue
+		}
+		if err := decodeValue(value.FieldByName(f.Name), v); err != nil {
+			return fmt.Errorf("invalid value %q of %s: %w", v, f.Key, err)
+		}
+	}
+	return nil
+}
+
+// decodeValue decodes a value in the format of an environment variable into v
+func decodeValue(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(value, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(value))
+			return nil
+		}
+		if strings.TrimSpace(value) == "" {
+			return nil
+		}
+		elements := strings.Split(value, ",")
+		slice := reflect.MakeSlice(v.Type(), len(elements), len(elements))
+		for i, element := range elements {
+			if err := decodeValue(slice.Index(i), element); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		if strings.TrimSpace(value) != "" {
+			for _, pair := range strings.Split(value, ",") {
+				kv := strings.Split(pair, ":")
+				if len(kv) != 2 {
+					return fmt.Errorf("invalid map item: %q", pair)
+				}
+				key := reflect.New(v.Type().Key()).Elem()
+				if err := decodeValue(key, kv[0]); err != nil {
+					return err
+				}
+				element := reflect.New(v.Type().Elem()).Elem()
+				if err := decodeValue(element, kv[1]); err != nil {
+					return err
+				}
+				m.SetMapIndex(key, element)
+			}
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// readFile reads the configuration file into values in the format of environment variables, keyed by environment
+// variable. It returns no values if path is empty.
+func readFile(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file: %w", err)
+	}
+
+	raw := make(map[string]any)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &raw)
+	case ".toml":
+		err = toml.Unmarshal(content, &raw)
+	default:
+		return nil, fmt.Errorf("unsupported configuration file format %q: expected .yaml, .yml or .toml", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse configuration file %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		v, err := fileValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %q in configuration file %s: %w", key, path, err)
+		}
+		values[strings.ToUpper(envPrefix+"_"+key)] = v
+	}
+	return values, nil
+}
+
+// fileValue formats a value of the configuration file as envconfig expects it in an environment variable
+func fileValue(value any) (string, error) {
+	switch v := value.(type) {
+	case []any:
+		elements := make([]string, 0, len(v))
+		for _, element := range v {
+			s, err := scalarValue(element)
+			if err != nil {
+				return "", err
+			}
+			elements = append(elements, s)
+		}
+		return strings.Join(elements, ","), nil
+	case map[string]any:
+		pairs := make([]string, 0, len(v))
+		for key, element := range v {
+			s, err := scalarValue(element)
+			if err != nil {
+				return "", err
+			}
+			pairs = append(pairs, key+":"+s)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ","), nil
+	default:
+		return scalarValue(value)
+	}
+}
+
+func scalarValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unsupported value of type %T", value)
+	}
+}
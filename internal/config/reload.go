@@ -0,0 +1,269 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.uber.org/fx"
+)
+
+const (
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+
+	redacted = "<redacted>"
+)
+
+// ReloadResult describes the outcome of a reload, listing settings by environment variable.
+type ReloadResult struct {
+	// Applied lists the settings changed and applied without a restart
+	Applied []string `json:"applied"`
+	// RestartRequired lists the settings changed but only applied after a restart, as they are not reloadable
+	RestartRequired []string `json:"restartRequired"`
+}
+
+// Setting describes the effective value of a setting.
+type Setting struct {
+	Key    string `json:"key"`
+	Field  string `json:"field"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	Secret bool   `json:"secret,omitempty"`
+	// Reloadable tells whether changes to the setting are applied without a restart
+	Reloadable bool `json:"reloadable"`
+	// RestartRequired tells whether the setting has been changed since startup, but requires a restart to apply
+	RestartRequired bool `json:"restartRequired,omitempty"`
+}
+
+// Reloader keeps the configuration up to date with the configuration file, reloading it whenever the file changes
+// or upon SIGHUP. Only settings tagged with reload:"true" are applied; components depending on them are expected
+// to read them from Current rather than from the Config parsed on startup, or to subscribe with OnReload.
+type Reloader struct {
+	path string
+
+	mu              sync.RWMutex
+	current         *Config
+	fileValues      map[string]string
+	fileModTime     time.Time
+	restartRequired map[string]struct{}
+	subscribers     []func(prev *Config, next *Config)
+}
+
+func NewReloader(conf *Config, lc fx.Lifecycle) *Reloader {
+	r := &Reloader{
+		path:            os.Getenv(FileEnv),
+		current:         conf,
+		restartRequired: make(map[string]struct{}),
+	}
+	if r.path == "" {
+		return r
+	}
+
+	// already read successfully by Parse on startup
+	r.fileValues, _ = readFile(r.path)
+	if info, err := os.Stat(r.path); err == nil {
+		r.fileModTime = info.ModTime()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go r.watch(ctx, conf.ConfigFileCheckInterval)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+	return r
+}
+
+// Current returns the configuration currently in effect. It must not be modified.
+func (r *Reloader) Current() *Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current
+}
+
+// OnReload makes fn be called after every reload changing reloadable settings, with the configuration in effect
+// before and after the reload.
+func (r *Reloader) OnReload(fn func(prev *Config, next *Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, fn)
+}
+
+// File returns the path of the configuration file, or an empty string if there is none.
+func (r *Reloader) File() string {
+	return r.path
+}
+
+func (r *Reloader) watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Info().Msg("received SIGHUP, reloading configuration")
+		case <-ticker.C:
+			info, err := os.Stat(r.path)
+			if err != nil {
+				log.Warn().Err(err).Str("file", r.path).Msg("failed to check configuration file for changes")
+				continue
+			}
+			r.mu.RLock()
+			modified := !info.ModTime().Equal(r.fileModTime)
+			r.mu.RUnlock()
+			if !modified {
+				continue
+			}
+		}
+
+		if _, err := r.Reload(); err != nil {
+			log.Error().Err(err).Str("file", r.path).Msg("failed to reload configuration, keeping the current one")
+		}
+	}
+}
+
+// Reload parses and validates the configuration again, then applies the reloadable settings changed. The current
+// configuration is kept as a whole if the new one is invalid.
+func (r *Reloader) Reload() (*ReloadResult, error) {
+	var modTime time.Time
+	if r.path != "" {
+		if info, err := os.Stat(r.path); err == nil {
+			modTime = info.ModTime()
+		}
+	}
+
+	loaded, fileValues, err := load(r.path)
+	if err != nil {
+		// not retried until the file changes again
+		r.mu.Lock()
+		r.fileModTime = modTime
+		r.mu.Unlock()
+		return nil, err
+	}
+
+	r.mu.Lock()
+	prev := r.current
+	next := *prev
+	result := &ReloadResult{Applied: []string{}, RestartRequired: []string{}}
+	nextValue := reflect.ValueOf(&next).Elem()
+	loadedValue := reflect.ValueOf(loaded).Elem()
+	r.restartRequired = make(map[string]struct{})
+	for _, f := range fields() {
+		loadedField := loadedValue.FieldByName(f.Name)
+		if reflect.DeepEqual(nextValue.FieldByName(f.Name).Interface(), loadedField.Interface()) {
+			continue
+		}
+		if isTrue(f.Tag.Get("reload")) {
+			nextValue.FieldByName(f.Name).Set(loadedField)
+			result.Applied = append(result.Applied, f.Key)
+		} else {
+			r.restartRequired[f.Key] = struct{}{}
+			result.RestartRequired = append(result.RestartRequired, f.Key)
+		}
+	}
+	r.current = &next
+	r.fileValues = fileValues
+	r.fileModTime = modTime
+	subscribers := make([]func(prev *Config, next *Config), len(r.subscribers))
+	copy(subscribers, r.subscribers)
+	r.mu.Unlock()
+
+	logger := log.With().Strs("applied", result.Applied).Strs("restartRequired", result.RestartRequired).Logger()
+	if len(result.RestartRequired) > 0 {
+		logger.Warn().Msg("configuration reloaded; some changed settings are not reloadable and require a restart to apply")
+	} else {
+		logger.Info().Msg("configuration reloaded")
+	}
+
+	if len(result.Applied) > 0 {
+		for _, fn := range subscribers {
+			fn(prev, &next)
+		}
+	}
+	return result, nil
+}
+
+// Settings returns every setting currently in effect, sorted by key, with secrets redacted.
+func (r *Reloader) Settings() []*Setting {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	value := reflect.ValueOf(r.current).Elem()
+	settings := make([]*Setting, 0, len(fields()))
+	for _, f := range fields() {
+		setting := &Setting{
+			Key:        f.Key,
+			Field:      f.Name,
+			Value:      formatValue(value.FieldByName(f.Name)),
+			Source:     SourceDefault,
+			Secret:     isTrue(f.Tag.Get("secret")),
+			Reloadable: isTrue(f.Tag.Get("reload")),
+		}
+		if _, ok := os.LookupEnv(f.Key); ok {
+			setting.Source = SourceEnv
+		} else if _, ok := r.fileValues[f.Key]; ok {
+			setting.Source = SourceFile
+		}
+		if _, ok := r.restartRequired[f.Key]; ok {
+			setting.RestartRequired = true
+		}
+		if setting.Secret && setting.Value != "" {
+			setting.Value = redacted
+		}
+		settings = append(settings, setting)
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+	return settings
+}
+
+// formatValue formats the value of a setting in the format of its environment variable
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+		elements := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elements = append(elements, formatValue(v.Index(i)))
+		}
+		return strings.Join(elements, ",")
+	case reflect.Map:
+		pairs := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			pairs = append(pairs, formatValue(iter.Key())+":"+formatValue(iter.Value()))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+func isTrue(s string) bool {
+	return s == "true"
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/samber/lo"
+
+	"github.com/penguin-statistics/backend-next/internal/constant"
+)
+
+var sourceCategories = []string{constant.SourceCategoryAll, constant.SourceCategoryAutomated, constant.SourceCategoryManual}
+
+// ValidationError lists every problem found while validating the configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks that the settings make sense beyond being well-formed, and reports every problem found at once.
+func (c *Config) Validate() error {
+	var problems []string
+	problem := func(name string, format string, args ...any) {
+		problems = append(problems, keyOf(name)+": "+fmt.Sprintf(format, args...))
+	}
+	positive := func(name string, d time.Duration) {
+		if d <= 0 {
+			problem(name, "must be a positive duration, got %s", d)
+		}
+	}
+	atLeast := func(name string, v int, min int) {
+		if v < min {
+			problem(name, "must be at least %d, got %d", min, v)
+		}
+	}
+
+	positive("CacheRemoteLockTTL", c.CacheRemoteLockTTL)
+	positive("HTTPServerShutdownTimeout", c.HTTPServerShutdownTimeout)
+	positive("WorkerInterval", c.WorkerInterval)
+	positive("WorkerTimeout", c.WorkerTimeout)
+	positive("WorkerMaxAge", c.WorkerMaxAge)
+	positive("WorkerLeaseTTL", c.WorkerLeaseTTL)
+	positive("ExportInterval", c.ExportInterval)
+	positive("WarmupTimeout", c.WarmupTimeout)
+	positive("AdvancedQueryLimitWindow", c.AdvancedQueryLimitWindow)
+	positive("ConfigFileCheckInterval", c.ConfigFileCheckInterval)
+	positive("SnowflakeNodeLeaseTTL", c.SnowflakeNodeLeaseTTL)
+	positive("PostgresReplicaMaxLag", c.PostgresReplicaMaxLag)
+	positive("PostgresReplicaLagCheckInterval", c.PostgresReplicaLagCheckInterval)
+	if c.WorkerSeparation < 0 {
+		problem("WorkerSeparation", "must not be negative, got %s", c.WorkerSeparation)
+	}
+	if c.WorkerJobRetryBackoff < 0 {
+		problem("WorkerJobRetryBackoff", "must not be negative, got %s", c.WorkerJobRetryBackoff)
+	}
+	if c.DropMatrixSnapshotRetention < 0 {
+		problem("DropMatrixSnapshotRetention", "must not be negative, got %s", c.DropMatrixSnapshotRetention)
+	}
+
+	atLeast("WorkerJobMaxRetries", c.WorkerJobMaxRetries, 0)
+	atLeast("WorkerConcurrency", c.WorkerConcurrency, 1)
+	atLeast("ExportReportsBackfillDays", c.ExportReportsBackfillDays, 1)
+	atLeast("WarmupConcurrency", c.WarmupConcurrency, 1)
+	atLeast("AdvancedQueryLimit", c.AdvancedQueryLimit, 1)
+
+	for jobType, interval := range c.WorkerJobIntervals {
+		if !lo.Contains(constant.WorkerJobTypes, jobType) {
+			problem("WorkerJobIntervals", "unknown job type %q, expected one of %s", jobType, strings.Join(constant.WorkerJobTypes, ", "))
+		} else if interval <= 0 {
+			problem("WorkerJobIntervals", "interval of %s must be a positive duration, got %s", jobType, interval)
+		}
+	}
+
+	if len(c.MatrixWorkerSourceCategories) == 0 {
+		problem("MatrixWorkerSourceCategories", "must not be empty")
+	}
+	for _, category := range c.MatrixWorkerSourceCategories {
+		if !lo.Contains(sourceCategories, category) {
+			problem("MatrixWorkerSourceCategories", "unknown source category %q, expected one of %s", category, strings.Join(sourceCategories, ", "))
+		}
+	}
+
+	if maxNode := int64(-1 ^ (-1 << snowflake.NodeBits)); c.SnowflakeNodeID < -1 || c.SnowflakeNodeID > maxNode {
+		problem("SnowflakeNodeID", "must be between 0 and %d, or -1 to lease one automatically, got %d", maxNode, c.SnowflakeNodeID)
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		problem("TracingSampleRatio", "must be between 0 and 1, got %g", c.TracingSampleRatio)
+	}
+	if c.TracingEnabled && c.TracingOTLPEndpoint == "" {
+		problem("TracingOTLPEndpoint", "must be set when tracing is enabled")
+	}
+	if c.ExportEnabled && c.ExportDir == "" {
+		problem("ExportDir", "must be set when the export is enabled")
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
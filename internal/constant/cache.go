@@ -1,3 +1,22 @@
 package constant
 
+import "time"
+
 const CacheSep = "|"
+
+const (
+	// CachePurgeSubject is the NATS subject cache purges are broadcast on to every instance
+	CachePurgeSubject = "CACHE.PURGE"
+
+	// CachePurgeAckTimeout is how long acknowledgements of a cache purge are collected for
+	CachePurgeAckTimeout = 2 * time.Second
+)
+
+const (
+	// ShimResultsFreshTTL is how long cached shim results are fresh for; afterwards they are still served, until
+	// ShimResultsExpireTTL, while being recomputed in the background
+	ShimResultsFreshTTL = time.Hour
+
+	// ShimResultsExpireTTL is how long cached shim results are kept for at most
+	ShimResultsExpireTTL = 24 * time.Hour
+)
@@ -0,0 +1,8 @@
+package constant
+
+const (
+	// FurnitureConfidenceLevel is the confidence level of the interval reported along with furniture drop rates
+	FurnitureConfidenceLevel = 0.95
+	// FurnitureConfidenceZ is the standard normal quantile corresponding to FurnitureConfidenceLevel
+	FurnitureConfidenceZ = 1.959964
+)
@@ -0,0 +1,9 @@
+package constant
+
+const (
+	// PlannerDefaultMinTimes is the default minimum number of samples a stage should have to be considered by the planner
+	PlannerDefaultMinTimes = 100
+
+	// PlannerDefaultByproductRate is the default probability of a crafting yielding an extra outcome
+	PlannerDefaultByproductRate = 0.1
+)
@@ -7,3 +7,24 @@ const (
 	// This is typically used by probes to avoid useless data being sent to Sentry.
 	SlimHeaderKey = "X-Slim"
 )
+
+const (
+	// WorkerLeaderKey is the Redis key of the lease held by the instance running the calculation worker.
+	WorkerLeaderKey = "penguin:calcwkr:leader"
+
+	// WorkerLastSuccessKey is the Redis hash of the unix time of the latest successful run of each calculation
+	// worker job, keyed by job name.
+	WorkerLastSuccessKey = "penguin:calcwkr:lastsuccess"
+
+	// FlakeNodeKeyPrefix prefixes the Redis keys of the leases of snowflake node IDs, each followed by the node ID
+	// and held by the instance generating IDs with it.
+	FlakeNodeKeyPrefix = "penguin:flake:node:"
+)
+
+const (
+	// ReportStreamName is the JetStream stream reports are published to.
+	ReportStreamName = "penguin-reports"
+
+	// ReportConsumerName is the durable JetStream consumer the report workers consume reports with.
+	ReportConsumerName = "penguin-reports"
+)
@@ -0,0 +1,20 @@
+package constant
+
+const (
+	// ShrinkagePriorSourceZoneCategory means the prior is derived from stages in zones of the same category
+	ShrinkagePriorSourceZoneCategory = "zoneCategory"
+	// ShrinkagePriorSourceSanityTier means the prior is derived from stages of the same sanity cost
+	ShrinkagePriorSourceSanityTier = "sanityTier"
+
+	// ShrinkageMinPriorTimes is the minimum total times of comparable stages for a prior to be derived from them
+	ShrinkageMinPriorTimes = 1000
+
+	// ShrinkageDefaultPriorStrength is the prior strength, in pseudo-runs, used when there are too few comparable
+	// stages to estimate how much rates vary between them
+	ShrinkageDefaultPriorStrength = 30.0
+
+	// ShrinkageMinPriorStrength and ShrinkageMaxPriorStrength bound the estimated prior strength, in pseudo-runs,
+	// so that neither the prior nor the observation is ever completely ignored
+	ShrinkageMinPriorStrength = 1.0
+	ShrinkageMaxPriorStrength = 300.0
+)
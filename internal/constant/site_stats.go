@@ -0,0 +1,4 @@
+package constant
+
+// SiteStatsDays is the number of game days, including the current one, that the expanded site stats cover
+const SiteStatsDays = 90
@@ -0,0 +1,18 @@
+package constant
+
+// job types of the calculation worker
+const (
+	WorkerJobTypeDropMatrix    = "dropMatrix"
+	WorkerJobTypePatternMatrix = "patternMatrix"
+	WorkerJobTypeTrend         = "trend"
+	WorkerJobTypeSiteStats     = "siteStats"
+	WorkerJobTypeFurniture     = "furniture"
+)
+
+var WorkerJobTypes = []string{
+	WorkerJobTypeDropMatrix,
+	WorkerJobTypePatternMatrix,
+	WorkerJobTypeTrend,
+	WorkerJobTypeSiteStats,
+	WorkerJobTypeFurniture,
+}
@@ -9,14 +9,11 @@ import (
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/gofiber/fiber/v2"
-	"github.com/pkg/errors"
 	"github.com/samber/lo"
 	"github.com/zeebo/xxh3"
 	"go.uber.org/fx"
 
-	"github.com/penguin-statistics/backend-next/internal/constant"
 	"github.com/penguin-statistics/backend-next/internal/model"
-	"github.com/penguin-statistics/backend-next/internal/model/cache"
 	"github.com/penguin-statistics/backend-next/internal/model/gamedata"
 	"github.com/penguin-statistics/backend-next/internal/model/types"
 	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
@@ -29,14 +26,11 @@ import (
 type AdminController struct {
 	fx.In
 
-	PatternRepo          *repo.DropPattern
-	PatternElementRepo   *repo.DropPatternElement
-	AdminService         *service.Admin
-	ItemService          *service.Item
-	DropMatrixService    *service.DropMatrix
-	PatternMatrixService *service.PatternMatrix
-	TrendService         *service.Trend
-	SiteStatsService     *service.SiteStats
+	PatternRepo        *repo.DropPattern
+	PatternElementRepo *repo.DropPatternElement
+	AdminService       *service.Admin
+	ItemService        *service.Item
+	CachePurgeService  *service.CachePurge
 }
 
 func RegisterAdmin(admin *svr.Admin, c AdminController) {
@@ -47,11 +41,6 @@ func RegisterAdmin(admin *svr.Admin, c AdminController) {
 	admin.Get("/cli/gamedata/seed", c.GetCliGameDataSeed)
 	admin.Get("/_temp/pattern/merging", c.FindPatterns)
 	admin.Get("/_temp/pattern/disambiguation", c.DisambiguatePatterns)
-
-	admin.Get("/refresh/matrix/:server", c.RefreshAllDropMatrixElements)
-	admin.Get("/refresh/pattern/:server", c.RefreshAllPatternMatrixElements)
-	admin.Get("/refresh/trend/:server", c.RefreshAllTrendElements)
-	admin.Get("/refresh/sitestats/:server", c.RefreshAllSiteStats)
 }
 
 type CliGameDataSeedResponse struct {
@@ -232,49 +221,29 @@ func (c *AdminController) SaveRenderedObjects(ctx *fiber.Ctx) error {
 	return ctx.JSON(request)
 }
 
+// PurgeCache purges the given caches on every instance, and responds with the acknowledgement of each instance.
 func (c *AdminController) PurgeCache(ctx *fiber.Ctx) error {
 	var request types.PurgeCacheRequest
 	if err := rekuest.ValidBody(ctx, &request); err != nil {
 		return err
 	}
-	errs := lo.Filter(
-		lo.Map(request.Pairs, func(pair types.PurgeCachePair, _ int) error {
-			err := cache.Delete(pair.Name, pair.Key)
-			if err != nil {
-				return errors.Wrapf(err, "cache [%s:%s]", pair.Name, pair.Key)
-			}
-			return nil
-		}),
-		func(v error, i int) bool {
-			return v != nil
-		},
-	)
-	if len(errs) > 0 {
-		err := pgerr.New(http.StatusInternalServerError, "PURGE_CACHE_FAILED", "error occurred while purging cache")
-		err.Extras = &pgerr.Extras{
-			"caches": errs,
-		}
-	}
-	return nil
-}
-
-func (c *AdminController) RefreshAllDropMatrixElements(ctx *fiber.Ctx) error {
-	server := ctx.Params("server")
-	return c.DropMatrixService.RefreshAllDropMatrixElements(ctx.Context(), server, []string{constant.SourceCategoryAll})
-}
 
-func (c *AdminController) RefreshAllPatternMatrixElements(ctx *fiber.Ctx) error {
-	server := ctx.Params("server")
-	return c.PatternMatrixService.RefreshAllPatternMatrixElements(ctx.Context(), server, []string{constant.SourceCategoryAll})
-}
+	acks, err := c.CachePurgeService.Purge(ctx.Context(), request.Pairs)
+	if err != nil {
+		return err
+	}
 
-func (c *AdminController) RefreshAllTrendElements(ctx *fiber.Ctx) error {
-	server := ctx.Params("server")
-	return c.TrendService.RefreshTrendElements(ctx.Context(), server, []string{constant.SourceCategoryAll})
-}
+	failed := len(acks) == 0 || lo.ContainsBy(acks, func(ack *types.PurgeCacheAck) bool {
+		return !ack.OK
+	})
+	if failed {
+		return pgerr.New(http.StatusInternalServerError, "PURGE_CACHE_FAILED", "error occurred while purging cache").
+			WithExtras(pgerr.Extras{
+				"acks": acks,
+			})
+	}
 
-func (c *AdminController) RefreshAllSiteStats(ctx *fiber.Ctx) error {
-	server := ctx.Params("server")
-	_, err := c.SiteStatsService.RefreshShimSiteStats(ctx.Context(), server)
-	return err
+	return ctx.JSON(fiber.Map{
+		"acks": acks,
+	})
 }
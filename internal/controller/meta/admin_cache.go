@@ -0,0 +1,70 @@
+package meta
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+
+	"github.com/penguin-statistics/backend-next/internal/infra"
+	"github.com/penguin-statistics/backend-next/internal/pkg/cache"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
+	"github.com/penguin-statistics/backend-next/internal/server/svr"
+)
+
+// defaultCacheEntriesLimit is the number of entries peeked at when no limit is given
+const defaultCacheEntriesLimit = 100
+
+type AdminCacheController struct {
+	fx.In
+}
+
+func RegisterAdminCache(admin *svr.Admin, c AdminCacheController) {
+	admin.Get("/caches", c.GetCaches)
+	admin.Get("/caches/:name/entries", c.GetCacheEntries)
+}
+
+// GetCaches lists every cache along with its statistics. Caches are per-instance, so are the statistics: they only
+// cover the instance serving the request.
+func (c *AdminCacheController) GetCaches(ctx *fiber.Ctx) error {
+	caches := cache.All()
+	stats := make([]cache.Stats, 0, len(caches))
+	for _, ca := range caches {
+		stats = append(stats, ca.Stats())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"instance": infra.InstanceID(),
+		"caches":   stats,
+	})
+}
+
+// GetCacheEntries lists the keys held by a cache and their expirations. The cache name has to be URL-encoded, as
+// most of them contain "#". Entries may be filtered by the prefix query and capped by the limit query.
+func (c *AdminCacheController) GetCacheEntries(ctx *fiber.Ctx) error {
+	name, err := url.PathUnescape(ctx.Params("name"))
+	if err != nil {
+		return pgerr.ErrInvalidReq.Msg("invalid cache name")
+	}
+	ca, ok := cache.Lookup(name)
+	if !ok {
+		return pgerr.ErrNotFound.Msg("cache not found")
+	}
+
+	limit := ctx.Query("limit")
+	n := defaultCacheEntriesLimit
+	if limit != "" {
+		if n, err = strconv.Atoi(limit); err != nil || n < 0 {
+			return pgerr.ErrInvalidReq.Msg("invalid limit")
+		}
+	}
+	entries, total := ca.Entries(ctx.Query("prefix"), n)
+
+	return ctx.JSON(fiber.Map{
+		"instance": infra.InstanceID(),
+		"stats":    ca.Stats(),
+		"total":    total,
+		"entries":  entries,
+	})
+}
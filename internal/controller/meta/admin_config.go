@@ -0,0 +1,53 @@
+package meta
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+
+	"github.com/penguin-statistics/backend-next/internal/config"
+	"github.com/penguin-statistics/backend-next/internal/infra"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
+	"github.com/penguin-statistics/backend-next/internal/server/svr"
+)
+
+type AdminConfigController struct {
+	fx.In
+
+	ConfigReloader *config.Reloader
+}
+
+func RegisterAdminConfig(admin *svr.Admin, c AdminConfigController) {
+	admin.Get("/config", c.GetConfig)
+	admin.Post("/config/reload", c.ReloadConfig)
+}
+
+// GetConfig shows the configuration in effect on the instance serving the request, with secrets redacted, along
+// with where every setting comes from.
+func (c *AdminConfigController) GetConfig(ctx *fiber.Ctx) error {
+	return ctx.JSON(fiber.Map{
+		"instance": infra.InstanceID(),
+		"file":     c.ConfigReloader.File(),
+		"settings": c.ConfigReloader.Settings(),
+	})
+}
+
+// ReloadConfig reloads the configuration of the instance serving the request right away, instead of waiting for
+// the configuration file to be checked for changes.
+func (c *AdminConfigController) ReloadConfig(ctx *fiber.Ctx) error {
+	result, err := c.ConfigReloader.Reload()
+	if err != nil {
+		extras := pgerr.Extras{}
+		var validationErr *config.ValidationError
+		if errors.As(err, &validationErr) {
+			extras["problems"] = validationErr.Problems
+		}
+		return pgerr.New(fiber.StatusBadRequest, "INVALID_CONFIGURATION", err.Error()).WithExtras(extras)
+	}
+
+	return ctx.JSON(fiber.Map{
+		"instance": infra.InstanceID(),
+		"result":   result,
+	})
+}
@@ -0,0 +1,125 @@
+package meta
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/penguin-statistics/backend-next/internal/model/types"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
+	"github.com/penguin-statistics/backend-next/internal/server/svr"
+	"github.com/penguin-statistics/backend-next/internal/service"
+	"github.com/penguin-statistics/backend-next/internal/util/rekuest"
+)
+
+type AdminFormulaController struct {
+	fx.In
+
+	FormulaService *service.Formula
+}
+
+func RegisterAdminFormula(admin *svr.Admin, c AdminFormulaController) {
+	admin.Get("/formulas", c.GetFormulas)
+	admin.Put("/formulas/:itemId", c.SaveFormula)
+	admin.Delete("/formulas/:itemId", c.DeleteFormula)
+	admin.Post("/formulas/import", c.ImportLegacyFormulas)
+
+	admin.Get("/formulas/revisions", c.GetRevisions)
+	admin.Get("/formulas/revisions/:revisionId", c.GetRevision)
+	admin.Post("/formulas/revisions/:revisionId/rollback", c.RollbackToRevision)
+}
+
+func (c *AdminFormulaController) GetFormulas(ctx *fiber.Ctx) error {
+	formulas, err := c.FormulaService.GetFormulas(ctx.Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(formulas)
+}
+
+func (c *AdminFormulaController) SaveFormula(ctx *fiber.Ctx) error {
+	var request types.SaveFormulaRequest
+	if err := rekuest.ValidBody(ctx, &request); err != nil {
+		return err
+	}
+
+	formula, err := c.FormulaService.SaveFormula(ctx.Context(), ctx.Params("itemId"), &request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(formula)
+}
+
+func (c *AdminFormulaController) DeleteFormula(ctx *fiber.Ctx) error {
+	comment := null.NewString(ctx.Query("comment"), ctx.Query("comment") != "")
+	if err := c.FormulaService.DeleteFormula(ctx.Context(), ctx.Params("itemId"), comment); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (c *AdminFormulaController) ImportLegacyFormulas(ctx *fiber.Ctx) error {
+	// the request body is optional
+	var request types.FormulaChangeRequest
+	if len(ctx.Body()) > 0 {
+		if err := rekuest.ValidBody(ctx, &request); err != nil {
+			return err
+		}
+	}
+
+	formulas, err := c.FormulaService.ImportLegacyFormulas(ctx.Context(), request.Comment)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(formulas)
+}
+
+func (c *AdminFormulaController) GetRevisions(ctx *fiber.Ctx) error {
+	revisions, err := c.FormulaService.GetRevisions(ctx.Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(revisions)
+}
+
+func (c *AdminFormulaController) GetRevision(ctx *fiber.Ctx) error {
+	revisionId, err := strconv.Atoi(ctx.Params("revisionId"))
+	if err != nil {
+		return pgerr.ErrInvalidReq.Msg("invalid revisionId")
+	}
+
+	revision, err := c.FormulaService.GetRevisionById(ctx.Context(), revisionId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(revision)
+}
+
+func (c *AdminFormulaController) RollbackToRevision(ctx *fiber.Ctx) error {
+	revisionId, err := strconv.Atoi(ctx.Params("revisionId"))
+	if err != nil {
+		return pgerr.ErrInvalidReq.Msg("invalid revisionId")
+	}
+
+	// the request body is optional
+	var request types.FormulaChangeRequest
+	if len(ctx.Body()) > 0 {
+		if err := rekuest.ValidBody(ctx, &request); err != nil {
+			return err
+		}
+	}
+
+	if err := c.FormulaService.RollbackToRevision(ctx.Context(), revisionId, request.Comment); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,54 @@
+package meta
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+
+	"github.com/penguin-statistics/backend-next/internal/model/types"
+	"github.com/penguin-statistics/backend-next/internal/server/svr"
+	"github.com/penguin-statistics/backend-next/internal/service"
+	"github.com/penguin-statistics/backend-next/internal/util/rekuest"
+)
+
+type AdminGachaBoxController struct {
+	fx.In
+
+	GachaBoxService *service.GachaBox
+}
+
+func RegisterAdminGachaBox(admin *svr.Admin, c AdminGachaBoxController) {
+	admin.Get("/gachabox/pools", c.GetPools)
+	admin.Put("/gachabox/pools/:stageId", c.SavePool)
+	admin.Delete("/gachabox/pools/:stageId", c.DeletePool)
+}
+
+func (c *AdminGachaBoxController) GetPools(ctx *fiber.Ctx) error {
+	pools, err := c.GachaBoxService.GetPools(ctx.Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(pools)
+}
+
+func (c *AdminGachaBoxController) SavePool(ctx *fiber.Ctx) error {
+	var request types.SaveGachaBoxPoolRequest
+	if err := rekuest.ValidBody(ctx, &request); err != nil {
+		return err
+	}
+
+	pool, err := c.GachaBoxService.SavePool(ctx.Context(), ctx.Params("stageId"), &request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(pool)
+}
+
+func (c *AdminGachaBoxController) DeletePool(ctx *fiber.Ctx) error {
+	if err := c.GachaBoxService.DeletePool(ctx.Context(), ctx.Params("stageId")); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
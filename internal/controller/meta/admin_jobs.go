@@ -0,0 +1,90 @@
+package meta
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+
+	"github.com/penguin-statistics/backend-next/internal/pkg/leader"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
+	"github.com/penguin-statistics/backend-next/internal/server/svr"
+	"github.com/penguin-statistics/backend-next/internal/workers/calcwkr"
+)
+
+type AdminJobsController struct {
+	fx.In
+
+	Scheduler *calcwkr.Scheduler
+	Elector   *leader.Elector
+}
+
+func RegisterAdminJobs(admin *svr.Admin, c AdminJobsController) {
+	admin.Get("/jobs", c.GetJobs)
+	admin.Get("/jobs/:name", c.GetJob)
+	admin.Post("/jobs/:name/trigger", c.TriggerJob)
+	admin.Post("/jobs/:name/pause", c.PauseJob)
+	admin.Post("/jobs/:name/resume", c.ResumeJob)
+}
+
+func (c *AdminJobsController) GetJobs(ctx *fiber.Ctx) error {
+	return ctx.JSON(c.Scheduler.Statuses())
+}
+
+func (c *AdminJobsController) GetJob(ctx *fiber.Ctx) error {
+	status, err := c.Scheduler.Status(ctx.Params("name"))
+	if err != nil {
+		return c.jobError(ctx, err)
+	}
+
+	return ctx.JSON(status)
+}
+
+// TriggerJob queues a manual run of the job and returns immediately; the outcome shows up in the job's history.
+func (c *AdminJobsController) TriggerJob(ctx *fiber.Ctx) error {
+	if err := c.Scheduler.Trigger(ctx.Params("name")); err != nil {
+		return c.jobError(ctx, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusAccepted)
+}
+
+func (c *AdminJobsController) PauseJob(ctx *fiber.Ctx) error {
+	if err := c.Scheduler.SetPaused(ctx.Params("name"), true); err != nil {
+		return c.jobError(ctx, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (c *AdminJobsController) ResumeJob(ctx *fiber.Ctx) error {
+	if err := c.Scheduler.SetPaused(ctx.Params("name"), false); err != nil {
+		return c.jobError(ctx, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (c *AdminJobsController) jobError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, calcwkr.ErrJobNotFound):
+		return pgerr.ErrNotFound.Msg("job not found")
+	case errors.Is(err, calcwkr.ErrJobAlreadyQueued):
+		return pgerr.New(fiber.StatusConflict, "JOB_ALREADY_QUEUED", "job already has a manual run queued")
+	case errors.Is(err, calcwkr.ErrNotLeader):
+		// name the leader so that the operator knows which instance to trigger the job on
+		id, leaderErr := c.Elector.Leader(ctx.Context())
+		if leaderErr != nil {
+			return leaderErr
+		}
+		if id == "" {
+			return pgerr.New(fiber.StatusServiceUnavailable, "NO_LEADER", "jobs only run on the leader instance, and no instance currently leads the worker")
+		}
+		return pgerr.New(fiber.StatusConflict, "NOT_LEADER", "jobs only run on the leader instance, which is currently "+id).
+			WithExtras(pgerr.Extras{
+				"leader": id,
+			})
+	default:
+		return err
+	}
+}
@@ -0,0 +1,57 @@
+package meta
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+
+	"github.com/penguin-statistics/backend-next/internal/model/types"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
+	"github.com/penguin-statistics/backend-next/internal/server/svr"
+	"github.com/penguin-statistics/backend-next/internal/service"
+	"github.com/penguin-statistics/backend-next/internal/util/rekuest"
+)
+
+type AdminMatrixController struct {
+	fx.In
+
+	DropMatrixSnapshotService *service.DropMatrixSnapshot
+}
+
+func RegisterAdminMatrix(admin *svr.Admin, c AdminMatrixController) {
+	admin.Get("/matrix/snapshots", c.GetSnapshots)
+	admin.Get("/matrix/changes", c.GetChanges)
+}
+
+func (c *AdminMatrixController) GetSnapshots(ctx *fiber.Ctx) error {
+	var query types.DropMatrixSnapshotsQuery
+	if err := ctx.QueryParser(&query); err != nil {
+		return pgerr.ErrInvalidReq.Msg("invalid request: %s", err)
+	}
+	if err := rekuest.ValidStruct(ctx, &query); err != nil {
+		return err
+	}
+
+	snapshots, err := c.DropMatrixSnapshotService.GetSnapshots(ctx.Context(), &query)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(snapshots)
+}
+
+func (c *AdminMatrixController) GetChanges(ctx *fiber.Ctx) error {
+	var query types.DropMatrixChangesQuery
+	if err := ctx.QueryParser(&query); err != nil {
+		return pgerr.ErrInvalidReq.Msg("invalid request: %s", err)
+	}
+	if err := rekuest.ValidStruct(ctx, &query); err != nil {
+		return err
+	}
+
+	changes, err := c.DropMatrixSnapshotService.GetChanges(ctx.Context(), &query)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(changes)
+}
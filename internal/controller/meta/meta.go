@@ -8,6 +8,8 @@ import (
 	"go.uber.org/fx"
 
 	"github.com/penguin-statistics/backend-next/internal/pkg/bininfo"
+	"github.com/penguin-statistics/backend-next/internal/pkg/flake"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
 	"github.com/penguin-statistics/backend-next/internal/server/svr"
 	"github.com/penguin-statistics/backend-next/internal/service"
 )
@@ -15,7 +17,9 @@ import (
 type Meta struct {
 	fx.In
 
-	HealthService *service.Health
+	HealthService  *service.Health
+	WarmupService  *service.Warmup
+	FlakeNodeLease *flake.NodeLease
 }
 
 func RegisterMeta(meta *svr.Meta, c Meta) {
@@ -25,21 +29,59 @@ func RegisterMeta(meta *svr.Meta, c Meta) {
 		// cache it for a second to mitigate potential DDoS
 		Expiration: time.Second,
 	}), c.Health)
+	meta.Get("/health/live", c.Liveness)
+	meta.Get("/health/ready", cache.New(cache.Config{
+		Expiration: time.Second,
+	}), c.Readiness)
 }
 
 func (c *Meta) BinInfo(ctx *fiber.Ctx) error {
 	return ctx.JSON(fiber.Map{
 		"version": bininfo.Version,
 		"build":   bininfo.BuildTime,
+		"node":    c.FlakeNodeLease.Node(),
 	})
 }
 
 func (c *Meta) Health(ctx *fiber.Ctx) error {
+	warmup := c.WarmupService.Status()
+	if !c.WarmupService.Ready() {
+		return pgerr.New(fiber.StatusServiceUnavailable, "WARMING_UP", "instance is warming up its caches").
+			WithExtras(pgerr.Extras{
+				"warmup": warmup,
+			})
+	}
+
 	if err := c.HealthService.Ping(ctx.Context()); err != nil {
 		return err
 	}
 
+	leader, err := c.HealthService.WorkerLeader(ctx.Context())
+	if err != nil {
+		return err
+	}
+
 	return ctx.JSON(fiber.Map{
 		"status": "ok",
+		"leader": leader,
+		"warmup": warmup,
 	})
 }
+
+// Liveness reports that the process is up and serving requests, without checking any dependency, so that an
+// outage of a dependency does not get instances restarted.
+func (c *Meta) Liveness(ctx *fiber.Ctx) error {
+	return ctx.JSON(fiber.Map{
+		"status": service.ComponentStatusUp,
+	})
+}
+
+// Readiness reports the status of every component this instance depends on, and responds with 503 if any critical
+// one is down, so that no traffic is routed to this instance.
+func (c *Meta) Readiness(ctx *fiber.Ctx) error {
+	readiness := c.HealthService.Readiness(ctx.Context())
+	if readiness.Status == service.ComponentStatusDown {
+		ctx.Status(fiber.StatusServiceUnavailable)
+	}
+	return ctx.JSON(readiness)
+}
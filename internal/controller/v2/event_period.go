@@ -1,8 +1,6 @@
 package v2
 
 import (
-	"time"
-
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/fx"
 
@@ -31,14 +29,12 @@ func RegisterEventPeriod(v2 *svr.V2, c EventPeriod) {
 // @Router   /PenguinStats/api/v2/period [GET]
 func (c *EventPeriod) GetEventPeriods(ctx *fiber.Ctx) (err error) {
 	var activities []*modelv2.Activity
+	lastModifiedKey := "[shimActivities]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
 	activities, err = c.ActivityService.GetShimActivities(ctx.Context())
 	if err != nil {
 		return err
 	}
-	var lastModifiedTime time.Time
-	if err := cache.LastModifiedTime.Get("[shimActivities]", &lastModifiedTime); err != nil {
-		lastModifiedTime = time.Now()
-	}
-	cachectrl.OptIn(ctx, lastModifiedTime)
-	return ctx.JSON(activities)
+
+	return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, activities)
 }
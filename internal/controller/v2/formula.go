@@ -4,6 +4,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/fx"
 
+	"github.com/penguin-statistics/backend-next/internal/model/cache"
+	"github.com/penguin-statistics/backend-next/internal/pkg/cachectrl"
 	"github.com/penguin-statistics/backend-next/internal/server/svr"
 	"github.com/penguin-statistics/backend-next/internal/service"
 )
@@ -21,13 +23,16 @@ func RegisterFormula(v2 *svr.V2, c Formula) {
 // @Summary  Get Formula
 // @Tags     Formula
 // @Produce  json
-// @Success  200
+// @Success  200  {array}   modelv2.Formula
 // @Failure  500  {object}  pgerr.PenguinError  "An unexpected error occurred"
 // @Router   /PenguinStats/api/v2/formula [GET]
 func (c *Formula) GetFormula(ctx *fiber.Ctx) error {
-	formula, err := c.FormulaService.GetFormula(ctx.Context())
+	lastModifiedKey := "[shimFormula]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
+	formula, err := c.FormulaService.GetShimFormula(ctx.Context())
 	if err != nil {
 		return err
 	}
-	return ctx.JSON(formula)
+
+	return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, formula)
 }
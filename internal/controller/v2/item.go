@@ -1,8 +1,6 @@
 package v2
 
 import (
-	"time"
-
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/fx"
 
@@ -33,16 +31,13 @@ func RegisterItem(v2 *svr.V2, c Item) {
 // @Failure  500  {object}  pgerr.PenguinError  "An unexpected error occurred"
 // @Router   /PenguinStats/api/v2/items [GET]
 func (c *Item) GetItems(ctx *fiber.Ctx) error {
+	lastModifiedKey := "[shimItems]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
 	items, err := c.ItemService.GetShimItems(ctx.Context())
 	if err != nil {
 		return err
 	}
-	var lastModifiedTime time.Time
-	if err := cache.LastModifiedTime.Get("[shimItems]", &lastModifiedTime); err != nil {
-		lastModifiedTime = time.Now()
-	}
-	cachectrl.OptIn(ctx, lastModifiedTime)
-	return ctx.JSON(items)
+	return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, items)
 }
 
 // @Summary  Get an Item with ID
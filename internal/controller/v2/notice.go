@@ -1,8 +1,6 @@
 package v2
 
 import (
-	"time"
-
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/fx"
 
@@ -29,14 +27,11 @@ func RegisterNotice(v2 *svr.V2, c Notice) {
 // @Failure  500  {object}  pgerr.PenguinError  "An unexpected error occurred"
 // @Router   /PenguinStats/api/v2/notice [GET]
 func (c *Notice) GetNotices(ctx *fiber.Ctx) error {
+	lastModifiedKey := "[notices]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
 	notices, err := c.NoticeService.GetNotices(ctx.Context())
 	if err != nil {
 		return err
 	}
-	var lastModifiedTime time.Time
-	if err := cache.LastModifiedTime.Get("[notices]", &lastModifiedTime); err != nil {
-		lastModifiedTime = time.Now()
-	}
-	cachectrl.OptIn(ctx, lastModifiedTime)
-	return ctx.JSON(notices)
+	return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, notices)
 }
@@ -1,8 +1,6 @@
 package v2
 
 import (
-	"time"
-
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/fx"
 	"gopkg.in/guregu/null.v3"
@@ -37,7 +35,7 @@ func RegisterPrivate(v2 *svr.V2, c Private) {
 
 // @Summary  Get Drop Matrix
 // @Tags     Private
-// @Produce  json
+// @Produce  json,application/x-protobuf,application/msgpack
 // @Param    server  path      string  true  "Server; default to CN"                  Enums(CN, US, JP, KR)
 // @Param    source  path      string  true  "Global or Personal; default to global"  Enums(global, personal)
 // @Success  200     {object}  modelv2.DropMatrixQueryResult
@@ -57,26 +55,24 @@ func (c *Private) GetDropMatrix(ctx *fiber.Ctx) error {
 		accountId.Valid = true
 	}
 
+	key := server + constant.CacheSep + "true"
+	lastModifiedKey := "[shimMaxAccumulableDropMatrixResults#server|showClosedZoned:" + key + "]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
 	shimResult, err := c.DropMatrixService.GetShimMaxAccumulableDropMatrixResults(ctx.Context(), server, true, "", "", accountId)
 	if err != nil {
 		return err
 	}
 
 	if !accountId.Valid {
-		key := server + constant.CacheSep + "true"
-		var lastModifiedTime time.Time
-		if err := cache.LastModifiedTime.Get("[shimMaxAccumulableDropMatrixResults#server|showClosedZoned:"+key+"]", &lastModifiedTime); err != nil {
-			lastModifiedTime = time.Now()
-		}
-		cachectrl.OptIn(ctx, lastModifiedTime)
+		return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, shimResult)
 	}
 
-	return ctx.JSON(shimResult)
+	return cachectrl.Send(ctx, shimResult)
 }
 
 // @Summary  Get Pattern Matrix
 // @Tags     Private
-// @Produce  json
+// @Produce  json,application/x-protobuf,application/msgpack
 // @Param    server  path      string  true  "Server; default to CN"                  Enums(CN, US, JP, KR)
 // @Param    source  path      string  true  "Global or Personal; default to global"  Enums(global, personal)
 // @Success  200     {object}  modelv2.PatternMatrixQueryResult
@@ -96,41 +92,35 @@ func (c *Private) GetPatternMatrix(ctx *fiber.Ctx) error {
 		accountId.Valid = true
 	}
 
+	lastModifiedKey := "[shimLatestPatternMatrixResults#server:" + server + "]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
 	shimResult, err := c.PatternMatrixService.GetShimLatestPatternMatrixResults(ctx.Context(), server, accountId)
 	if err != nil {
 		return err
 	}
 
 	if !accountId.Valid {
-		var lastModifiedTime time.Time
-		if err := cache.LastModifiedTime.Get("[shimLatestPatternMatrixResults#server:"+server+"]", &lastModifiedTime); err != nil {
-			lastModifiedTime = time.Now()
-		}
-		cachectrl.OptIn(ctx, lastModifiedTime)
+		return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, shimResult)
 	}
 
-	return ctx.JSON(shimResult)
+	return cachectrl.Send(ctx, shimResult)
 }
 
 // @Summary  Get Trends
 // @Tags     Private
-// @Produce  json
+// @Produce  json,application/x-protobuf,application/msgpack
 // @Param    server  path      string  true  "Server; default to CN"  Enums(CN, US, JP, KR)
 // @Success  200     {object}  modelv2.TrendQueryResult
 // @Failure  500     {object}  pgerr.PenguinError  "An unexpected error occurred"
 // @Router   /PenguinStats/api/v2/_private/result/trend/{server} [GET]
 func (c *Private) GetTrends(ctx *fiber.Ctx) error {
 	server := ctx.Params("server")
+	lastModifiedKey := "[shimSavedTrendResults#server:" + server + "]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
 	shimResult, err := c.TrendService.GetShimSavedTrendResults(ctx.Context(), server)
 	if err != nil {
 		return err
 	}
 
-	var lastModifiedTime time.Time
-	if err := cache.LastModifiedTime.Get("[shimSavedTrendResults#server:"+server+"]", &lastModifiedTime); err != nil {
-		lastModifiedTime = time.Now()
-	}
-	cachectrl.OptIn(ctx, lastModifiedTime)
-
-	return ctx.JSON(shimResult)
+	return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, shimResult)
 }
@@ -1,7 +1,9 @@
 package v2
 
 import (
+	"fmt"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,6 +11,7 @@ import (
 	"go.uber.org/fx"
 	"gopkg.in/guregu/null.v3"
 
+	"github.com/penguin-statistics/backend-next/internal/config"
 	"github.com/penguin-statistics/backend-next/internal/constant"
 	"github.com/penguin-statistics/backend-next/internal/model"
 	"github.com/penguin-statistics/backend-next/internal/model/cache"
@@ -27,6 +30,7 @@ var ErrIntervalLengthTooSmall = pgerr.ErrInvalidReq.Msg("interval length must be
 type Result struct {
 	fx.In
 
+	ConfigReloader       *config.Reloader
 	DropMatrixService    *service.DropMatrix
 	PatternMatrixService *service.PatternMatrix
 	TrendService         *service.Trend
@@ -39,26 +43,55 @@ func RegisterResult(v2 *svr.V2, c Result) {
 	v2.Get("/result/matrix", c.GetDropMatrix)
 	v2.Get("/result/pattern", c.GetPatternMatrix)
 	v2.Get("/result/trends", c.GetTrends)
-	v2.Post("/result/advanced", limiter.New(limiter.Config{
+	v2.Post("/result/advanced", c.advancedQueryLimiter(), c.AdvancedQuery)
+}
+
+// advancedQueryLimiter limits the rate of advanced queries of every client as configured. The limiter is replaced
+// whenever the limits change upon a configuration reload, which resets the counts of requests.
+func (c *Result) advancedQueryLimiter() fiber.Handler {
+	var handler atomic.Value
+	handler.Store(newAdvancedQueryLimiter(c.ConfigReloader.Current()))
+	c.ConfigReloader.OnReload(func(prev *config.Config, next *config.Config) {
+		if prev.AdvancedQueryLimit != next.AdvancedQueryLimit || prev.AdvancedQueryLimitWindow != next.AdvancedQueryLimitWindow {
+			handler.Store(newAdvancedQueryLimiter(next))
+		}
+	})
+
+	return func(ctx *fiber.Ctx) error {
+		return handler.Load().(fiber.Handler)(ctx)
+	}
+}
+
+func newAdvancedQueryLimiter(conf *config.Config) fiber.Handler {
+	window := conf.AdvancedQueryLimitWindow.String()
+	if conf.AdvancedQueryLimitWindow == time.Minute {
+		window = "minute"
+	} else if conf.AdvancedQueryLimitWindow%time.Minute == 0 {
+		window = fmt.Sprintf("%d minutes", conf.AdvancedQueryLimitWindow/time.Minute)
+	}
+	message := fmt.Sprintf("Your client is sending requests too frequently. The Penguin Stats advanced query API is limited to %d requests per %s.", conf.AdvancedQueryLimit, window)
+
+	return limiter.New(limiter.Config{
 		LimitReached: func(c *fiber.Ctx) error {
 			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
 				"code":    "TOO_MANY_REQUESTS",
-				"message": "Your client is sending requests too frequently. The Penguin Stats advanced query API is limited to 30 requests per 5 minutes.",
+				"message": message,
 			})
 		},
-		Max:        30,
-		Expiration: time.Minute * 5,
-	}), c.AdvancedQuery)
+		Max:        conf.AdvancedQueryLimit,
+		Expiration: conf.AdvancedQueryLimitWindow,
+	})
 }
 
 // @Summary   Get Drop Matrix
 // @Tags      Result
-// @Produce   json
+// @Produce   json,application/x-protobuf,application/msgpack
 // @Param     server             query     string                         true   "Server; default to CN"  Enums(CN, US, JP, KR)
 // @Param     is_personal        query     bool                           false  "Whether to query for personal drop matrix or not. If `is_personal` equals to `true`, a valid PenguinID would be required to be provided (PenguinIDAuth)"
 // @Param     show_closed_zones  query     bool                           false  "Whether to show closed stages or not"
 // @Param     stageFilter        query     []string                       false  "Comma separated list of stage IDs to filter"  collectionFormat(csv)
 // @Param     itemFilter         query     []string                       false  "Comma separated list of item IDs to filter"   collectionFormat(csv)
+// @Param     shrinkage          query     bool                           false  "Whether to annotate elements with drop rates shrunk toward the rates of comparable stages, which are more stable for stages with few samples"
 // @Success   200                {object}  modelv2.DropMatrixQueryResult  "Drop Matrix response"
 // @Failure   500                {object}  pgerr.PenguinError             "An unexpected error occurred"
 // @Security  PenguinIDAuth
@@ -79,6 +112,10 @@ func (c *Result) GetDropMatrix(ctx *fiber.Ctx) error {
 	}
 	stageFilterStr := ctx.Query("stageFilter")
 	itemFilterStr := ctx.Query("itemFilter")
+	shrinkage, err := strconv.ParseBool(ctx.Query("shrinkage", "false"))
+	if err != nil {
+		return err
+	}
 
 	accountId := null.NewInt(0, false)
 	if isPersonal {
@@ -90,27 +127,42 @@ func (c *Result) GetDropMatrix(ctx *fiber.Ctx) error {
 		accountId.Valid = true
 	}
 
+	useCache := !accountId.Valid && stageFilterStr == "" && itemFilterStr == ""
+	key := server + constant.CacheSep + strconv.FormatBool(showClosedZones)
+	if useCache && shrinkage {
+		// the global matrix with shrinkage estimates is cached by itself, so that they are not estimated per request
+		lastModifiedKey := "[shrunkMaxAccumulableDropMatrixResults#server|showClosedZoned:" + key + "]"
+		lastModifiedTime := cache.LastModified(lastModifiedKey)
+		shrunkResult, err := c.DropMatrixService.GetShrunkMaxAccumulableDropMatrixResults(ctx.Context(), server, showClosedZones)
+		if err != nil {
+			return err
+		}
+		return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, shrunkResult)
+	}
+
+	lastModifiedKey := "[shimMaxAccumulableDropMatrixResults#server|showClosedZoned:" + key + "]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
 	shimQueryResult, err := c.DropMatrixService.GetShimMaxAccumulableDropMatrixResults(ctx.Context(), server, showClosedZones, stageFilterStr, itemFilterStr, accountId)
 	if err != nil {
 		return err
 	}
+	if shrinkage {
+		shimQueryResult, err = c.DropMatrixService.ApplyShrinkageEstimates(ctx.Context(), server, shimQueryResult)
+		if err != nil {
+			return err
+		}
+	}
 
-	useCache := !accountId.Valid && stageFilterStr == "" && itemFilterStr == ""
 	if useCache {
-		key := server + constant.CacheSep + strconv.FormatBool(showClosedZones)
-		var lastModifiedTime time.Time
-		if err := cache.LastModifiedTime.Get("[shimMaxAccumulableDropMatrixResults#server|showClosedZoned:"+key+"]", &lastModifiedTime); err != nil {
-			lastModifiedTime = time.Now()
-		}
-		cachectrl.OptIn(ctx, lastModifiedTime)
+		return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, shimQueryResult)
 	}
 
-	return ctx.JSON(shimQueryResult)
+	return cachectrl.Send(ctx, shimQueryResult)
 }
 
 // @Summary   Get Pattern Matrix
 // @Tags      Result
-// @Produce   json
+// @Produce   json,application/x-protobuf,application/msgpack
 // @Param     server       query     string  true   "Server; default to CN"  Enums(CN, US, JP, KR)
 // @Param     is_personal  query     bool    false  "Whether to query for personal drop matrix or not. If `is_personal` equals to `true`, a valid PenguinID would be required to be provided (PenguinIDAuth)"
 // @Success   200          {object}  modelv2.PatternMatrixQueryResult
@@ -138,25 +190,23 @@ func (c *Result) GetPatternMatrix(ctx *fiber.Ctx) error {
 		accountId.Valid = true
 	}
 
+	lastModifiedKey := "[shimLatestPatternMatrixResults#server:" + server + "]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
 	shimResult, err := c.PatternMatrixService.GetShimLatestPatternMatrixResults(ctx.Context(), server, accountId)
 	if err != nil {
 		return err
 	}
 
 	if !accountId.Valid {
-		var lastModifiedTime time.Time
-		if err := cache.LastModifiedTime.Get("[shimLatestPatternMatrixResults#server:"+server+"]", &lastModifiedTime); err != nil {
-			lastModifiedTime = time.Now()
-		}
-		cachectrl.OptIn(ctx, lastModifiedTime)
+		return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, shimResult)
 	}
 
-	return ctx.JSON(shimResult)
+	return cachectrl.Send(ctx, shimResult)
 }
 
 // @Summary  Get Trends
 // @Tags     Result
-// @Produce  json
+// @Produce  json,application/x-protobuf,application/msgpack
 // @Param    server  query     string  true  "Server; default to CN"  Enums(CN, US, JP, KR)
 // @Success  200     {object}  modelv2.TrendQueryResult
 // @Failure  500     {object}  pgerr.PenguinError  "An unexpected error occurred"
@@ -167,18 +217,14 @@ func (c *Result) GetTrends(ctx *fiber.Ctx) error {
 		return err
 	}
 
+	lastModifiedKey := "[shimSavedTrendResults#server:" + server + "]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
 	shimResult, err := c.TrendService.GetShimSavedTrendResults(ctx.Context(), server)
 	if err != nil {
 		return err
 	}
 
-	var lastModifiedTime time.Time
-	if err := cache.LastModifiedTime.Get("[shimSavedTrendResults#server:"+server+"]", &lastModifiedTime); err != nil {
-		lastModifiedTime = time.Now()
-	}
-	cachectrl.OptIn(ctx, lastModifiedTime)
-
-	return ctx.JSON(shimResult)
+	return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, shimResult)
 }
 
 // @Summary  Execute Advanced Query
@@ -1,8 +1,6 @@
 package v2
 
 import (
-	"time"
-
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/fx"
 
@@ -39,16 +37,12 @@ func (c *SiteStats) GetSiteStats(ctx *fiber.Ctx) error {
 		return err
 	}
 
+	lastModifiedKey := "[shimSiteStats#server:" + server + "]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
 	siteStats, err := c.SiteStatsService.GetShimSiteStats(ctx.Context(), server)
 	if err != nil {
 		return err
 	}
 
-	var lastModifiedTime time.Time
-	if err := cache.LastModifiedTime.Get("[shimSiteStats#server:"+server+"]", &lastModifiedTime); err != nil {
-		lastModifiedTime = time.Now()
-	}
-	cachectrl.OptIn(ctx, lastModifiedTime)
-
-	return ctx.JSON(siteStats)
+	return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, siteStats)
 }
@@ -1,8 +1,6 @@
 package v2
 
 import (
-	"time"
-
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/fx"
 
@@ -35,16 +33,13 @@ func RegisterStage(v2 *svr.V2, c Stage) {
 func (c *Stage) GetStages(ctx *fiber.Ctx) error {
 	server := ctx.Query("server", "CN")
 
+	lastModifiedKey := "[shimStages#server:" + server + "]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
 	stages, err := c.StageService.GetShimStages(ctx.Context(), server)
 	if err != nil {
 		return err
 	}
-	var lastModifiedTime time.Time
-	if err := cache.LastModifiedTime.Get("[shimStages#server:"+server+"]", &lastModifiedTime); err != nil {
-		lastModifiedTime = time.Now()
-	}
-	cachectrl.OptIn(ctx, lastModifiedTime)
-	return ctx.JSON(stages)
+	return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, stages)
 }
 
 // @Summary  Get a Stage with ID
@@ -1,8 +1,6 @@
 package v2
 
 import (
-	"time"
-
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/fx"
 
@@ -33,16 +31,13 @@ func RegisterZone(v2 *svr.V2, c Zone) {
 // @Failure  500  {object}  pgerr.PenguinError  "An unexpected error occurred"
 // @Router   /PenguinStats/api/v2/zones [GET]
 func (c *Zone) GetZones(ctx *fiber.Ctx) error {
+	lastModifiedKey := "[shimZones]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
 	zones, err := c.ZoneService.GetShimZones(ctx.Context())
 	if err != nil {
 		return err
 	}
-	var lastModifiedTime time.Time
-	if err := cache.LastModifiedTime.Get("[shimZones]", &lastModifiedTime); err != nil {
-		lastModifiedTime = time.Now()
-	}
-	cachectrl.OptIn(ctx, lastModifiedTime)
-	return ctx.JSON(zones)
+	return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, zones)
 }
 
 // @Summary  Get a Zone with ID
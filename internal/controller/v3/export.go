@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+
+	"github.com/penguin-statistics/backend-next/internal/pkg/cachectrl"
+	"github.com/penguin-statistics/backend-next/internal/server/svr"
+	"github.com/penguin-statistics/backend-next/internal/service"
+)
+
+type ExportController struct {
+	fx.In
+
+	ExportService *service.Export
+}
+
+func RegisterExportController(v3 *svr.V3, c ExportController) {
+	v3.Get("/exports/manifest", c.GetManifest)
+	v3.Static("/exports/files", c.ExportService.Dir, fiber.Static{
+		ByteRange: true,
+		Download:  true,
+		// never serve the temporary files of exports being written, which are dotfiles; the decoded path is checked
+		// as it is the one files are looked up by
+		Next: func(ctx *fiber.Ctx) bool {
+			return strings.Contains(string(ctx.Request().URI().Path()), "/.")
+		},
+	})
+}
+
+// @Summary      Get Open Data Export Manifest
+// @Description  Lists all available open data exports with their checksums. Each file could be downloaded at `/api/v3/exports/files/{path}`, which supports range requests.
+// @Tags         Export
+// @Produce      json
+// @Success      200  {object}  model.ExportManifest
+// @Failure      500  {object}  pgerr.PenguinError  "An unexpected error occurred"
+// @Router       /api/v3/exports/manifest [GET]
+func (c *ExportController) GetManifest(ctx *fiber.Ctx) error {
+	manifest, err := c.ExportService.GetManifest()
+	if err != nil {
+		return err
+	}
+
+	if !manifest.GeneratedAt.IsZero() {
+		cachectrl.OptIn(ctx, manifest.GeneratedAt)
+	}
+
+	return ctx.JSON(manifest)
+}
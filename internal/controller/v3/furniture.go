@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+
+	"github.com/penguin-statistics/backend-next/internal/model/cache"
+	"github.com/penguin-statistics/backend-next/internal/pkg/cachectrl"
+	"github.com/penguin-statistics/backend-next/internal/server/svr"
+	"github.com/penguin-statistics/backend-next/internal/service"
+	"github.com/penguin-statistics/backend-next/internal/util/rekuest"
+)
+
+type FurnitureController struct {
+	fx.In
+
+	FurnitureService *service.Furniture
+}
+
+func RegisterFurnitureController(v3 *svr.V3, c FurnitureController) {
+	v3.Get("/furniture/drops", c.GetFurnitureDrops)
+}
+
+// @Summary      Get Furniture Drop Rates
+// @Description  Lists every stage dropping furniture, with the probability of a run dropping furniture and its 95% Wilson score interval, over all reliable reports. Calculated separately from the drop matrix, and recalculated periodically.
+// @Tags         Furniture
+// @Produce      json
+// @Param        server  query     string  true  "Server; default to CN"  Enums(CN, US, JP, KR)
+// @Success      200     {object}  model.FurnitureDrops
+// @Failure      500     {object}  pgerr.PenguinError  "An unexpected error occurred"
+// @Router       /api/v3/furniture/drops [GET]
+func (c *FurnitureController) GetFurnitureDrops(ctx *fiber.Ctx) error {
+	server := ctx.Query("server", "CN")
+	if err := rekuest.ValidServer(ctx, server); err != nil {
+		return err
+	}
+
+	lastModifiedKey := "[furnitureDrops#server:" + server + "]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
+	furnitureDrops, err := c.FurnitureService.GetFurnitureDrops(ctx.Context(), server)
+	if err != nil {
+		return err
+	}
+
+	return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, furnitureDrops)
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+
+	"github.com/penguin-statistics/backend-next/internal/constant"
+	"github.com/penguin-statistics/backend-next/internal/model/cache"
+	"github.com/penguin-statistics/backend-next/internal/pkg/cachectrl"
+	"github.com/penguin-statistics/backend-next/internal/server/svr"
+	"github.com/penguin-statistics/backend-next/internal/service"
+	"github.com/penguin-statistics/backend-next/internal/util"
+	"github.com/penguin-statistics/backend-next/internal/util/rekuest"
+)
+
+type GachaBoxController struct {
+	fx.In
+
+	GachaBoxService *service.GachaBox
+	AccountService  *service.Account
+}
+
+func RegisterGachaBoxController(v3 *svr.V3, c GachaBoxController) {
+	v3.Get("/gachabox/pools", c.GetPools)
+	v3.Get("/gachabox/:stageId", buildSanitizer(util.NonNullString), c.GetDistribution)
+	v3.Get("/gachabox/:stageId/remaining", buildSanitizer(util.NonNullString), c.GetRemainingPool)
+}
+
+// @Summary      Get All Gacha Box Pools
+// @Description  Lists the pool definition of every gacha box stage.
+// @Tags         GachaBox
+// @Produce      json
+// @Success      200     {array}   model.GachaBoxPool
+// @Failure      500     {object}  pgerr.PenguinError  "An unexpected error occurred"
+// @Router       /api/v3/gachabox/pools [GET]
+func (c *GachaBoxController) GetPools(ctx *fiber.Ctx) error {
+	pools, err := c.GachaBoxService.GetPools(ctx.Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(pools)
+}
+
+// @Summary      Get Distribution of a Gacha Box
+// @Description  Compares the share of every item in the pool definition of the gacha box stage with the share observed from reliable reports. Recalculated along with the drop matrix.
+// @Tags         GachaBox
+// @Produce      json
+// @Param        stageId  path      string  true  "Stage ID"                example(act11d0_rep_gacha)
+// @Param        server   query     string  true  "Server; default to CN"  Enums(CN, US, JP, KR)
+// @Success      200      {object}  model.GachaBoxDistribution
+// @Failure      404      {object}  pgerr.PenguinError  "Pool of the stage not found"
+// @Failure      500      {object}  pgerr.PenguinError  "An unexpected error occurred"
+// @Router       /api/v3/gachabox/{stageId} [GET]
+func (c *GachaBoxController) GetDistribution(ctx *fiber.Ctx) error {
+	stageId := strings.TrimSpace(ctx.Params("stageId"))
+	server := ctx.Query("server", "CN")
+	if err := rekuest.ValidServer(ctx, server); err != nil {
+		return err
+	}
+
+	lastModifiedKey := "[gachaBoxDistribution#server|arkStageId:" + server + constant.CacheSep + stageId + "]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
+	distribution, err := c.GachaBoxService.GetDistribution(ctx.Context(), server, stageId)
+	if err != nil {
+		return err
+	}
+
+	return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, distribution)
+}
+
+// @Summary      Get Personal Remaining Pool of a Gacha Box
+// @Description  Infers the box the caller is currently drawing from based on the draws the caller has reported, and returns what is left in it, the chance of drawing each item next, and the cost to clear it. Requires a PenguinID.
+// @Tags         GachaBox
+// @Produce      json
+// @Param        stageId  path      string  true  "Stage ID"                example(act11d0_rep_gacha)
+// @Param        server   query     string  true  "Server; default to CN"  Enums(CN, US, JP, KR)
+// @Success      200      {object}  model.GachaBoxRemainingPool
+// @Failure      400      {object}  pgerr.PenguinError  "PenguinID not provided or invalid"
+// @Failure      404      {object}  pgerr.PenguinError  "Pool of the stage not found"
+// @Failure      500      {object}  pgerr.PenguinError  "An unexpected error occurred"
+// @Security     PenguinIDAuth
+// @Router       /api/v3/gachabox/{stageId}/remaining [GET]
+func (c *GachaBoxController) GetRemainingPool(ctx *fiber.Ctx) error {
+	cachectrl.OptOut(ctx)
+
+	stageId := strings.TrimSpace(ctx.Params("stageId"))
+	server := ctx.Query("server", "CN")
+	if err := rekuest.ValidServer(ctx, server); err != nil {
+		return err
+	}
+
+	account, err := c.AccountService.GetAccountFromRequest(ctx)
+	if err != nil {
+		return err
+	}
+
+	remainingPool, err := c.GachaBoxService.GetRemainingPool(ctx.Context(), server, stageId, account.AccountID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(remainingPool)
+}
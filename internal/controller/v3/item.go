@@ -6,6 +6,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/fx"
 
+	"github.com/penguin-statistics/backend-next/internal/model/cache"
+	"github.com/penguin-statistics/backend-next/internal/pkg/cachectrl"
 	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
 	"github.com/penguin-statistics/backend-next/internal/server/svr"
 	"github.com/penguin-statistics/backend-next/internal/service"
@@ -38,12 +40,14 @@ func buildSanitizer(sanitizer ...func(string) bool) func(ctx *fiber.Ctx) error {
 }
 
 func (c *ItemController) GetItems(ctx *fiber.Ctx) error {
+	lastModifiedKey := "[items]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
 	items, err := c.ItemService.GetItems(ctx.Context())
 	if err != nil {
 		return err
 	}
 
-	return ctx.JSON(items)
+	return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, items)
 }
 
 func (c *ItemController) GetItemById(ctx *fiber.Ctx) error {
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+
+	"github.com/penguin-statistics/backend-next/internal/constant"
+	"github.com/penguin-statistics/backend-next/internal/model/cache"
+	"github.com/penguin-statistics/backend-next/internal/pkg/cachectrl"
+	"github.com/penguin-statistics/backend-next/internal/server/svr"
+	"github.com/penguin-statistics/backend-next/internal/service"
+	"github.com/penguin-statistics/backend-next/internal/util"
+	"github.com/penguin-statistics/backend-next/internal/util/rekuest"
+)
+
+type ItemDropsController struct {
+	fx.In
+
+	DropMatrixService *service.DropMatrix
+}
+
+func RegisterItemDropsController(v3 *svr.V3, c ItemDropsController) {
+	v3.Get("/items/:itemId/drops", buildSanitizer(util.NonNullString), c.GetItemDrops)
+}
+
+// @Summary      Get Drops of an Item
+// @Description  Lists every stage dropping the item, with the quantity, times, drop rate and sanity cost per item in each of the stage's time ranges, as of the latest periodic drop matrix calculation.
+// @Tags         Item
+// @Produce      json
+// @Param        itemId  path      string  true  "Item ID"                 example(30012)
+// @Param        server  query     string  true  "Server; default to CN"  Enums(CN, US, JP, KR)
+// @Success      200     {object}  model.ItemDrops
+// @Failure      404     {object}  pgerr.PenguinError  "Item not found"
+// @Failure      500     {object}  pgerr.PenguinError  "An unexpected error occurred"
+// @Router       /api/v3/items/{itemId}/drops [GET]
+func (c *ItemDropsController) GetItemDrops(ctx *fiber.Ctx) error {
+	itemId := strings.TrimSpace(ctx.Params("itemId"))
+	server := ctx.Query("server", "CN")
+	if err := rekuest.ValidServer(ctx, server); err != nil {
+		return err
+	}
+
+	lastModifiedKey := "[itemDrops#server|arkItemId:" + server + constant.CacheSep + itemId + "]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
+	itemDrops, err := c.DropMatrixService.GetItemDrops(ctx.Context(), server, itemId)
+	if err != nil {
+		return err
+	}
+
+	return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, itemDrops)
+}
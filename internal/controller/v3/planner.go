@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"go.uber.org/fx"
+
+	"github.com/penguin-statistics/backend-next/internal/model/types"
+	"github.com/penguin-statistics/backend-next/internal/server/svr"
+	"github.com/penguin-statistics/backend-next/internal/service"
+	"github.com/penguin-statistics/backend-next/internal/util/rekuest"
+)
+
+type PlannerController struct {
+	fx.In
+
+	PlannerService *service.Planner
+}
+
+func RegisterPlannerController(v3 *svr.V3, c PlannerController) {
+	v3.Post("/planner", limiter.New(limiter.Config{
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"code":    "TOO_MANY_REQUESTS",
+				"message": "Your client is sending requests too frequently. The Penguin Stats planner API is limited to 30 requests per 5 minutes.",
+			})
+		},
+		Max:        30,
+		Expiration: time.Minute * 5,
+	}), c.Plan)
+}
+
+// @Summary      Plan Farming
+// @Description  Calculates the sanity-optimal combination of stage runs and craftings to obtain the requested items, based on the drop matrix of currently open stages.
+// @Tags         Planner
+// @Accept       json
+// @Produce      json
+// @Param        request  body      types.PlannerRequest  true  "Planner request"
+// @Success      200      {object}  model.PlannerResult
+// @Failure      400      {object}  pgerr.PenguinError  "Invalid request, or the requested items could not be obtained"
+// @Failure      500      {object}  pgerr.PenguinError  "An unexpected error occurred"
+// @Router       /api/v3/planner [POST]
+func (c *PlannerController) Plan(ctx *fiber.Ctx) error {
+	var request types.PlannerRequest
+	if err := rekuest.ValidBody(ctx, &request); err != nil {
+		return err
+	}
+
+	result, err := c.PlannerService.Plan(ctx.Context(), &request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(result)
+}
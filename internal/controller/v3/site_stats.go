@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+
+	"github.com/penguin-statistics/backend-next/internal/model/cache"
+	"github.com/penguin-statistics/backend-next/internal/pkg/cachectrl"
+	"github.com/penguin-statistics/backend-next/internal/server/svr"
+	"github.com/penguin-statistics/backend-next/internal/service"
+	"github.com/penguin-statistics/backend-next/internal/util/rekuest"
+)
+
+type SiteStatsController struct {
+	fx.In
+
+	SiteStatsService *service.SiteStats
+}
+
+func RegisterSiteStatsController(v3 *svr.V3, c SiteStatsController) {
+	v3.Get("/stats", c.GetSiteStats)
+}
+
+// @Summary      Get Expanded Site Stats
+// @Description  Daily series of report counts, sanity and unique contributing accounts, with per-zone and per-source breakdowns and the share of unreliable reports, over the last 90 game days. Recalculated periodically.
+// @Tags         SiteStats
+// @Produce      json
+// @Param        server  query     string  true  "Server; default to CN"  Enums(CN, US, JP, KR)
+// @Success      200     {object}  model.SiteStats
+// @Failure      500     {object}  pgerr.PenguinError  "An unexpected error occurred"
+// @Router       /api/v3/stats [GET]
+func (c *SiteStatsController) GetSiteStats(ctx *fiber.Ctx) error {
+	server := ctx.Query("server", "CN")
+	if err := rekuest.ValidServer(ctx, server); err != nil {
+		return err
+	}
+
+	lastModifiedKey := "[siteStats#server:" + server + "]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
+	siteStats, err := c.SiteStatsService.GetSiteStats(ctx.Context(), server)
+	if err != nil {
+		return err
+	}
+
+	return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, siteStats)
+}
@@ -4,6 +4,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/fx"
 
+	"github.com/penguin-statistics/backend-next/internal/model/cache"
+	"github.com/penguin-statistics/backend-next/internal/pkg/cachectrl"
 	"github.com/penguin-statistics/backend-next/internal/server/svr"
 	"github.com/penguin-statistics/backend-next/internal/service"
 )
@@ -20,12 +22,14 @@ func RegisterStageController(v3 *svr.V3, c StageController) {
 }
 
 func (c *StageController) GetStages(ctx *fiber.Ctx) error {
+	lastModifiedKey := "[stages]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
 	stages, err := c.StageService.GetStages(ctx.Context())
 	if err != nil {
 		return err
 	}
 
-	return ctx.JSON(stages)
+	return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, stages)
 }
 
 func (c *StageController) GetStageById(ctx *fiber.Ctx) error {
@@ -4,6 +4,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/fx"
 
+	"github.com/penguin-statistics/backend-next/internal/model/cache"
+	"github.com/penguin-statistics/backend-next/internal/pkg/cachectrl"
 	"github.com/penguin-statistics/backend-next/internal/server/svr"
 	"github.com/penguin-statistics/backend-next/internal/service"
 )
@@ -20,12 +22,14 @@ func RegisterZoneController(v3 *svr.V3, c ZoneController) {
 }
 
 func (c *ZoneController) GetZones(ctx *fiber.Ctx) error {
+	lastModifiedKey := "[zones]"
+	lastModifiedTime := cache.LastModified(lastModifiedKey)
 	zones, err := c.ZoneService.GetZones(ctx.Context())
 	if err != nil {
 		return err
 	}
 
-	return ctx.JSON(zones)
+	return cachectrl.Respond(ctx, lastModifiedKey, lastModifiedTime, zones)
 }
 
 func (c *ZoneController) GetZoneById(ctx *fiber.Ctx) error {
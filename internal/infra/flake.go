@@ -0,0 +1,56 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+	"go.uber.org/fx"
+
+	"github.com/penguin-statistics/backend-next/internal/config"
+	"github.com/penguin-statistics/backend-next/internal/constant"
+	"github.com/penguin-statistics/backend-next/internal/pkg/flake"
+)
+
+// FlakeNodeLease leases the snowflake node ID of this instance, either the one configured or any free one, and
+// keeps renewing it while running. The process exits if the lease is taken over by another instance, or is about to
+// expire because redis has been unreachable for too long, as IDs generated afterwards may collide with theirs.
+func FlakeNodeLease(conf *config.Config, client *redis.Client, lc fx.Lifecycle) (*flake.NodeLease, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	var lease *flake.NodeLease
+	var err error
+	if conf.SnowflakeNodeID >= 0 {
+		lease, err = flake.LeaseNode(ctx, client, constant.FlakeNodeKeyPrefix, InstanceID(), conf.SnowflakeNodeID, conf.SnowflakeNodeLeaseTTL)
+	} else {
+		lease, err = flake.LeaseAnyNode(ctx, client, constant.FlakeNodeKeyPrefix, InstanceID(), conf.SnowflakeNodeLeaseTTL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Int64("node", lease.Node()).Bool("configured", conf.SnowflakeNodeID >= 0).Msg("leased snowflake node ID")
+
+	runCtx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := lease.Run(runCtx); err != nil {
+			log.Fatal().Err(err).Int64("node", lease.Node()).Msg("lost snowflake node lease, exiting to avoid generating colliding IDs")
+		}
+	}()
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			// release so that a replacement instance may take the node ID over right away
+			stop()
+			select {
+			case <-done:
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	})
+
+	return lease, nil
+}
@@ -0,0 +1,16 @@
+package infra
+
+import (
+	"fmt"
+	"os"
+)
+
+// InstanceID identifies this instance among replicas, e.g. in leader election and cluster-wide cache purges. The pid
+// tells apart instances sharing a hostname, e.g. when running several locally.
+func InstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
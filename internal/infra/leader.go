@@ -0,0 +1,13 @@
+package infra
+
+import (
+	"github.com/go-redis/redis/v8"
+
+	"github.com/penguin-statistics/backend-next/internal/config"
+	"github.com/penguin-statistics/backend-next/internal/constant"
+	"github.com/penguin-statistics/backend-next/internal/pkg/leader"
+)
+
+func LeaderElector(conf *config.Config, client *redis.Client) *leader.Elector {
+	return leader.New(client, constant.WorkerLeaderKey, InstanceID(), conf.WorkerLeaseTTL)
+}
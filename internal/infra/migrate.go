@@ -0,0 +1,23 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/penguin-statistics/backend-next/internal/config"
+	"github.com/penguin-statistics/backend-next/internal/migrations"
+)
+
+// Migrate applies pending database migrations on startup when enabled by configuration.
+func Migrate(conf *config.Config, db *bun.DB) error {
+	if !conf.MigrateOnStartup {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
+	defer cancel()
+
+	return migrations.Up(ctx, db)
+}
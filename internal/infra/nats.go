@@ -7,6 +7,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/penguin-statistics/backend-next/internal/config"
+	"github.com/penguin-statistics/backend-next/internal/constant"
 )
 
 func NATS(conf *config.Config) (*nats.Conn, nats.JetStreamContext, error) {
@@ -21,7 +22,7 @@ func NATS(conf *config.Config) (*nats.Conn, nats.JetStreamContext, error) {
 	}
 
 	_, err = js.AddStream(&nats.StreamConfig{
-		Name: "penguin-reports",
+		Name: constant.ReportStreamName,
 		Subjects: []string{
 			"REPORT.*",
 		},
@@ -6,31 +6,87 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/rs/zerolog/log"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
 	"github.com/uptrace/bun/driver/pgdriver"
 	"github.com/uptrace/bun/extra/bundebug"
 	"github.com/uptrace/bun/extra/bunotel"
+	"go.uber.org/fx"
 
 	"github.com/penguin-statistics/backend-next/internal/config"
+	"github.com/penguin-statistics/backend-next/internal/pkg/observability"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgreplica"
 )
 
 func Postgres(conf *config.Config) (*bun.DB, error) {
+	db := openPostgres(conf, conf.PostgresDSN, pgreplica.PoolPrimary)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// PostgresReplica connects to the database replica if one is configured, and keeps checking its replication lag so
+// that read-only queries are routed to it only while it is usable. An unreachable replica does not prevent startup,
+// as reads fall back to the primary.
+func PostgresReplica(conf *config.Config, db *bun.DB, lc fx.Lifecycle) *pgreplica.Router {
+	if conf.PostgresReplicaDSN == "" {
+		return pgreplica.New(db, nil, conf.PostgresReplicaMaxLag)
+	}
+
+	replica := openPostgres(conf, conf.PostgresReplicaDSN, pgreplica.PoolReplica)
+	router := pgreplica.New(db, replica, conf.PostgresReplicaMaxLag)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	// route reads to the replica right away if usable, rather than after the first periodic check
+	_ = router.Check(ctx)
+
+	runCtx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go func() {
+				defer close(done)
+				router.Run(runCtx, conf.PostgresReplicaLagCheckInterval)
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			stop()
+			select {
+			case <-done:
+			case <-ctx.Done():
+			}
+			if err := replica.Close(); err != nil {
+				log.Warn().Err(err).Msg("failed to close database replica connections")
+			}
+			return nil
+		},
+	})
+
+	return router
+}
+
+func openPostgres(conf *config.Config, dsn string, pool string) *bun.DB {
 	// Open a Postgres database.
-	pgdb := sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(conf.PostgresDSN), pgdriver.WithApplicationName("penguin-backend")))
+	pgdb := sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(dsn), pgdriver.WithApplicationName("penguin-backend")))
 
 	// Create a Bun db on top of it.
 	db := bun.NewDB(pgdb, pgdialect.New())
+	db.AddQueryHook(observability.NewQueryHook(pool))
 	if conf.DevMode {
 		db.AddQueryHook(bundebug.NewQueryHook(bundebug.WithEnabled(true), bundebug.WithVerbose(conf.BunDebugVerbose)))
-		db.AddQueryHook(bunotel.NewQueryHook(bunotel.WithDBName("penguin_structured")))
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-	defer cancel()
-
-	if err := db.PingContext(ctx); err != nil {
-		return nil, err
+	if conf.TracingEnabled {
+		// added after the observability hook, so query spans are children of the repo call spans
+		db.AddQueryHook(bunotel.NewQueryHook(bunotel.WithDBName("penguin_structured")))
 	}
 
 	pgdb.SetMaxOpenConns(runtime.NumCPU() * 2)
@@ -38,5 +94,5 @@ func Postgres(conf *config.Config) (*bun.DB, error) {
 	pgdb.SetConnMaxLifetime(time.Minute * 5)
 	pgdb.SetConnMaxIdleTime(time.Minute * 5)
 
-	return db, nil
+	return db
 }
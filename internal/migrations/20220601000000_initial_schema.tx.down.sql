@@ -0,0 +1,7 @@
+-- The initial schema holds the data of the service, and may have been applied to a database created before
+-- migrations were introduced, so it is never rolled back.
+DO $$
+BEGIN
+	RAISE EXCEPTION 'the initial schema migration cannot be rolled back';
+END
+$$;
@@ -0,0 +1,198 @@
+-- Initial schema, matching the models in internal/model. Every statement is guarded with IF NOT EXISTS so that
+-- applying it to a database created before migrations were introduced only records it as applied.
+
+CREATE TABLE IF NOT EXISTS accounts (
+	account_id serial PRIMARY KEY,
+	penguin_id text NOT NULL,
+	weight double precision NOT NULL DEFAULT 0,
+	created_at timestamptz NOT NULL DEFAULT current_timestamp
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS accounts_penguin_id_idx ON accounts (penguin_id);
+
+CREATE TABLE IF NOT EXISTS activities (
+	activity_id serial PRIMARY KEY,
+	start_time timestamptz,
+	end_time timestamptz,
+	name jsonb,
+	existence jsonb
+);
+
+CREATE TABLE IF NOT EXISTS items (
+	item_id serial PRIMARY KEY,
+	ark_item_id text NOT NULL,
+	name jsonb,
+	existence jsonb,
+	sort_id integer NOT NULL DEFAULT 0,
+	rarity integer NOT NULL DEFAULT 0,
+	"group" text,
+	sprite text,
+	keywords jsonb,
+	type text
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS items_ark_item_id_idx ON items (ark_item_id);
+
+CREATE TABLE IF NOT EXISTS zones (
+	zone_id serial PRIMARY KEY,
+	ark_zone_id text NOT NULL,
+	index integer NOT NULL DEFAULT 0,
+	category text NOT NULL,
+	type text,
+	name jsonb,
+	existence jsonb,
+	background text
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS zones_ark_zone_id_idx ON zones (ark_zone_id);
+
+CREATE TABLE IF NOT EXISTS stages (
+	stage_id serial PRIMARY KEY,
+	ark_stage_id text NOT NULL,
+	zone_id integer NOT NULL REFERENCES zones (zone_id),
+	stage_type text NOT NULL,
+	extra_process_type text,
+	code jsonb,
+	sanity integer,
+	existence jsonb,
+	min_clear_time integer
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS stages_ark_stage_id_idx ON stages (ark_stage_id);
+CREATE INDEX IF NOT EXISTS stages_zone_id_idx ON stages (zone_id);
+
+CREATE TABLE IF NOT EXISTS time_ranges (
+	range_id serial PRIMARY KEY,
+	name text,
+	start_time timestamptz NOT NULL,
+	end_time timestamptz NOT NULL,
+	comment text,
+	server text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS time_ranges_server_idx ON time_ranges (server, start_time);
+
+CREATE TABLE IF NOT EXISTS drop_infos (
+	drop_id serial PRIMARY KEY,
+	server text NOT NULL,
+	stage_id integer NOT NULL REFERENCES stages (stage_id),
+	item_id integer REFERENCES items (item_id),
+	drop_type text NOT NULL,
+	range_id integer NOT NULL REFERENCES time_ranges (range_id),
+	accumulable boolean NOT NULL DEFAULT true,
+	bounds jsonb,
+	extras jsonb
+);
+
+CREATE INDEX IF NOT EXISTS drop_infos_server_stage_id_idx ON drop_infos (server, stage_id);
+CREATE INDEX IF NOT EXISTS drop_infos_range_id_idx ON drop_infos (range_id);
+
+CREATE TABLE IF NOT EXISTS drop_patterns (
+	pattern_id serial PRIMARY KEY,
+	hash text NOT NULL,
+	original_fingerprint text NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS drop_patterns_hash_idx ON drop_patterns (hash);
+
+CREATE TABLE IF NOT EXISTS drop_pattern_elements (
+	element_id serial PRIMARY KEY,
+	drop_pattern_id integer NOT NULL REFERENCES drop_patterns (pattern_id),
+	item_id integer NOT NULL REFERENCES items (item_id),
+	quantity integer NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS drop_pattern_elements_drop_pattern_id_idx ON drop_pattern_elements (drop_pattern_id);
+
+CREATE TABLE IF NOT EXISTS drop_reports (
+	report_id bigserial PRIMARY KEY,
+	stage_id integer NOT NULL,
+	pattern_id integer NOT NULL,
+	times integer NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT current_timestamp,
+	reliability integer NOT NULL DEFAULT 0,
+	server text NOT NULL,
+	account_id integer NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS drop_reports_server_stage_id_created_at_idx ON drop_reports (server, stage_id, created_at);
+CREATE INDEX IF NOT EXISTS drop_reports_created_at_idx ON drop_reports (created_at);
+CREATE INDEX IF NOT EXISTS drop_reports_account_id_idx ON drop_reports (account_id);
+CREATE INDEX IF NOT EXISTS drop_reports_pattern_id_idx ON drop_reports (pattern_id);
+
+CREATE TABLE IF NOT EXISTS drop_report_extras (
+	report_id bigint PRIMARY KEY REFERENCES drop_reports (report_id) ON DELETE CASCADE,
+	ip text,
+	source_name text,
+	version text,
+	metadata jsonb,
+	md5 text
+);
+
+CREATE INDEX IF NOT EXISTS drop_report_extras_md5_idx ON drop_report_extras (md5) WHERE md5 IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS drop_matrix_elements (
+	element_id serial PRIMARY KEY,
+	stage_id integer NOT NULL,
+	item_id integer NOT NULL,
+	range_id integer NOT NULL,
+	quantity integer NOT NULL,
+	times integer NOT NULL,
+	quantity_buckets jsonb,
+	server text NOT NULL,
+	source_category text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS drop_matrix_elements_server_source_category_idx ON drop_matrix_elements (server, source_category);
+
+CREATE TABLE IF NOT EXISTS pattern_matrix_elements (
+	element_id serial PRIMARY KEY,
+	stage_id integer NOT NULL,
+	pattern_id integer NOT NULL,
+	range_id integer NOT NULL,
+	quantity integer NOT NULL,
+	times integer NOT NULL,
+	server text NOT NULL,
+	source_category text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS pattern_matrix_elements_server_source_category_idx ON pattern_matrix_elements (server, source_category);
+
+CREATE TABLE IF NOT EXISTS trend_elements (
+	element_id serial PRIMARY KEY,
+	stage_id integer NOT NULL,
+	item_id integer NOT NULL,
+	group_id integer NOT NULL,
+	start_time timestamptz NOT NULL,
+	end_time timestamptz NOT NULL,
+	quantity integer NOT NULL,
+	times integer NOT NULL,
+	server text NOT NULL,
+	source_category text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS trend_elements_server_source_category_idx ON trend_elements (server, source_category);
+
+CREATE TABLE IF NOT EXISTS notices (
+	notice_id serial PRIMARY KEY,
+	existence jsonb,
+	severity integer,
+	content jsonb
+);
+
+CREATE TABLE IF NOT EXISTS properties (
+	property_id serial PRIMARY KEY,
+	key text NOT NULL,
+	value text NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS properties_key_idx ON properties (key);
+
+CREATE TABLE IF NOT EXISTS reject_rules (
+	rule_id serial PRIMARY KEY,
+	created_at timestamptz DEFAULT current_timestamp,
+	updated_at timestamptz DEFAULT current_timestamp,
+	status integer NOT NULL DEFAULT 0,
+	expr text NOT NULL,
+	with_reliability integer NOT NULL DEFAULT 0
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS formula_revisions;
+DROP TABLE IF EXISTS formulas;
@@ -0,0 +1,19 @@
+-- Formulas managed through the admin API, and the revisions kept every time they are modified.
+
+CREATE TABLE IF NOT EXISTS formulas (
+	formula_id serial PRIMARY KEY,
+	ark_item_id text NOT NULL,
+	gold_cost integer NOT NULL DEFAULT 0,
+	costs jsonb,
+	extra_outcomes jsonb,
+	existence jsonb
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS formulas_ark_item_id_idx ON formulas (ark_item_id);
+
+CREATE TABLE IF NOT EXISTS formula_revisions (
+	revision_id serial PRIMARY KEY,
+	comment text,
+	formulas jsonb,
+	created_at timestamptz NOT NULL DEFAULT current_timestamp
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS drop_matrix_snapshots;
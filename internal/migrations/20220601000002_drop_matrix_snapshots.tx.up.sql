@@ -0,0 +1,13 @@
+-- Snapshots of the drop matrix recorded when it is refreshed, to compare drop rates over time.
+
+CREATE TABLE IF NOT EXISTS drop_matrix_snapshots (
+	snapshot_id serial PRIMARY KEY,
+	server text NOT NULL,
+	source_category text NOT NULL,
+	element_count integer NOT NULL,
+	hash text,
+	elements jsonb,
+	created_at timestamptz NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX IF NOT EXISTS drop_matrix_snapshots_server_source_category_created_at_idx ON drop_matrix_snapshots (server, source_category, created_at);
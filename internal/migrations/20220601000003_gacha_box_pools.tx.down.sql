@@ -0,0 +1 @@
+DROP TABLE IF EXISTS gacha_box_pools;
@@ -0,0 +1,12 @@
+-- Item pools of gacha boxes, such as the ones of event shops.
+
+CREATE TABLE IF NOT EXISTS gacha_box_pools (
+	pool_id serial PRIMARY KEY,
+	stage_id integer NOT NULL REFERENCES stages (stage_id),
+	items jsonb,
+	draw_cost integer NOT NULL DEFAULT 0,
+	cost_item_id text,
+	updated_at timestamptz NOT NULL DEFAULT current_timestamp
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS gacha_box_pools_stage_id_idx ON gacha_box_pools (stage_id);
@@ -0,0 +1,81 @@
+// Package migrations holds the versioned SQL migrations of the database schema, embedded into the binary.
+//
+// Migrations are named YYYYMMDDHHMMSS_name.up.sql and YYYYMMDDHHMMSS_name.down.sql, and are run in a transaction when
+// named .tx.up.sql and .tx.down.sql instead. Use `backend migrate create <name>` to add one.
+package migrations
+
+import (
+	"context"
+	"embed"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+// advisoryLockKey identifies the PostgreSQL advisory lock held while migrating on startup, so that instances
+// starting at the same time migrate one after another rather than failing on bun's migration lock.
+const advisoryLockKey = 0x70656e67 // "peng"
+
+var (
+	//go:embed *.up.sql *.down.sql
+	sqlMigrations embed.FS
+
+	//go:embed seed.sql
+	seedSQL string
+)
+
+// Migrations are the migrations embedded into the binary.
+var Migrations = migrate.NewMigrations()
+
+func init() {
+	if err := Migrations.Discover(sqlMigrations); err != nil {
+		panic(err)
+	}
+}
+
+func NewMigrator(db *bun.DB) *migrate.Migrator {
+	return migrate.NewMigrator(db, Migrations)
+}
+
+// Up applies every migration not applied yet, waiting for other instances migrating at the same time to finish.
+func Up(ctx context.Context, db *bun.DB) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(?)", advisoryLockKey); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(?)", advisoryLockKey); err != nil {
+			log.Warn().Err(err).Msg("failed to release migration lock")
+		}
+	}()
+
+	migrator := NewMigrator(db)
+	if err := migrator.Init(ctx); err != nil {
+		return err
+	}
+	group, err := migrator.Migrate(ctx)
+	if err != nil {
+		return err
+	}
+	if group.IsZero() {
+		log.Info().Msg("database schema is up to date")
+		return nil
+	}
+	log.Info().Str("group", group.String()).Msg("database schema migrated")
+	return nil
+}
+
+// Seed loads sample zones, stages, items, time ranges and drop infos for development. Rows already present are
+// left untouched.
+func Seed(ctx context.Context, db *bun.DB) error {
+	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.ExecContext(ctx, seedSQL)
+		return err
+	})
+}
@@ -0,0 +1,50 @@
+-- Sample data for development: a couple of zones and stages with their drops on the CN and US servers. Rows
+-- already present are left untouched, so seeding may be run again safely.
+
+INSERT INTO zones (zone_id, ark_zone_id, index, category, type, name, existence, background) VALUES
+	(1, 'main_0', 0, 'MAINLINE', 'AWAKENING_HOUR', '{"zh": "序章", "en": "Prologue", "ja": "序章", "ko": "프롤로그"}', '{"CN": {"exist": true}, "US": {"exist": true}, "JP": {"exist": true}, "KR": {"exist": true}}', '/backgrounds/zones/main_0.jpg'),
+	(2, 'main_1', 1, 'MAINLINE', 'AWAKENING_HOUR', '{"zh": "黑暗时代·上", "en": "Evil Time Part 1", "ja": "暗黒時代・上", "ko": "암흑시대 상"}', '{"CN": {"exist": true}, "US": {"exist": true}, "JP": {"exist": true}, "KR": {"exist": true}}', '/backgrounds/zones/main_1.jpg')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO items (item_id, ark_item_id, name, existence, sort_id, rarity, "group", sprite, keywords, type) VALUES
+	(1, '30011', '{"zh": "源岩", "en": "Orirock", "ja": "源岩", "ko": "원암"}', '{"CN": {"exist": true}, "US": {"exist": true}, "JP": {"exist": true}, "KR": {"exist": true}}', 10003, 0, 'orirock', '0:1', '{"alias": {"zh": ["源岩"]}, "pron": {"zh": ["yuan`yan"]}}', 'MATERIAL'),
+	(2, '30012', '{"zh": "固源岩", "en": "Orirock Cube", "ja": "初級源岩", "ko": "원암 큐브"}', '{"CN": {"exist": true}, "US": {"exist": true}, "JP": {"exist": true}, "KR": {"exist": true}}', 10004, 1, 'orirock', '1:1', '{"alias": {"zh": ["固源岩"]}, "pron": {"zh": ["gu`yuan`yan"]}}', 'MATERIAL'),
+	(3, '30061', '{"zh": "破损装置", "en": "Damaged Device", "ja": "破損装置", "ko": "파손된 장치"}', '{"CN": {"exist": true}, "US": {"exist": true}, "JP": {"exist": true}, "KR": {"exist": true}}', 10019, 0, 'device', '0:6', '{"alias": {"zh": ["破损装置"]}, "pron": {"zh": ["po`sun`zhuang`zhi"]}}', 'MATERIAL'),
+	(4, '30062', '{"zh": "装置", "en": "Device", "ja": "装置", "ko": "장치"}', '{"CN": {"exist": true}, "US": {"exist": true}, "JP": {"exist": true}, "KR": {"exist": true}}', 10020, 1, 'device', '1:6', '{"alias": {"zh": ["装置"]}, "pron": {"zh": ["zhuang`zhi"]}}', 'MATERIAL'),
+	(5, 'furni', '{"zh": "家具", "en": "Furniture", "ja": "家具", "ko": "가구"}', '{"CN": {"exist": true}, "US": {"exist": true}, "JP": {"exist": true}, "KR": {"exist": true}}', 99999, 2, NULL, NULL, '{}', 'FURN')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO stages (stage_id, ark_stage_id, zone_id, stage_type, extra_process_type, code, sanity, existence, min_clear_time) VALUES
+	(1, 'main_00-01', 1, 'MAIN', NULL, '{"zh": "0-1", "en": "0-1", "ja": "0-1", "ko": "0-1"}', 6, '{"CN": {"exist": true}, "US": {"exist": true}, "JP": {"exist": true}, "KR": {"exist": true}}', 60000),
+	(2, 'main_01-07', 2, 'MAIN', NULL, '{"zh": "1-7", "en": "1-7", "ja": "1-7", "ko": "1-7"}', 6, '{"CN": {"exist": true}, "US": {"exist": true}, "JP": {"exist": true}, "KR": {"exist": true}}', 84000)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO time_ranges (range_id, name, start_time, end_time, comment, server) VALUES
+	(1, NULL, to_timestamp(1556676000), to_timestamp(62141368179), 'CN server launch onwards', 'CN'),
+	(2, NULL, to_timestamp(1579190400), to_timestamp(62141368179), 'US server launch onwards', 'US')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO drop_infos (drop_id, server, stage_id, item_id, drop_type, range_id, accumulable, bounds, extras) VALUES
+	(1, 'CN', 1, NULL, 'REGULAR', 1, true, '{"lower": 0, "upper": 1}', NULL),
+	(2, 'CN', 1, 3, 'REGULAR', 1, true, '{"lower": 0, "upper": 1}', NULL),
+	(3, 'CN', 1, NULL, 'FURNITURE', 1, true, '{"lower": 0, "upper": 1}', NULL),
+	(4, 'CN', 1, 5, 'FURNITURE', 1, true, '{"lower": 0, "upper": 1}', NULL),
+	(5, 'CN', 2, NULL, 'REGULAR', 1, true, '{"lower": 1, "upper": 1}', NULL),
+	(6, 'CN', 2, 2, 'REGULAR', 1, true, '{"lower": 1, "upper": 1}', NULL),
+	(7, 'CN', 2, NULL, 'EXTRA', 1, true, '{"lower": 0, "upper": 2}', NULL),
+	(8, 'CN', 2, 1, 'EXTRA', 1, true, '{"lower": 0, "upper": 2}', NULL),
+	(9, 'CN', 2, 3, 'EXTRA', 1, true, '{"lower": 0, "upper": 1}', NULL),
+	(10, 'US', 1, NULL, 'REGULAR', 2, true, '{"lower": 0, "upper": 1}', NULL),
+	(11, 'US', 1, 3, 'REGULAR', 2, true, '{"lower": 0, "upper": 1}', NULL),
+	(12, 'US', 2, NULL, 'REGULAR', 2, true, '{"lower": 1, "upper": 1}', NULL),
+	(13, 'US', 2, 2, 'REGULAR', 2, true, '{"lower": 1, "upper": 1}', NULL),
+	(14, 'US', 2, NULL, 'EXTRA', 2, true, '{"lower": 0, "upper": 2}', NULL),
+	(15, 'US', 2, 1, 'EXTRA', 2, true, '{"lower": 0, "upper": 2}', NULL)
+ON CONFLICT DO NOTHING;
+
+-- the rows above are inserted with explicit IDs, so move the sequences past them
+SELECT setval(pg_get_serial_sequence('zones', 'zone_id'), (SELECT max(zone_id) FROM zones));
+SELECT setval(pg_get_serial_sequence('items', 'item_id'), (SELECT max(item_id) FROM items));
+SELECT setval(pg_get_serial_sequence('stages', 'stage_id'), (SELECT max(stage_id) FROM stages));
+SELECT setval(pg_get_serial_sequence('time_ranges', 'range_id'), (SELECT max(range_id) FROM time_ranges));
+SELECT setval(pg_get_serial_sequence('drop_infos', 'drop_id'), (SELECT max(drop_id) FROM drop_infos));
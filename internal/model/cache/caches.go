@@ -2,12 +2,14 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
 	"sync"
 	"time"
 
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
 	"gopkg.in/guregu/null.v3"
 
+	"github.com/penguin-statistics/backend-next/internal/config"
 	"github.com/penguin-statistics/backend-next/internal/model"
 	modelv2 "github.com/penguin-statistics/backend-next/internal/model/v2"
 	"github.com/penguin-statistics/backend-next/internal/pkg/cache"
@@ -16,6 +18,12 @@ import (
 
 type Flusher func() error
 
+// SetDeleter deletes values from a cache set, either by key or all at once.
+type SetDeleter struct {
+	Delete func(key string) error
+	Flush  Flusher
+}
+
 var (
 	AccountByID        *cache.Set[model.Account]
 	AccountByPenguinID *cache.Set[model.Account]
@@ -23,9 +31,17 @@ var (
 	ItemDropSetByStageIDAndRangeID   *cache.Set[[]int]
 	ItemDropSetByStageIdAndTimeRange *cache.Set[[]int]
 
-	ShimMaxAccumulableDropMatrixResults *cache.Set[modelv2.DropMatrixQueryResult]
+	ShimMaxAccumulableDropMatrixResults   *cache.Set[modelv2.DropMatrixQueryResult]
+	ShrunkMaxAccumulableDropMatrixResults *cache.Set[modelv2.DropMatrixQueryResult]
+	ItemDrops                             *cache.Set[model.ItemDrops]
+
+	GachaBoxPools        *cache.Singular[[]*model.GachaBoxPool]
+	GachaBoxDistribution *cache.Set[model.GachaBoxDistribution]
 
-	Formula *cache.Singular[json.RawMessage]
+	FurnitureDrops *cache.Set[model.FurnitureDrops]
+
+	Formulas    *cache.Singular[[]*model.Formula]
+	ShimFormula *cache.Singular[[]*modelv2.Formula]
 
 	Items           *cache.Singular[[]*model.Item]
 	ItemByArkID     *cache.Set[model.Item]
@@ -42,6 +58,7 @@ var (
 	ShimLatestPatternMatrixResults *cache.Set[modelv2.PatternMatrixQueryResult]
 
 	ShimSiteStats *cache.Set[modelv2.SiteStats]
+	SiteStats     *cache.Set[model.SiteStats]
 
 	Stages           *cache.Singular[[]*model.Stage]
 	StageByArkID     *cache.Set[model.Stage]
@@ -70,64 +87,116 @@ var (
 
 	once sync.Once
 
-	SetMap             map[string]Flusher
+	SetMap             map[string]SetDeleter
 	SingularFlusherMap map[string]Flusher
 )
 
-func Initialize(propertyRepo *repo.Property) {
+func Initialize(conf *config.Config, client *redis.Client, propertyRepo *repo.Property) {
 	once.Do(func() {
+		if conf.CacheRemoteEnabled {
+			cache.EnableRemote(client, conf.CacheRemoteLockTTL)
+		}
 		initializeCaches()
 		populateProperties(propertyRepo)
 	})
 }
 
+// Delete deletes the value of key from the cache set of the given name, or if key is not given, flushes the whole
+// cache set or deletes the singular cache of the given name.
 func Delete(name string, key null.String) error {
-	if key.Valid {
-		if _, ok := SetMap[name]; ok {
-			if err := SetMap[name](); err != nil {
-				return err
-			}
+	if set, ok := SetMap[name]; ok {
+		if key.Valid {
+			return set.Delete(key.String)
 		}
-	} else {
-		if _, ok := SingularFlusherMap[name]; ok {
-			if err := SingularFlusherMap[name](); err != nil {
-				return err
-			}
-		} else if _, ok := SetMap[name]; ok {
-			if err := SetMap[name](); err != nil {
-				return err
-			}
+		return set.Flush()
+	}
+	if flush, ok := SingularFlusherMap[name]; ok {
+		if key.Valid {
+			return errors.Errorf("cache %s is a singular cache and has no keys", name)
 		}
+		return flush()
+	}
+	return errors.Errorf("cache %s does not exist", name)
+}
+
+// LastModified returns the time the cached value under key was last modified, or the zero time if it is unknown.
+// Values are stored in the cache before their last modified time is updated, so the time must be read before the
+// value: the value read afterwards is then at least as recent as the time, and a stale value is never memoized as
+// the representation of a newer one.
+func LastModified(key string) time.Time {
+	var lastModified time.Time
+	if err := LastModifiedTime.Get(key, &lastModified); err != nil {
+		return time.Time{}
+	}
+	return lastModified
+}
+
+// recordLastModified returns a callback recording the last modified time of values of the named set, for sets whose
+// values are computed in the background, where the caller does not get to know when a new value is stored.
+func recordLastModified(name string) func(key string) {
+	return func(key string) {
+		LastModifiedTime.Set("["+name+":"+key+"]", time.Now(), 0)
+	}
+}
+
+func registerSet[T any](name string, set *cache.Set[T]) {
+	SetMap[name] = SetDeleter{
+		Delete: set.Delete,
+		Flush:  set.Flush,
 	}
-	return nil
+}
+
+func registerSingular[T any](name string, singular *cache.Singular[T]) {
+	SingularFlusherMap[name] = singular.Delete
 }
 
 func initializeCaches() {
-	SetMap = make(map[string]Flusher)
+	SetMap = make(map[string]SetDeleter)
 	SingularFlusherMap = make(map[string]Flusher)
 
 	// account
 	AccountByID = cache.NewSet[model.Account]("account#accountId")
 	AccountByPenguinID = cache.NewSet[model.Account]("account#penguinId")
 
-	SetMap["account#accountId"] = AccountByID.Flush
-	SetMap["account#penguinId"] = AccountByPenguinID.Flush
+	registerSet("account#accountId", AccountByID)
+	registerSet("account#penguinId", AccountByPenguinID)
 
 	// drop_info
 	ItemDropSetByStageIDAndRangeID = cache.NewSet[[]int]("itemDropSet#server|stageId|rangeId")
 	ItemDropSetByStageIdAndTimeRange = cache.NewSet[[]int]("itemDropSet#server|stageId|startTime|endTime")
 
-	SetMap["itemDropSet#server|stageId|rangeId"] = ItemDropSetByStageIDAndRangeID.Flush
-	SetMap["itemDropSet#server|stageId|startTime|endTime"] = ItemDropSetByStageIdAndTimeRange.Flush
+	registerSet("itemDropSet#server|stageId|rangeId", ItemDropSetByStageIDAndRangeID)
+	registerSet("itemDropSet#server|stageId|startTime|endTime", ItemDropSetByStageIdAndTimeRange)
 
 	// drop_matrix
 	ShimMaxAccumulableDropMatrixResults = cache.NewSet[modelv2.DropMatrixQueryResult]("shimMaxAccumulableDropMatrixResults#server|showClosedZoned")
+	ShrunkMaxAccumulableDropMatrixResults = cache.NewSet[modelv2.DropMatrixQueryResult]("shrunkMaxAccumulableDropMatrixResults#server|showClosedZoned")
+	ItemDrops = cache.NewSet[model.ItemDrops]("itemDrops#server|arkItemId")
 
-	SetMap["shimMaxAccumulableDropMatrixResults#server|showClosedZoned"] = ShimMaxAccumulableDropMatrixResults.Flush
+	registerSet("shimMaxAccumulableDropMatrixResults#server|showClosedZoned", ShimMaxAccumulableDropMatrixResults)
+	ShimMaxAccumulableDropMatrixResults.OnSet(recordLastModified("shimMaxAccumulableDropMatrixResults#server|showClosedZoned"))
+	registerSet("shrunkMaxAccumulableDropMatrixResults#server|showClosedZoned", ShrunkMaxAccumulableDropMatrixResults)
+	ShrunkMaxAccumulableDropMatrixResults.OnSet(recordLastModified("shrunkMaxAccumulableDropMatrixResults#server|showClosedZoned"))
+	registerSet("itemDrops#server|arkItemId", ItemDrops)
+
+	// gacha_box
+	GachaBoxPools = cache.NewSingular[[]*model.GachaBoxPool]("gachaBoxPools")
+	GachaBoxDistribution = cache.NewSet[model.GachaBoxDistribution]("gachaBoxDistribution#server|arkStageId")
+
+	registerSingular("gachaBoxPools", GachaBoxPools)
+	registerSet("gachaBoxDistribution#server|arkStageId", GachaBoxDistribution)
+
+	// furniture
+	FurnitureDrops = cache.NewSet[model.FurnitureDrops]("furnitureDrops#server")
+
+	registerSet("furnitureDrops#server", FurnitureDrops)
 
 	// formula
-	Formula = cache.NewSingular[json.RawMessage]("formula")
-	SingularFlusherMap["formula"] = Formula.Delete
+	Formulas = cache.NewSingular[[]*model.Formula]("formulas")
+	ShimFormula = cache.NewSingular[[]*modelv2.Formula]("shimFormula")
+
+	registerSingular("formulas", Formulas)
+	registerSingular("shimFormula", ShimFormula)
 
 	// item
 	Items = cache.NewSingular[[]*model.Item]("items")
@@ -137,34 +206,37 @@ func initializeCaches() {
 	ItemsMapById = cache.NewSingular[map[int]*model.Item]("itemsMapById")
 	ItemsMapByArkID = cache.NewSingular[map[string]*model.Item]("itemsMapByArkId")
 
-	SingularFlusherMap["items"] = Items.Delete
-	SetMap["item#arkItemId"] = ItemByArkID.Flush
-	SingularFlusherMap["shimItems"] = ShimItems.Delete
-	SetMap["shimItem#arkItemId"] = ShimItemByArkID.Flush
-	SingularFlusherMap["itemsMapById"] = ItemsMapById.Delete
-	SingularFlusherMap["itemsMapByArkId"] = ItemsMapByArkID.Delete
+	registerSingular("items", Items)
+	registerSet("item#arkItemId", ItemByArkID)
+	registerSingular("shimItems", ShimItems)
+	registerSet("shimItem#arkItemId", ShimItemByArkID)
+	registerSingular("itemsMapById", ItemsMapById)
+	registerSingular("itemsMapByArkId", ItemsMapByArkID)
 
 	// notice
 	Notices = cache.NewSingular[[]*model.Notice]("notices")
 
-	SingularFlusherMap["notices"] = Notices.Delete
+	registerSingular("notices", Notices)
 
 	// activity
 	Activities = cache.NewSingular[[]*model.Activity]("activities")
 	ShimActivities = cache.NewSingular[[]*modelv2.Activity]("shimActivities")
 
-	SingularFlusherMap["activities"] = Activities.Delete
-	SingularFlusherMap["shimActivities"] = ShimActivities.Delete
+	registerSingular("activities", Activities)
+	registerSingular("shimActivities", ShimActivities)
 
 	// pattern_matrix
 	ShimLatestPatternMatrixResults = cache.NewSet[modelv2.PatternMatrixQueryResult]("shimLatestPatternMatrixResults#server")
 
-	SetMap["shimLatestPatternMatrixResults#server"] = ShimLatestPatternMatrixResults.Flush
+	registerSet("shimLatestPatternMatrixResults#server", ShimLatestPatternMatrixResults)
+	ShimLatestPatternMatrixResults.OnSet(recordLastModified("shimLatestPatternMatrixResults#server"))
 
 	// site_stats
 	ShimSiteStats = cache.NewSet[modelv2.SiteStats]("shimSiteStats#server")
+	SiteStats = cache.NewSet[model.SiteStats]("siteStats#server")
 
-	SetMap["shimSiteStats#server"] = ShimSiteStats.Flush
+	registerSet("shimSiteStats#server", ShimSiteStats)
+	registerSet("siteStats#server", SiteStats)
 
 	// stage
 	Stages = cache.NewSingular[[]*model.Stage]("stages")
@@ -174,12 +246,12 @@ func initializeCaches() {
 	StagesMapByID = cache.NewSingular[map[int]*model.Stage]("stagesMapById")
 	StagesMapByArkID = cache.NewSingular[map[string]*model.Stage]("stagesMapByArkId")
 
-	SingularFlusherMap["stages"] = Stages.Delete
-	SetMap["stage#arkStageId"] = StageByArkID.Flush
-	SetMap["shimStages#server"] = ShimStages.Flush
-	SetMap["shimStage#server|arkStageId"] = ShimStageByArkID.Flush
-	SingularFlusherMap["stagesMapById"] = StagesMapByID.Delete
-	SingularFlusherMap["stagesMapByArkId"] = StagesMapByArkID.Delete
+	registerSingular("stages", Stages)
+	registerSet("stage#arkStageId", StageByArkID)
+	registerSet("shimStages#server", ShimStages)
+	registerSet("shimStage#server|arkStageId", ShimStageByArkID)
+	registerSingular("stagesMapById", StagesMapByID)
+	registerSingular("stagesMapByArkId", StagesMapByArkID)
 
 	// time_range
 	TimeRanges = cache.NewSet[[]*model.TimeRange]("timeRanges#server")
@@ -187,15 +259,16 @@ func initializeCaches() {
 	TimeRangesMap = cache.NewSet[map[int]*model.TimeRange]("timeRangesMap#server")
 	MaxAccumulableTimeRanges = cache.NewSet[map[int]map[int][]*model.TimeRange]("maxAccumulableTimeRanges#server")
 
-	SetMap["timeRanges#server"] = TimeRanges.Flush
-	SetMap["timeRange#rangeId"] = TimeRangeByID.Flush
-	SetMap["timeRangesMap#server"] = TimeRangesMap.Flush
-	SetMap["maxAccumulableTimeRanges#server"] = MaxAccumulableTimeRanges.Flush
+	registerSet("timeRanges#server", TimeRanges)
+	registerSet("timeRange#rangeId", TimeRangeByID)
+	registerSet("timeRangesMap#server", TimeRangesMap)
+	registerSet("maxAccumulableTimeRanges#server", MaxAccumulableTimeRanges)
 
 	// trend
 	ShimSavedTrendResults = cache.NewSet[modelv2.TrendQueryResult]("shimSavedTrendResults#server")
 
-	SetMap["shimSavedTrendResults#server"] = ShimSavedTrendResults.Flush
+	registerSet("shimSavedTrendResults#server", ShimSavedTrendResults)
+	ShimSavedTrendResults.OnSet(recordLastModified("shimSavedTrendResults#server"))
 
 	// zone
 	Zones = cache.NewSingular[[]*model.Zone]("zones")
@@ -203,20 +276,20 @@ func initializeCaches() {
 	ShimZones = cache.NewSingular[[]*modelv2.Zone]("shimZones")
 	ShimZoneByArkID = cache.NewSet[modelv2.Zone]("shimZone#arkZoneId")
 
-	SingularFlusherMap["zones"] = Zones.Delete
-	SetMap["zone#arkZoneId"] = ZoneByArkID.Flush
-	SingularFlusherMap["shimZones"] = ShimZones.Delete
-	SetMap["shimZone#arkZoneId"] = ShimZoneByArkID.Flush
+	registerSingular("zones", Zones)
+	registerSet("zone#arkZoneId", ZoneByArkID)
+	registerSingular("shimZones", ShimZones)
+	registerSet("shimZone#arkZoneId", ShimZoneByArkID)
 
 	// drop_pattern_elements
 	DropPatternElementsByPatternID = cache.NewSet[[]*model.DropPatternElement]("dropPatternElements#patternId")
 
-	SetMap["dropPatternElements#patternId"] = DropPatternElementsByPatternID.Flush
+	registerSet("dropPatternElements#patternId", DropPatternElementsByPatternID)
 
 	// others
 	LastModifiedTime = cache.NewSet[time.Time]("lastModifiedTime#key")
 
-	SetMap["lastModifiedTime#key"] = LastModifiedTime.Flush
+	registerSet("lastModifiedTime#key", LastModifiedTime)
 }
 
 func populateProperties(repo *repo.Property) {
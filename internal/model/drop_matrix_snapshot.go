@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+	"gopkg.in/guregu/null.v3"
+)
+
+// DropMatrixSnapshot is a compact copy of all drop matrix elements of a server and source category,
+// recorded every time the drop matrix elements are refreshed.
+type DropMatrixSnapshot struct {
+	bun.BaseModel `bun:"drop_matrix_snapshots,alias:dms"`
+
+	SnapshotID     int    `bun:",pk,autoincrement" json:"id"`
+	Server         string `json:"server"`
+	SourceCategory string `json:"sourceCategory"`
+	ElementCount   int    `json:"elementCount"`
+	// Hash is the SHA-256 of the elements, used to skip recording snapshots identical to the previous one
+	Hash      string                       `bun:",nullzero" json:"-"`
+	Elements  []*DropMatrixSnapshotElement `bun:"type:jsonb" json:"elements,omitempty"`
+	CreatedAt time.Time                    `bun:",nullzero,notnull,default:current_timestamp" json:"createdAt"`
+}
+
+// DropMatrixSnapshotElement uses short JSON keys to keep snapshots small.
+type DropMatrixSnapshotElement struct {
+	StageID  int `json:"s"`
+	ItemID   int `json:"i"`
+	RangeID  int `json:"r"`
+	Quantity int `json:"q"`
+	Times    int `json:"t"`
+}
+
+type DropMatrixChanges struct {
+	Server         string `json:"server"`
+	SourceCategory string `json:"sourceCategory"`
+	// From and To are the snapshots being compared, which are the latest ones recorded before the requested times.
+	From    *DropMatrixSnapshot        `json:"from"`
+	To      *DropMatrixSnapshot        `json:"to"`
+	Changes []*DropMatrixElementChange `json:"changes"`
+}
+
+type DropMatrixElementChange struct {
+	StageID string `json:"stageId"`
+	ItemID  string `json:"itemId"`
+	RangeID int    `json:"rangeId"`
+
+	FromTimes    int     `json:"fromTimes"`
+	FromQuantity int     `json:"fromQuantity"`
+	FromRate     float64 `json:"fromRate"`
+	ToTimes      int     `json:"toTimes"`
+	ToQuantity   int     `json:"toQuantity"`
+	ToRate       float64 `json:"toRate"`
+	RateDelta    float64 `json:"rateDelta"`
+	// IntervalRate is the drop rate among the reports accepted in-between the two snapshots,
+	// i.e. quantity delta divided by times delta. It is null when times did not increase.
+	IntervalRate null.Float `json:"intervalRate" swaggertype:"number"`
+}
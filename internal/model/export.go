@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// ExportedDropReport is an anonymised drop report, without any information that could identify the reporter.
+type ExportedDropReport struct {
+	StageID    int         `bun:"stage_id"`
+	PatternID  int         `bun:"pattern_id"`
+	Times      int         `bun:"times"`
+	CreatedAt  time.Time   `bun:"created_at"`
+	SourceName null.String `bun:"source_name"`
+}
+
+type ExportManifest struct {
+	GeneratedAt time.Time             `json:"generatedAt"`
+	Files       []*ExportManifestFile `json:"files"`
+}
+
+type ExportManifestFile struct {
+	// Path is the path of the file relative to the export directory, which is also the path to download it.
+	Path string `json:"path" example:"snapshots/2022-05-01/CN/drop_matrix.csv"`
+	// Dataset is one of "drop_matrix", "pattern_matrix", "trend" and "drop_reports".
+	Dataset string `json:"dataset" example:"drop_matrix"`
+	Server  string `json:"server" example:"CN"`
+	// Date is the date of the snapshot, or the date the reports were submitted for drop_reports, in UTC.
+	Date string `json:"date" example:"2022-05-01"`
+	// Format is either "csv" or "jsonl.gz".
+	Format     string    `json:"format" example:"csv"`
+	Size       int64     `json:"size"`
+	SHA256     string    `json:"sha256"`
+	ModifiedAt time.Time `json:"modifiedAt"`
+}
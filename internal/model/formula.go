@@ -0,0 +1,66 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"github.com/uptrace/bun"
+	"gopkg.in/guregu/null.v3"
+)
+
+type Formula struct {
+	bun.BaseModel `bun:"formulas,alias:fo"`
+
+	FormulaID int `bun:",pk,autoincrement" json:"formulaId"`
+	// ArkItemID (itemId) is the string form ID of the item crafted by the formula. Each item has at most one formula.
+	ArkItemID string `bun:",unique" json:"itemId"`
+	// GoldCost is the amount of LMD consumed by a single crafting.
+	GoldCost int `json:"goldCost"`
+	// Costs are the items consumed by a single crafting.
+	Costs []*FormulaCost `bun:"type:jsonb" json:"costs"`
+	// ExtraOutcomes are the items that might be yielded as a byproduct of a crafting. The probability of each
+	// extra outcome, given that a byproduct is yielded, is its weight divided by the sum of all weights.
+	ExtraOutcomes []*FormulaExtraOutcome `bun:"type:jsonb" json:"extraOutcomes"`
+	// Existence is a map with server code as key and the existence of the formula in that server as value.
+	Existence json.RawMessage `json:"existence" swaggertype:"object"`
+}
+
+type FormulaCost struct {
+	ArkItemID string `json:"itemId"`
+	Count     int    `json:"count"`
+}
+
+type FormulaExtraOutcome struct {
+	ArkItemID string `json:"itemId"`
+	Weight    int    `json:"weight"`
+}
+
+// TotalWeight is the sum of weights of all extra outcomes of the formula.
+func (f *Formula) TotalWeight() int {
+	total := 0
+	for _, outcome := range f.ExtraOutcomes {
+		total += outcome.Weight
+	}
+	return total
+}
+
+// ExistsIn reports whether the formula exists in the given server. Formulas without existence
+// information are considered to exist in every server.
+func (f *Formula) ExistsIn(server string) bool {
+	if len(f.Existence) == 0 {
+		return true
+	}
+	exist := gjson.GetBytes(f.Existence, server+".exist")
+	return !exist.Exists() || exist.Bool()
+}
+
+// FormulaRevision is a snapshot of the full set of formulas, recorded every time the formulas are modified.
+type FormulaRevision struct {
+	bun.BaseModel `bun:"formula_revisions,alias:fr"`
+
+	RevisionID int         `bun:",pk,autoincrement" json:"revisionId"`
+	Comment    null.String `json:"comment" swaggertype:"string"`
+	Formulas   []*Formula  `bun:"type:jsonb" json:"formulas,omitempty"`
+	CreatedAt  time.Time   `bun:",nullzero,notnull,default:current_timestamp" json:"createdAt"`
+}
@@ -0,0 +1,33 @@
+package model
+
+// FurnitureDrops lists the furniture drop rates of every stage that drops furniture. Furniture is reported as a
+// single pseudo-item, at most once per run, so the rate of a stage is the probability of a run dropping furniture.
+type FurnitureDrops struct {
+	Server string                `json:"server"`
+	Stages []*FurnitureDropStage `json:"stages"`
+	// ConfidenceLevel is the confidence level of the intervals of every stage
+	ConfidenceLevel float64 `json:"confidenceLevel"`
+	UpdatedAt       int64   `json:"updatedAt"`
+}
+
+type FurnitureDropStage struct {
+	StageID string `json:"stageId"`
+	// Open is whether the stage currently drops furniture, i.e. it has a furniture drop info in a current time range
+	Open bool `json:"open"`
+	// Times is the number of runs reported
+	Times int `json:"times"`
+	// Quantity is the number of runs that dropped furniture
+	Quantity int `json:"quantity"`
+	// Rate is the probability of a run dropping furniture
+	Rate float64 `json:"rate"`
+	// ConfidenceLower and ConfidenceUpper are the bounds of the Wilson score interval of the rate
+	ConfidenceLower float64 `json:"confidenceLower"`
+	ConfidenceUpper float64 `json:"confidenceUpper"`
+}
+
+// FurnitureDropQuantity is the number of runs, and the quantity of furniture dropped, reported for a stage.
+type FurnitureDropQuantity struct {
+	StageID  int `bun:"stage_id"`
+	Times    int `bun:"times"`
+	Quantity int `bun:"quantity"`
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+	"gopkg.in/guregu/null.v3"
+)
+
+// GachaBoxPool is the definition of the pool of a gacha box stage: a box holding a fixed number of each item,
+// which are drawn without replacement until the box is cleared.
+type GachaBoxPool struct {
+	bun.BaseModel `bun:"gacha_box_pools,alias:gbp"`
+
+	PoolID int `bun:",pk,autoincrement" json:"poolId"`
+	// StageID is the numerical ID of the gacha box stage the pool belongs to. Each stage has at most one pool.
+	StageID int `bun:",unique" json:"-"`
+	// ArkStageID (stageId) is the string form ID of the gacha box stage.
+	ArkStageID string `bun:"-" json:"stageId"`
+	// Items are the items held by a full box.
+	Items []*GachaBoxPoolItem `bun:"type:jsonb" json:"items"`
+	// DrawCost is the amount of CostItemID consumed by a single draw.
+	DrawCost int `json:"drawCost"`
+	// CostItemID is the string form ID of the item consumed by draws, usually an event token.
+	CostItemID null.String `json:"costItemId,omitempty" swaggertype:"string"`
+	UpdatedAt  time.Time   `bun:",nullzero,notnull,default:current_timestamp" json:"updatedAt"`
+}
+
+type GachaBoxPoolItem struct {
+	ArkItemID string `json:"itemId"`
+	Quantity  int    `json:"quantity"`
+}
+
+// Size is the number of draws it takes to clear a full box.
+func (p *GachaBoxPool) Size() int {
+	size := 0
+	for _, item := range p.Items {
+		size += item.Quantity
+	}
+	return size
+}
+
+// GachaBoxDistribution compares the defined distribution of a gacha box pool with the one observed from reports.
+type GachaBoxDistribution struct {
+	StageID string                      `json:"stageId"`
+	Server  string                      `json:"server"`
+	Pool    *GachaBoxPool               `json:"pool"`
+	Draws   int                         `json:"draws"`
+	Items   []*GachaBoxDistributionItem `json:"items"`
+}
+
+type GachaBoxDistributionItem struct {
+	ItemID           string  `json:"itemId"`
+	DefinedQuantity  int     `json:"definedQuantity"`
+	DefinedShare     float64 `json:"definedShare"`
+	ObservedQuantity int     `json:"observedQuantity"`
+	ObservedShare    float64 `json:"observedShare"`
+}
+
+// GachaBoxRemainingPool is the state of the box a player is currently drawing from, inferred from their reports.
+type GachaBoxRemainingPool struct {
+	StageID string `json:"stageId"`
+	Server  string `json:"server"`
+	// Draws is the number of draws the player has reported in total
+	Draws int `json:"draws"`
+	// BoxesCleared is the number of full boxes the player has cleared
+	BoxesCleared int `json:"boxesCleared"`
+	// RemainingDraws is the number of draws left to clear the current box
+	RemainingDraws int `json:"remainingDraws"`
+	// RemainingCost is the amount of CostItemID needed to clear the current box
+	RemainingCost int                          `json:"remainingCost"`
+	CostItemID    null.String                  `json:"costItemId,omitempty" swaggertype:"string"`
+	Items         []*GachaBoxRemainingPoolItem `json:"items"`
+}
+
+type GachaBoxRemainingPoolItem struct {
+	ItemID    string `json:"itemId"`
+	Drawn     int    `json:"drawn"`
+	Remaining int    `json:"remaining"`
+	// Probability is the chance of drawing the item with the next draw
+	Probability float64 `json:"probability"`
+}
+
+// GachaBoxItemQuantity is the total quantity of an item drawn from a gacha box stage.
+type GachaBoxItemQuantity struct {
+	ItemID   int `bun:"item_id"`
+	Quantity int `bun:"total_quantity"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import "gopkg.in/guregu/null.v3"
+
+// ItemDrops lists every stage dropping an item, with the drop statistics of each of the stage's time ranges.
+type ItemDrops struct {
+	ItemID string           `json:"itemId"`
+	Server string           `json:"server"`
+	Stages []*ItemDropStage `json:"stages"`
+}
+
+type ItemDropStage struct {
+	StageID string `json:"stageId"`
+	// Open is whether the stage currently drops items, i.e. it has a drop info in a current time range
+	Open   bool             `json:"open"`
+	Ranges []*ItemDropRange `json:"ranges"`
+}
+
+type ItemDropRange struct {
+	// StartTime is in unix milliseconds
+	StartTime int64 `json:"start"`
+	// EndTime is in unix milliseconds; absent if the time range is still open
+	EndTime  null.Int `json:"end,omitempty" swaggertype:"integer"`
+	Quantity int      `json:"quantity"`
+	Times    int      `json:"times"`
+	Rate     float64  `json:"rate"`
+	// SanityPerItem is the expected sanity cost to obtain one item; absent if the item has never dropped
+	// or the stage has no sanity cost
+	SanityPerItem null.Float `json:"sanityPerItem,omitempty" swaggertype:"number"`
+}
@@ -0,0 +1,27 @@
+package model
+
+type PlannerResult struct {
+	// TotalSanity is the expected sanity cost of all stage runs in the plan.
+	TotalSanity float64 `json:"totalSanity"`
+	// TotalGoldCost is the LMD cost of all craftings in the plan.
+	TotalGoldCost float64 `json:"totalGoldCost"`
+
+	Stages    []*PlannerStage    `json:"stages"`
+	Craftings []*PlannerCrafting `json:"craftings"`
+}
+
+type PlannerStage struct {
+	StageID string  `json:"stageId" example:"main_01-07"`
+	Times   float64 `json:"times"`
+	Sanity  float64 `json:"sanity"`
+	// Items is a map with item ID as key and the expected quantity of that item dropped from all runs as value.
+	Items map[string]float64 `json:"items"`
+}
+
+type PlannerCrafting struct {
+	ItemID   string  `json:"itemId" example:"30013"`
+	Times    float64 `json:"times"`
+	GoldCost float64 `json:"goldCost"`
+	// Materials is a map with item ID as key and the quantity of that item consumed by all craftings as value.
+	Materials map[string]float64 `json:"materials"`
+}
@@ -0,0 +1,53 @@
+package model
+
+// SiteStats is the expanded site statistics of a server over the last constant.SiteStatsDays game days.
+// Unless noted otherwise, figures only count reliable reports; reports deleted by their submitters are
+// excluded altogether.
+type SiteStats struct {
+	Server string `json:"server"`
+	// StartTime is the start of the first game day covered, in unix milliseconds
+	StartTime int64              `json:"start"`
+	Total     *SiteStatsTotal    `json:"total"`
+	Daily     []*SiteStatsDay    `json:"daily"`
+	Zones     []*SiteStatsZone   `json:"zones"`
+	Sources   []*SiteStatsSource `json:"sources"`
+	UpdatedAt int64              `json:"updatedAt"`
+}
+
+type SiteStatsTotal struct {
+	// Reports counts both reliable and unreliable reports
+	Reports           int     `json:"reports"`
+	UnreliableReports int     `json:"unreliableReports"`
+	UnreliableShare   float64 `json:"unreliableShare"`
+	Times             int     `json:"times"`
+	Sanity            int     `json:"sanity"`
+	UniqueAccounts    int     `json:"uniqueAccounts"`
+}
+
+type SiteStatsDay struct {
+	// Date is the game day in the server's timezone, formatted as YYYY-MM-DD
+	Date string `json:"date" bun:"date"`
+	// Reports counts both reliable and unreliable reports
+	Reports           int     `json:"reports" bun:"reports"`
+	UnreliableReports int     `json:"unreliableReports" bun:"unreliable_reports"`
+	UnreliableShare   float64 `json:"unreliableShare" bun:"-"`
+	Times             int     `json:"times" bun:"times"`
+	Sanity            int     `json:"sanity" bun:"sanity"`
+	UniqueAccounts    int     `json:"uniqueAccounts" bun:"unique_accounts"`
+}
+
+type SiteStatsZone struct {
+	ZoneID  string `json:"zoneId" bun:"ark_zone_id"`
+	Reports int    `json:"reports" bun:"reports"`
+	Times   int    `json:"times" bun:"times"`
+	Sanity  int    `json:"sanity" bun:"sanity"`
+}
+
+type SiteStatsSource struct {
+	SourceName string `json:"source" bun:"source_name"`
+	// Reports counts both reliable and unreliable reports
+	Reports           int     `json:"reports" bun:"reports"`
+	UnreliableReports int     `json:"unreliableReports" bun:"unreliable_reports"`
+	UnreliableShare   float64 `json:"unreliableShare" bun:"-"`
+	Times             int     `json:"times" bun:"times"`
+}
@@ -28,10 +28,25 @@ type TimeRange struct {
 }
 
 type PurgeCacheRequest struct {
-	Pairs []PurgeCachePair `json:"pairs"`
+	Pairs []PurgeCachePair `json:"pairs" validate:"required,min=1,dive"`
 }
 
+// PurgeCachePair identifies a cache to purge. Name could also be in the form of name:key, e.g.
+// stage#arkStageId:main_01-07, in which case Key should be left empty.
 type PurgeCachePair struct {
-	Name string      `json:"name"`
+	Name string      `json:"name" validate:"required"`
 	Key  null.String `json:"key" swaggertype:"string"`
 }
+
+// PurgeCacheAck is the acknowledgement of a cache purge from one instance.
+type PurgeCacheAck struct {
+	Instance string              `json:"instance"`
+	OK       bool                `json:"ok"`
+	Results  []*PurgeCacheResult `json:"results"`
+}
+
+type PurgeCacheResult struct {
+	Name  string      `json:"name"`
+	Key   null.String `json:"key" swaggertype:"string"`
+	Error string      `json:"error,omitempty"`
+}
@@ -0,0 +1,18 @@
+package types
+
+type DropMatrixSnapshotsQuery struct {
+	Server         string `query:"server" validate:"required,alpha,caseinsensitiveoneof=CN US JP KR"`
+	SourceCategory string `query:"source" validate:"omitempty,oneof=all automated manual"`
+}
+
+type DropMatrixChangesQuery struct {
+	Server         string `query:"server" validate:"required,alpha,caseinsensitiveoneof=CN US JP KR"`
+	SourceCategory string `query:"source" validate:"omitempty,oneof=all automated manual"`
+	// From and To are timestamps in milliseconds
+	From    int64  `query:"from" validate:"required,gt=0"`
+	To      int64  `query:"to" validate:"required,gtfield=From"`
+	StageID string `query:"stageId" validate:"omitempty,printascii"`
+	ItemID  string `query:"itemId" validate:"omitempty,printascii"`
+	// Threshold is the minimum absolute difference of drop rates for an element to be considered as changed
+	Threshold float64 `query:"threshold" validate:"gte=0,lte=1000"`
+}
@@ -0,0 +1,29 @@
+package types
+
+import "gopkg.in/guregu/null.v3"
+
+type SaveFormulaRequest struct {
+	GoldCost      int                           `json:"goldCost" validate:"gte=0"`
+	Costs         []*FormulaCostRequest         `json:"costs" validate:"required,min=1,max=16,dive"`
+	ExtraOutcomes []*FormulaExtraOutcomeRequest `json:"extraOutcomes" validate:"max=64,dive"`
+	// Existence is a map with server code as key and whether the formula exists in that server as value.
+	// Servers omitted are considered as existing.
+	Existence map[string]bool `json:"existence" validate:"dive,keys,caseinsensitiveoneof=CN US JP KR,endkeys"`
+	// Comment describes the change and is recorded in the revision history.
+	Comment null.String `json:"comment" swaggertype:"string"`
+}
+
+type FormulaCostRequest struct {
+	ItemID string `json:"itemId" validate:"required,printascii"`
+	Count  int    `json:"count" validate:"required,gt=0,lte=1000"`
+}
+
+type FormulaExtraOutcomeRequest struct {
+	ItemID string `json:"itemId" validate:"required,printascii"`
+	Weight int    `json:"weight" validate:"required,gt=0"`
+}
+
+type FormulaChangeRequest struct {
+	// Comment describes the change and is recorded in the revision history.
+	Comment null.String `json:"comment" swaggertype:"string"`
+}
@@ -0,0 +1,14 @@
+package types
+
+import "gopkg.in/guregu/null.v3"
+
+type SaveGachaBoxPoolRequest struct {
+	Items      []*GachaBoxPoolItemRequest `json:"items" validate:"required,min=1,max=128,dive"`
+	DrawCost   int                        `json:"drawCost" validate:"gte=0"`
+	CostItemID null.String                `json:"costItemId" swaggertype:"string"`
+}
+
+type GachaBoxPoolItemRequest struct {
+	ItemID   string `json:"itemId" validate:"required,printascii"`
+	Quantity int    `json:"quantity" validate:"required,gt=0,lte=10000"`
+}
@@ -0,0 +1,24 @@
+package types
+
+import "gopkg.in/guregu/null.v3"
+
+type PlannerRequest struct {
+	Server string `json:"server" validate:"required,alpha,caseinsensitiveoneof=CN US JP KR" required:"true" example:"CN"`
+	// Needs is a map with item ID as key and the required quantity of that item as value.
+	Needs map[string]int `json:"needs" validate:"required,min=1,max=200,dive,keys,required,printascii,endkeys,gte=0,lte=1000000" required:"true"`
+	// Owned is a map with item ID as key and the quantity of that item currently owned as value.
+	Owned map[string]int `json:"owned" validate:"max=1000,dive,keys,required,printascii,endkeys,gte=0,lte=1000000"`
+
+	Options PlannerOptions `json:"options"`
+}
+
+type PlannerOptions struct {
+	// AllowCrafting determines whether crafting through the workshop shall be considered. Defaults to true.
+	AllowCrafting null.Bool `json:"allowCrafting" swaggertype:"boolean"`
+	// ByproductRate is the probability of a crafting yielding an extra outcome. Defaults to 0.1.
+	ByproductRate null.Float `json:"byproductRate" swaggertype:"number"`
+	// MinTimes is the minimum number of samples a stage should have to be considered. Defaults to 100.
+	MinTimes null.Int `json:"minTimes" swaggertype:"integer"`
+	// ExcludedStages is a list of stage IDs that shall not be considered.
+	ExcludedStages []string `json:"excludedStages" validate:"max=1000,dive,printascii"`
+}
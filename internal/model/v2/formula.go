@@ -0,0 +1,24 @@
+package v2
+
+// Formula is the legacy representation of a crafting formula.
+type Formula struct {
+	ID           string                 `json:"id" example:"30013"`
+	Name         string                 `json:"name"`
+	Costs        []*FormulaCost         `json:"costs"`
+	GoldCost     int                    `json:"goldCost" example:"200"`
+	ExtraOutcome []*FormulaExtraOutcome `json:"extraOutcome"`
+	TotalWeight  int                    `json:"totalWeight"`
+}
+
+type FormulaCost struct {
+	ID    string `json:"id" example:"30012"`
+	Name  string `json:"name"`
+	Count int    `json:"count" example:"5"`
+}
+
+type FormulaExtraOutcome struct {
+	ID     string `json:"id" example:"30023"`
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+	Count  int    `json:"count"`
+}
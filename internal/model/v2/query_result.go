@@ -15,6 +15,24 @@ type OneDropMatrixElement struct {
 	StdDev    float64  `json:"stdDev" example:"0.114514"`
 	StartTime int64    `json:"start" example:"1556676000000"`
 	EndTime   null.Int `json:"end,omitempty" swaggertype:"integer"`
+	// Shrinkage is only present when explicitly requested, and when a prior could be derived for the element
+	Shrinkage *DropRateEstimate `json:"shrinkage,omitempty"`
+}
+
+// DropRateEstimate is a drop rate shrunk toward a prior derived from the same item's rates on comparable stages,
+// so that rates of stages with few samples are less volatile.
+type DropRateEstimate struct {
+	// Rate is the estimated quantity per run
+	Rate float64 `json:"rate" example:"1.2456"`
+	// PriorRate is the quantity per run of the item pooled over comparable stages
+	PriorRate float64 `json:"priorRate" example:"1.2"`
+	// PriorSource describes which stages are considered comparable: stages in zones of the same category
+	// ("zoneCategory"), or of the same sanity cost ("sanityTier")
+	PriorSource string `json:"priorSource" example:"zoneCategory"`
+	// PriorStrength is the weight of the prior in pseudo-runs
+	PriorStrength float64 `json:"priorStrength" example:"30"`
+	// EffectiveSampleSize is the number of runs the estimate is worth, i.e. times plus prior strength
+	EffectiveSampleSize float64 `json:"effectiveSampleSize" example:"130"`
 }
 
 // DropPattern
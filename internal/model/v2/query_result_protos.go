@@ -0,0 +1,87 @@
+package v2
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"github.com/penguin-statistics/backend-next/internal/model/protos"
+)
+
+func (r *DropMatrixQueryResult) ToProtobuf() proto.Message {
+	matrix := make([]*protos.DropMatrixElement, 0, len(r.Matrix))
+	for _, el := range r.Matrix {
+		pel := &protos.DropMatrixElement{
+			StageId:  el.StageID,
+			ItemId:   el.ItemID,
+			Times:    int64(el.Times),
+			Quantity: int64(el.Quantity),
+			StdDev:   el.StdDev,
+			Start:    el.StartTime,
+		}
+		if el.EndTime.Valid {
+			pel.End = proto.Int64(el.EndTime.Int64)
+		}
+		if el.Shrinkage != nil {
+			pel.Shrinkage = &protos.DropRateEstimate{
+				Rate:                el.Shrinkage.Rate,
+				PriorRate:           el.Shrinkage.PriorRate,
+				PriorSource:         el.Shrinkage.PriorSource,
+				PriorStrength:       el.Shrinkage.PriorStrength,
+				EffectiveSampleSize: el.Shrinkage.EffectiveSampleSize,
+			}
+		}
+		matrix = append(matrix, pel)
+	}
+	return &protos.DropMatrixQueryResult{Matrix: matrix}
+}
+
+func (r *PatternMatrixQueryResult) ToProtobuf() proto.Message {
+	patternMatrix := make([]*protos.PatternMatrixElement, 0, len(r.PatternMatrix))
+	for _, el := range r.PatternMatrix {
+		pel := &protos.PatternMatrixElement{
+			StageId:  el.StageID,
+			Times:    int64(el.Times),
+			Quantity: int64(el.Quantity),
+			Start:    el.StartTime,
+		}
+		if el.Pattern != nil {
+			pel.Drops = make([]*protos.PatternDrop, 0, len(el.Pattern.Drops))
+			for _, drop := range el.Pattern.Drops {
+				pel.Drops = append(pel.Drops, &protos.PatternDrop{
+					ItemId:   drop.ItemID,
+					Quantity: int64(drop.Quantity),
+				})
+			}
+		}
+		if el.EndTime.Valid {
+			pel.End = proto.Int64(el.EndTime.Int64)
+		}
+		patternMatrix = append(patternMatrix, pel)
+	}
+	return &protos.PatternMatrixQueryResult{PatternMatrix: patternMatrix}
+}
+
+func (r *TrendQueryResult) ToProtobuf() proto.Message {
+	trend := make(map[string]*protos.StageTrend, len(r.Trend))
+	for stageId, stageTrend := range r.Trend {
+		results := make(map[string]*protos.ItemTrend, len(stageTrend.Results))
+		for itemId, itemTrend := range stageTrend.Results {
+			results[itemId] = &protos.ItemTrend{
+				Quantity: toInt64s(itemTrend.Quantity),
+				Times:    toInt64s(itemTrend.Times),
+			}
+		}
+		trend[stageId] = &protos.StageTrend{
+			Results:   results,
+			StartTime: stageTrend.StartTime,
+		}
+	}
+	return &protos.TrendQueryResult{Trend: trend}
+}
+
+func toInt64s(s []int) []int64 {
+	r := make([]int64, len(s))
+	for i, v := range s {
+		r[i] = int64(v)
+	}
+	return r
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"reflect"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/guregu/null.v3"
+)
+
+// null types are encoded in msgpack as their JSON counterparts, i.e. the value itself or nil, both for msgpack
+// responses and for values stored in the remote tier
+func init() {
+	msgpack.Register(null.Int{}, func(e *msgpack.Encoder, v reflect.Value) error {
+		if i := v.Interface().(null.Int); i.Valid {
+			return e.EncodeInt(i.Int64)
+		}
+		return e.EncodeNil()
+	}, func(d *msgpack.Decoder, v reflect.Value) error {
+		var i *int64
+		if err := d.Decode(&i); err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(null.IntFromPtr(i)))
+		return nil
+	})
+	msgpack.Register(null.Float{}, func(e *msgpack.Encoder, v reflect.Value) error {
+		if f := v.Interface().(null.Float); f.Valid {
+			return e.EncodeFloat64(f.Float64)
+		}
+		return e.EncodeNil()
+	}, func(d *msgpack.Decoder, v reflect.Value) error {
+		var f *float64
+		if err := d.Decode(&f); err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(null.FloatFromPtr(f)))
+		return nil
+	})
+	msgpack.Register(null.String{}, func(e *msgpack.Encoder, v reflect.Value) error {
+		if s := v.Interface().(null.String); s.Valid {
+			return e.EncodeString(s.String)
+		}
+		return e.EncodeNil()
+	}, func(d *msgpack.Decoder, v reflect.Value) error {
+		var s *string
+		if err := d.Decode(&s); err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(null.StringFromPtr(s)))
+		return nil
+	})
+	msgpack.Register(null.Bool{}, func(e *msgpack.Encoder, v reflect.Value) error {
+		if b := v.Interface().(null.Bool); b.Valid {
+			return e.EncodeBool(b.Bool)
+		}
+		return e.EncodeNil()
+	}, func(d *msgpack.Decoder, v reflect.Value) error {
+		var b *bool
+		if err := d.Decode(&b); err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(null.BoolFromPtr(b)))
+		return nil
+	})
+}
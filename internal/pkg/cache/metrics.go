@@ -0,0 +1,52 @@
+package cache
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Collector exports the statistics of every cache as Prometheus metrics labelled by cache name.
+type Collector struct {
+	hits               *prometheus.Desc
+	misses             *prometheus.Desc
+	staleHits          *prometheus.Desc
+	computations       *prometheus.Desc
+	computationErrors  *prometheus.Desc
+	computationSeconds *prometheus.Desc
+	items              *prometheus.Desc
+}
+
+func NewCollector(namespace string) *Collector {
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "cache", name), help, []string{"cache", "kind"}, nil)
+	}
+	return &Collector{
+		hits:               desc("hits_total", "Number of cache lookups found in the cache"),
+		misses:             desc("misses_total", "Number of cache lookups not found in the cache"),
+		staleHits:          desc("stale_hits_total", "Number of cache lookups found in the cache but no longer fresh"),
+		computations:       desc("computations_total", "Number of values computed on cache misses"),
+		computationErrors:  desc("computation_errors_total", "Number of values failed to be computed on cache misses"),
+		computationSeconds: desc("computation_seconds_total", "Total time spent computing values on cache misses in seconds"),
+		items:              desc("items", "Number of entries held in the in-process cache"),
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.staleHits
+	ch <- c.computations
+	ch <- c.computationErrors
+	ch <- c.computationSeconds
+	ch <- c.items
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, cache := range All() {
+		s := cache.Stats()
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits), s.Name, s.Kind)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses), s.Name, s.Kind)
+		ch <- prometheus.MustNewConstMetric(c.staleHits, prometheus.CounterValue, float64(s.StaleHits), s.Name, s.Kind)
+		ch <- prometheus.MustNewConstMetric(c.computations, prometheus.CounterValue, float64(s.Computations), s.Name, s.Kind)
+		ch <- prometheus.MustNewConstMetric(c.computationErrors, prometheus.CounterValue, float64(s.ComputationErrors), s.Name, s.Kind)
+		ch <- prometheus.MustNewConstMetric(c.computationSeconds, prometheus.CounterValue, s.ComputationSeconds, s.Name, s.Kind)
+		ch <- prometheus.MustNewConstMetric(c.items, prometheus.GaugeValue, float64(s.Items), s.Name, s.Kind)
+	}
+}
@@ -0,0 +1,241 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	remoteKeyPrefix          = "penguin:cache:"
+	remoteLockPrefix         = "penguin:cachelock:"
+	remoteInvalidatesChannel = "penguin:cache:invalidates"
+
+	// remoteTimeout bounds every single operation against the remote tier, so that a slow Redis degrades the caches
+	// to be local-only instead of stalling requests
+	remoteTimeout = time.Second
+
+	// remoteLockPollInterval is how often a value computed by another instance holding the lock is checked for
+	remoteLockPollInterval = 100 * time.Millisecond
+)
+
+// releaseLockScript deletes the lock only if it is still held by the given token
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// remote is the shared tier of every cache created after EnableRemote is called; nil if not enabled
+var remote *Remote
+
+// Remote is a Redis tier shared by every instance behind their in-process caches. Values are serialized with
+// msgpack along with their expiry; a value set, deleted or flushed on one instance is invalidated on the others
+// through Redis pub/sub, so that they read it again from Redis, and computation of a missing value in MutexGetSet is
+// guarded by a per-key lock, so that only one instance computes it.
+type Remote struct {
+	client *redis.Client
+	// origin identifies this instance in invalidations, so that it ignores its own
+	origin string
+	// lockTTL is how long a lock is held at most, i.e. how long other instances wait for the value to be computed
+	// before they compute it themselves
+	lockTTL time.Duration
+
+	mu sync.RWMutex
+	// invalidators invalidate the local tier of the cache of the given name; an empty key flushes all of it
+	invalidators map[string]func(key string)
+}
+
+type invalidation struct {
+	Origin string `json:"o"`
+	Name   string `json:"n"`
+	// Key is the key invalidated; empty to flush the whole cache
+	Key string `json:"k,omitempty"`
+}
+
+// EnableRemote makes every cache created afterwards use client as its shared tier, and starts listening for
+// invalidations from other instances. It must be called before any cache that should be shared is created.
+func EnableRemote(client *redis.Client, lockTTL time.Duration) *Remote {
+	origin := make([]byte, 8)
+	_, _ = rand.Read(origin)
+
+	remote = &Remote{
+		client:       client,
+		origin:       hex.EncodeToString(origin),
+		lockTTL:      lockTTL,
+		invalidators: make(map[string]func(key string)),
+	}
+	go remote.listen(context.Background())
+	return remote
+}
+
+func (r *Remote) register(name string, invalidator func(key string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.invalidators[name] = invalidator
+}
+
+func (r *Remote) listen(ctx context.Context) {
+	sub := r.client.Subscribe(ctx, remoteInvalidatesChannel)
+	defer sub.Close()
+
+	// the channel reconnects and resubscribes by itself should the connection drop
+	for msg := range sub.Channel() {
+		var inv invalidation
+		if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+			log.Warn().Err(err).Str("payload", msg.Payload).Msg("failed to parse cache invalidation")
+			continue
+		}
+		if inv.Origin == r.origin {
+			continue
+		}
+
+		r.mu.RLock()
+		invalidator, ok := r.invalidators[inv.Name]
+		r.mu.RUnlock()
+		if ok {
+			invalidator(inv.Key)
+		}
+	}
+}
+
+func (r *Remote) publish(ctx context.Context, name string, key string) {
+	payload, err := json.Marshal(invalidation{Origin: r.origin, Name: name, Key: key})
+	if err != nil {
+		return
+	}
+	if err := r.client.Publish(ctx, remoteInvalidatesChannel, payload).Err(); err != nil {
+		log.Warn().Err(err).Str("name", name).Str("key", key).Msg("failed to publish cache invalidation")
+	}
+}
+
+// get reads the value stored at key into dest, returning its remaining time to live, or 0 if it never expires.
+func (r *Remote) get(key string, dest any) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+
+	pipe := r.client.Pipeline()
+	getCmd := pipe.Get(ctx, remoteKeyPrefix+key)
+	ttlCmd := pipe.PTTL(ctx, remoteKeyPrefix+key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+
+	b, err := getCmd.Bytes()
+	if err != nil {
+		return 0, err
+	}
+	if err := msgpack.Unmarshal(b, dest); err != nil {
+		return 0, err
+	}
+	ttl := ttlCmd.Val()
+	if ttl < 0 {
+		ttl = 0
+	}
+	return ttl, nil
+}
+
+// set stores value at key, and invalidates it on other instances.
+func (r *Remote) set(name string, key string, value any, expire time.Duration) {
+	b, err := msgpack.Marshal(value)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("failed to serialize value for remote cache")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	if err := r.client.Set(ctx, remoteKeyPrefix+key, b, expire).Err(); err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("failed to set value to remote cache")
+		return
+	}
+	r.publish(ctx, name, key)
+}
+
+// delete deletes key, and invalidates it on other instances.
+func (r *Remote) delete(name string, key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	if err := r.client.Del(ctx, remoteKeyPrefix+key).Err(); err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("failed to delete value from remote cache")
+	}
+	r.publish(ctx, name, key)
+}
+
+// flush deletes every key starting with prefix, and flushes the cache on other instances.
+func (r *Remote) flush(name string, prefix string) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout*10)
+	defer cancel()
+
+	iter := r.client.Scan(ctx, 0, remoteKeyPrefix+prefix+"*", 1000).Iterator()
+	keys := make([]string, 0)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		log.Warn().Err(err).Str("name", name).Msg("failed to scan remote cache for flushing")
+	} else if len(keys) > 0 {
+		if err := r.client.Del(ctx, keys...).Err(); err != nil {
+			log.Warn().Err(err).Str("name", name).Msg("failed to flush remote cache")
+		}
+	}
+	r.publish(ctx, name, "")
+}
+
+// lock tries to acquire the lock of key, returning a function releasing it if acquired.
+func (r *Remote) lock(key string) (release func(), acquired bool) {
+	token := make([]byte, 8)
+	_, _ = rand.Read(token)
+	value := hex.EncodeToString(token)
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	acquired, err := r.client.SetNX(ctx, remoteLockPrefix+key, value, r.lockTTL).Result()
+	if err != nil {
+		// without the remote tier, at worst every instance computes the value, as it would be without it
+		log.Warn().Err(err).Str("key", key).Msg("failed to acquire remote cache lock")
+		return func() {}, true
+	}
+	if !acquired {
+		return nil, false
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+		defer cancel()
+		releaseLockScript.Run(ctx, r.client, []string{remoteLockPrefix + key}, value)
+	}, true
+}
+
+// waitFor polls key for a value computed by the instance holding its lock, until the value shows up, or the lock
+// is released or expires without it.
+func (r *Remote) waitFor(key string, dest any) (time.Duration, error) {
+	deadline := time.Now().Add(r.lockTTL)
+	for time.Now().Before(deadline) {
+		time.Sleep(remoteLockPollInterval)
+
+		ttl, err := r.get(key, dest)
+		if err == nil {
+			return ttl, nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+		locked, err := r.client.Exists(ctx, remoteLockPrefix+key).Result()
+		cancel()
+		if err != nil || locked == 0 {
+			break
+		}
+	}
+	// the value may have been set right before the lock was released
+	return r.get(key, dest)
+}
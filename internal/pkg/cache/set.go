@@ -10,19 +10,59 @@ import (
 )
 
 func NewSet[T any](prefix string) *Set[T] {
-	return &Set[T]{
+	c := &Set[T]{
+		name:   prefix,
 		prefix: prefix + ":",
 		c:      cache.New(cache.NoExpiration, time.Minute*10),
+		remote: remote,
+
+		revalidating: make(map[string]chan struct{}),
+	}
+	if c.remote != nil {
+		c.remote.register(c.name, func(key string) {
+			if key == "" {
+				c.c.Flush()
+			} else {
+				c.c.Delete(key)
+			}
+		})
 	}
+	register(c)
+	return c
 }
 
 type Set[T any] struct {
 	// m is a mutex for MutexGetSet for concurrent prevention
 	m sync.Mutex
 
+	name   string
 	prefix string
 
 	c *cache.Cache
+
+	// remote is the shared tier behind c; nil if the remote tier is not enabled
+	remote *Remote
+
+	// revalidating holds the keys being revalidated, each with a channel closed once its revalidation is done
+	revalidatingMu sync.Mutex
+	revalidating   map[string]chan struct{}
+
+	// onSet is called with the key of every value set by this instance, once it is stored
+	onSet func(key string)
+
+	stats stats
+}
+
+func (c *Set[T]) Name() string {
+	return c.name
+}
+
+func (c *Set[T]) Stats() Stats {
+	return c.stats.snapshot(c.name, KindSet, c.c)
+}
+
+func (c *Set[T]) Entries(prefix string, limit int) ([]Entry, int) {
+	return entries(c.c, c.prefix, prefix, limit)
 }
 
 func (c *Set[T]) key(key string) string {
@@ -30,13 +70,30 @@ func (c *Set[T]) key(key string) string {
 }
 
 func (c *Set[T]) Get(key string, dest *T) error {
+	err := c.get(key, dest)
+	c.stats.lookup(err == nil)
+	return err
+}
+
+func (c *Set[T]) get(key string, dest *T) error {
+	_, err := c.getWithExpiry(key, dest)
+	return err
+}
+
+// getWithExpiry is like get, but also returns when the value expires, or a zero time if it never expires.
+func (c *Set[T]) getWithExpiry(key string, dest *T) (time.Time, error) {
 	key = c.key(key)
-	result, ok := c.c.Get(key)
+	result, expiresAt, ok := c.c.GetWithExpiration(key)
 	if !ok {
+		if c.remote != nil {
+			if expiresAt, err := c.getRemote(key, dest); err == nil {
+				return expiresAt, nil
+			}
+		}
 		if l := log.Trace(); l.Enabled() {
 			l.Str("key", key).Msg("cache entry not found")
 		}
-		return ErrNotFound
+		return time.Time{}, ErrNotFound
 	}
 
 	// copy value to dest
@@ -47,15 +104,44 @@ func (c *Set[T]) Get(key string, dest *T) error {
 		r = reflect.ValueOf(result)
 	}
 	reflect.ValueOf(dest).Elem().Set(r)
-	return nil
+	return expiresAt, nil
 }
 
 func (c *Set[T]) Set(key string, value T, expire time.Duration) {
-	key = c.key(key)
+	prefixed := c.key(key)
 	if l := log.Trace(); l.Enabled() {
-		l.Str("key", key).Msg("setting value to cache")
+		l.Str("key", prefixed).Msg("setting value to cache")
+	}
+	c.c.Set(prefixed, value, expire)
+	if c.remote != nil {
+		c.remote.set(c.name, prefixed, value, expire)
+	}
+	if c.onSet != nil {
+		c.onSet(key)
 	}
-	c.c.Set(key, value, expire)
+}
+
+// OnSet registers f to be called with the key of every value set by this instance once it is stored, such as the
+// values computed in the background by StaleGetSet and Revalidate. It must be registered before the set is used.
+func (c *Set[T]) OnSet(f func(key string)) {
+	c.onSet = f
+}
+
+// getRemote reads key, which is already prefixed, from the remote tier into dest, and keeps it in the local tier
+// for the rest of its time to live, which is returned as the time it expires at.
+func (c *Set[T]) getRemote(key string, dest *T) (time.Time, error) {
+	ttl, err := c.remote.get(key, dest)
+	if err != nil {
+		if err != ErrNotFound {
+			log.Warn().Err(err).Str("key", key).Msg("failed to get value from remote cache")
+		}
+		return time.Time{}, err
+	}
+	c.c.Set(key, *dest, ttl)
+	if ttl == 0 {
+		return time.Time{}, nil
+	}
+	return time.Now().Add(ttl), nil
 }
 
 // MutexGetSet gets value from cache and writes to dest, or if the key does not exist, it executes valueFunc
@@ -75,13 +161,25 @@ func (c *Set[T]) MutexGetSet(key string, dest *T, valueFunc func() (*T, error),
 func (c *Set[T]) slowMutexGetSet(key string, dest *T, valueFunc func() (*T, error), expire time.Duration) error {
 	c.m.Lock()
 	defer c.m.Unlock()
-	err := c.Get(key, dest)
+	err := c.get(key, dest)
 
 	if err == nil {
 		return nil
 	}
 
+	if c.remote != nil {
+		// let only one instance compute the value, while the others wait for it to show up in the remote tier
+		release, acquired := c.remote.lock(c.key(key))
+		if acquired {
+			defer release()
+		} else if c.waitRemote(key, dest) == nil {
+			return nil
+		}
+	}
+
+	started := time.Now()
 	value, err := valueFunc()
+	c.stats.computed(started, err)
 	if err != nil {
 		log.Error().Err(err).Str("key", key).Msg("failed to get value from valueFunc() in MutexGetSet")
 		return err
@@ -105,17 +203,35 @@ func (c *Set[T]) slowMutexGetSet(key string, dest *T, valueFunc func() (*T, erro
 	return nil
 }
 
+// waitRemote waits for the value of key to be computed by the instance holding its lock, and keeps it in the
+// local tier once it shows up.
+func (c *Set[T]) waitRemote(key string, dest *T) error {
+	key = c.key(key)
+	ttl, err := c.remote.waitFor(key, dest)
+	if err != nil {
+		return err
+	}
+	c.c.Set(key, *dest, ttl)
+	return nil
+}
+
 func (c *Set[T]) Delete(key string) error {
 	key = c.key(key)
 	if l := log.Trace(); l.Enabled() {
 		l.Str("key", key).Msg("deleting value from cache")
 	}
 	c.c.Delete(key)
+	if c.remote != nil {
+		c.remote.delete(c.name, key)
+	}
 
 	return nil
 }
 
 func (c *Set[T]) Flush() error {
 	c.c.Flush()
+	if c.remote != nil {
+		c.remote.flush(c.name, c.prefix)
+	}
 	return nil
 }
@@ -10,10 +10,18 @@ import (
 )
 
 func NewSingular[T any](key string) *Singular[T] {
-	return &Singular[T]{
-		key: key,
-		c:   cache.New(cache.NoExpiration, time.Minute*10),
+	c := &Singular[T]{
+		key:    key,
+		c:      cache.New(cache.NoExpiration, time.Minute*10),
+		remote: remote,
 	}
+	if c.remote != nil {
+		c.remote.register(c.key, func(string) {
+			c.c.Flush()
+		})
+	}
+	register(c)
+	return c
 }
 
 type Singular[T any] struct {
@@ -23,11 +31,37 @@ type Singular[T any] struct {
 	key string
 
 	c *cache.Cache
+
+	// remote is the shared tier behind c; nil if the remote tier is not enabled
+	remote *Remote
+
+	stats stats
+}
+
+func (c *Singular[T]) Name() string {
+	return c.key
+}
+
+func (c *Singular[T]) Stats() Stats {
+	return c.stats.snapshot(c.key, KindSingular, c.c)
+}
+
+func (c *Singular[T]) Entries(prefix string, limit int) ([]Entry, int) {
+	return entries(c.c, "", prefix, limit)
 }
 
 func (c *Singular[T]) Get(dest *T) error {
+	err := c.get(dest)
+	c.stats.lookup(err == nil)
+	return err
+}
+
+func (c *Singular[T]) get(dest *T) error {
 	result, ok := c.c.Get(c.key)
 	if !ok {
+		if c.remote != nil {
+			return c.getRemote(dest)
+		}
 		return ErrNotFound
 	}
 	// copy value to dest
@@ -44,6 +78,23 @@ func (c *Singular[T]) Get(dest *T) error {
 
 func (c *Singular[T]) Set(value T, expire time.Duration) {
 	c.c.Set(c.key, value, expire)
+	if c.remote != nil {
+		c.remote.set(c.key, c.key, value, expire)
+	}
+}
+
+// getRemote reads the value from the remote tier into dest, and keeps it in the local tier for the rest of its
+// time to live.
+func (c *Singular[T]) getRemote(dest *T) error {
+	ttl, err := c.remote.get(c.key, dest)
+	if err != nil {
+		if err != ErrNotFound {
+			log.Warn().Err(err).Str("key", c.key).Msg("failed to get value from remote cache")
+		}
+		return ErrNotFound
+	}
+	c.c.Set(c.key, *dest, ttl)
+	return nil
 }
 
 // MutexGetSet gets value from cache and writes to dest, or if the key does not exist, it executes valueFunc
@@ -63,13 +114,26 @@ func (c *Singular[T]) MutexGetSet(dest *T, valueFunc func() (T, error), expire t
 func (c *Singular[T]) slowMutexGetSet(dest *T, valueFunc func() (T, error), expire time.Duration) error {
 	c.m.Lock()
 	defer c.m.Unlock()
-	err := c.Get(dest)
+	err := c.get(dest)
 
 	if err == nil {
 		return nil
 	}
 
+	if c.remote != nil {
+		// let only one instance compute the value, while the others wait for it to show up in the remote tier
+		release, acquired := c.remote.lock(c.key)
+		if acquired {
+			defer release()
+		} else if ttl, err := c.remote.waitFor(c.key, dest); err == nil {
+			c.c.Set(c.key, *dest, ttl)
+			return nil
+		}
+	}
+
+	started := time.Now()
 	value, err := valueFunc()
+	c.stats.computed(started, err)
 	if err != nil {
 		log.Error().Err(err).Str("key", c.key).Msg("failed to get value from valueFunc() in MutexGetSet")
 		return err
@@ -92,5 +156,8 @@ func (c *Singular[T]) slowMutexGetSet(dest *T, valueFunc func() (T, error), expi
 
 func (c *Singular[T]) Delete() error {
 	c.c.Flush()
+	if c.remote != nil {
+		c.remote.delete(c.key, c.key)
+	}
 	return nil
 }
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// revalidateTimeout bounds a revalidation running in the background
+const revalidateTimeout = 10 * time.Minute
+
+// StaleGetSet gets value from cache and writes to dest, like MutexGetSet does, except that a value is only fresh for
+// the first fresh out of its expire duration. Once a value is no longer fresh but not expired yet, it is still
+// written to dest right away, while valueFunc recomputes it in the background; only a missing value is computed
+// with the caller waiting for it.
+// The first return value means whether the value is got from cache or not. True means calculated; False means got
+// from cache, fresh or stale.
+func (c *Set[T]) StaleGetSet(ctx context.Context, key string, dest *T, valueFunc func(ctx context.Context) (*T, error), fresh time.Duration, expire time.Duration) (bool, error) {
+	expiresAt, err := c.getWithExpiry(key, dest)
+	c.stats.lookup(err == nil)
+	if err != nil {
+		return true, c.slowMutexGetSet(key, dest, func() (*T, error) {
+			return valueFunc(ctx)
+		}, expire)
+	}
+
+	if !expiresAt.IsZero() && time.Until(expiresAt) < expire-fresh {
+		c.stats.stale()
+		go c.revalidateStale(key, valueFunc, expire)
+	}
+	return false, nil
+}
+
+// revalidateStale recomputes the stale value of key, unless it is already being revalidated, either by this instance
+// or by another one holding its lock in the remote tier.
+func (c *Set[T]) revalidateStale(key string, valueFunc func(ctx context.Context) (*T, error), expire time.Duration) {
+	c.revalidatingMu.Lock()
+	if _, ok := c.revalidating[key]; ok {
+		c.revalidatingMu.Unlock()
+		return
+	}
+	done := make(chan struct{})
+	c.revalidating[key] = done
+	c.revalidatingMu.Unlock()
+	defer c.doneRevalidating(key, done)
+
+	if c.remote != nil {
+		release, acquired := c.remote.lock(c.key(key))
+		if !acquired {
+			// the instance holding the lock sets the value, which then gets invalidated here
+			return
+		}
+		defer release()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), revalidateTimeout)
+	defer cancel()
+	if err := c.compute(ctx, key, valueFunc, expire); err != nil {
+		log.Error().Err(err).Str("key", c.key(key)).Msg("failed to revalidate stale cache value")
+	}
+}
+
+// Revalidate computes the value of key and swaps it in, so that readers keep getting the previous value until the
+// new one is ready, instead of waiting on a cold cache as they would after Delete. A revalidation of the stale value
+// already running is waited for first, so that the value computed here is the one that stays.
+func (c *Set[T]) Revalidate(ctx context.Context, key string, valueFunc func(ctx context.Context) (*T, error), expire time.Duration) error {
+	for {
+		c.revalidatingMu.Lock()
+		running, ok := c.revalidating[key]
+		if !ok {
+			break
+		}
+		c.revalidatingMu.Unlock()
+
+		select {
+		case <-running:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	done := make(chan struct{})
+	c.revalidating[key] = done
+	c.revalidatingMu.Unlock()
+	defer c.doneRevalidating(key, done)
+
+	return c.compute(ctx, key, valueFunc, expire)
+}
+
+func (c *Set[T]) doneRevalidating(key string, done chan struct{}) {
+	c.revalidatingMu.Lock()
+	defer c.revalidatingMu.Unlock()
+	delete(c.revalidating, key)
+	close(done)
+}
+
+func (c *Set[T]) compute(ctx context.Context, key string, valueFunc func(ctx context.Context) (*T, error), expire time.Duration) error {
+	started := time.Now()
+	value, err := valueFunc(ctx)
+	c.stats.computed(started, err)
+	if err != nil {
+		return err
+	}
+	c.Set(key, *value, expire)
+	return nil
+}
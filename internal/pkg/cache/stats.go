@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+const (
+	KindSet      = "set"
+	KindSingular = "singular"
+)
+
+// Inspectable is a cache that reports its statistics and lets its entries be peeked at.
+type Inspectable interface {
+	Name() string
+	Stats() Stats
+	// Entries returns the entries held in the local tier whose keys start with prefix, sorted by key and at most
+	// limit of them, along with the total number of matching entries. A limit of 0 or less means no limit.
+	Entries(prefix string, limit int) ([]Entry, int)
+}
+
+// Stats is a snapshot of the statistics of a cache since the process started.
+type Stats struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Items is the number of entries held in the local tier, including expired ones not evicted yet
+	Items int `json:"items"`
+	// Hits and Misses count lookups, by Get, MutexGetSet and StaleGetSet, found or not found in the cache
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	// StaleHits counts the hits, out of Hits, on values no longer fresh, by StaleGetSet
+	StaleHits uint64 `json:"staleHits"`
+	// Computations counts the values computed by MutexGetSet, StaleGetSet and Revalidate, including the failed ones
+	// counted by ComputationErrors
+	Computations      uint64 `json:"computations"`
+	ComputationErrors uint64 `json:"computationErrors"`
+	// ComputationSeconds is the total time spent computing values
+	ComputationSeconds float64 `json:"computationSeconds"`
+}
+
+// Entry is a key held in a cache along with its expiry.
+type Entry struct {
+	Key string `json:"key"`
+	// ExpiresAt is nil if the entry never expires
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// stats counts the usage of a cache; fields are accessed atomically
+type stats struct {
+	hits              uint64
+	misses            uint64
+	staleHits         uint64
+	computations      uint64
+	computationErrors uint64
+	computationNanos  uint64
+}
+
+func (s *stats) lookup(hit bool) {
+	if hit {
+		atomic.AddUint64(&s.hits, 1)
+	} else {
+		atomic.AddUint64(&s.misses, 1)
+	}
+}
+
+func (s *stats) stale() {
+	atomic.AddUint64(&s.staleHits, 1)
+}
+
+func (s *stats) computed(started time.Time, err error) {
+	atomic.AddUint64(&s.computations, 1)
+	atomic.AddUint64(&s.computationNanos, uint64(time.Since(started)))
+	if err != nil {
+		atomic.AddUint64(&s.computationErrors, 1)
+	}
+}
+
+func (s *stats) snapshot(name string, kind string, c *cache.Cache) Stats {
+	return Stats{
+		Name:               name,
+		Kind:               kind,
+		Items:              c.ItemCount(),
+		Hits:               atomic.LoadUint64(&s.hits),
+		Misses:             atomic.LoadUint64(&s.misses),
+		StaleHits:          atomic.LoadUint64(&s.staleHits),
+		Computations:       atomic.LoadUint64(&s.computations),
+		ComputationErrors:  atomic.LoadUint64(&s.computationErrors),
+		ComputationSeconds: time.Duration(atomic.LoadUint64(&s.computationNanos)).Seconds(),
+	}
+}
+
+// entries lists the unexpired entries of c whose keys, with trimPrefix trimmed, start with prefix.
+func entries(c *cache.Cache, trimPrefix string, prefix string, limit int) ([]Entry, int) {
+	now := time.Now().UnixNano()
+	matched := make([]Entry, 0)
+	for key, item := range c.Items() {
+		if item.Expiration > 0 && item.Expiration < now {
+			continue
+		}
+		key = strings.TrimPrefix(key, trimPrefix)
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		entry := Entry{Key: key}
+		if item.Expiration > 0 {
+			expiresAt := time.Unix(0, item.Expiration)
+			entry.ExpiresAt = &expiresAt
+		}
+		matched = append(matched, entry)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].Key < matched[j].Key
+	})
+
+	total := len(matched)
+	if limit > 0 && total > limit {
+		matched = matched[:limit]
+	}
+	return matched, total
+}
+
+var registry = struct {
+	sync.RWMutex
+	caches map[string]Inspectable
+}{
+	caches: make(map[string]Inspectable),
+}
+
+func register(c Inspectable) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.caches[c.Name()] = c
+}
+
+// All returns every cache created in this process, sorted by name.
+func All() []Inspectable {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	caches := make([]Inspectable, 0, len(registry.caches))
+	for _, c := range registry.caches {
+		caches = append(caches, c)
+	}
+	sort.Slice(caches, func(i, j int) bool {
+		return caches[i].Name() < caches[j].Name()
+	})
+	return caches
+}
+
+// Lookup returns the cache of the given name, if created in this process.
+func Lookup(name string) (Inspectable, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	c, ok := registry.caches[name]
+	return c, ok
+}
@@ -0,0 +1,267 @@
+package cachectrl
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/zeebo/xxh3"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/penguin-statistics/backend-next/internal/pkg/cache"
+)
+
+const (
+	MIMEApplicationProtobuf = "application/x-protobuf"
+	MIMEApplicationMsgpack  = "application/msgpack"
+
+	EncodingBrotli   = "br"
+	EncodingGzip     = "gzip"
+	EncodingIdentity = "identity"
+
+	// responses are compressed once per last modified time, so a higher level than the on-the-fly default is affordable
+	brotliLevel = 6
+	gzipLevel   = fasthttp.CompressBestCompression
+
+	// responses smaller than this are not worth compressing
+	compressMinSize = 1024
+)
+
+// Negotiable is implemented by response values that could also be served as protobuf and msgpack,
+// in addition to JSON.
+type Negotiable interface {
+	ToProtobuf() proto.Message
+}
+
+// encodedResponse is a memoized response body in one representation along with its strong ETag, valid
+// as long as the value it is encoded from has the same last modified time.
+type encodedResponse struct {
+	LastModified time.Time
+	ETag         string
+	// Encoding is the content encoding Body is compressed with, or empty if it is not compressed
+	Encoding string
+	Body     []byte
+}
+
+// encodedResponses is created on package initialization, before the remote cache tier could be enabled, and is
+// therefore always local: every instance encodes the responses it serves by itself.
+var encodedResponses = cache.NewSet[encodedResponse]("cachectrl#encodedResponse")
+
+// Respond opts the response in for caching, and writes value with a strong ETag in the representation
+// negotiated with the client: values implementing Negotiable could be served as protobuf or msgpack
+// besides JSON, and every format could be compressed with Brotli or gzip. Each representation is
+// memoized under key and reused until lastModified changes, so that clients polling a large result
+// neither make the server re-encode it nor re-download it: conditional requests matching the current
+// representation are answered with 304 Not Modified.
+//
+// key must uniquely identify the value among all values responded with, e.g. the key used to cache
+// the value itself. A zero lastModified means the last modified time is unknown, in which case the value is
+// encoded for this response only, as of now.
+func Respond(ctx *fiber.Ctx, key string, lastModified time.Time, value any) error {
+	format := acceptsFormat(ctx, value)
+	encoding := acceptsEncoding(ctx)
+
+	var encoded encodedResponse
+	var err error
+	if lastModified.IsZero() {
+		lastModified = time.Now()
+		encoded, err = encodeResponse(ctx, value, format, encoding)
+	} else {
+		encoded, err = getEncodedResponse(ctx, key, lastModified, value, format, encoding)
+	}
+	if err != nil {
+		return err
+	}
+
+	OptIn(ctx, lastModified)
+	ctx.Set(fiber.HeaderETag, encoded.ETag)
+
+	if notModified(ctx, encoded.ETag, lastModified) {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	ctx.Set(fiber.HeaderContentType, format)
+	if encoded.Encoding != "" {
+		ctx.Set(fiber.HeaderContentEncoding, encoded.Encoding)
+	}
+	return ctx.Send(encoded.Body)
+}
+
+// Send writes value in the representation negotiated with the client in the same way as Respond, but neither
+// opts the response in for caching nor memoizes it. It is meant for values specific to the request, such as
+// personal or filtered results.
+func Send(ctx *fiber.Ctx, value any) error {
+	format := acceptsFormat(ctx, value)
+	encoding := acceptsEncoding(ctx)
+
+	encoded, err := encodeResponse(ctx, value, format, encoding)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, format)
+	if encoded.Encoding != "" {
+		ctx.Set(fiber.HeaderContentEncoding, encoded.Encoding)
+	}
+	return ctx.Send(encoded.Body)
+}
+
+// getEncodedResponse returns the memoized representation of value, encoding it if the memoized one is
+// missing or stale. Compressed representations are derived from the uncompressed one of the same format.
+func getEncodedResponse(ctx *fiber.Ctx, key string, lastModified time.Time, value any, format, encoding string) (encodedResponse, error) {
+	memoKey := key + "#format:" + format + "#encoding:" + encoding
+
+	var encoded encodedResponse
+	if err := encodedResponses.Get(memoKey, &encoded); err == nil && encoded.LastModified.Equal(lastModified) {
+		return encoded, nil
+	}
+
+	if encoding == EncodingIdentity {
+		body, err := encode(ctx, value, format)
+		if err != nil {
+			return encodedResponse{}, err
+		}
+		encoded = encodedResponse{Body: body}
+	} else {
+		identity, err := getEncodedResponse(ctx, key, lastModified, value, format, EncodingIdentity)
+		if err != nil {
+			return encodedResponse{}, err
+		}
+		if len(identity.Body) < compressMinSize {
+			// not worth compressing; share the uncompressed representation
+			return identity, nil
+		}
+		encoded = encodedResponse{Encoding: encoding, Body: compress(identity.Body, encoding)}
+	}
+	encoded.LastModified = lastModified
+	encoded.ETag = etagOf(encoded.Body)
+
+	encodedResponses.Set(memoKey, encoded, 24*time.Hour)
+	return encoded, nil
+}
+
+// encodeResponse encodes value without memoizing it.
+func encodeResponse(ctx *fiber.Ctx, value any, format, encoding string) (encodedResponse, error) {
+	body, err := encode(ctx, value, format)
+	if err != nil {
+		return encodedResponse{}, err
+	}
+	encoded := encodedResponse{Body: body}
+	if encoding != EncodingIdentity && len(body) >= compressMinSize {
+		encoded.Encoding = encoding
+		encoded.Body = compress(body, encoding)
+	}
+	encoded.ETag = etagOf(encoded.Body)
+	return encoded, nil
+}
+
+func etagOf(body []byte) string {
+	return `"` + strconv.FormatUint(xxh3.Hash(body), 16) + `"`
+}
+
+func encode(ctx *fiber.Ctx, value any, format string) ([]byte, error) {
+	switch format {
+	case MIMEApplicationProtobuf:
+		return proto.Marshal(value.(Negotiable).ToProtobuf())
+	case MIMEApplicationMsgpack:
+		var buf bytes.Buffer
+		enc := msgpack.NewEncoder(&buf)
+		// use the same field names as JSON
+		enc.SetCustomStructTag("json")
+		enc.UseCompactInts(true)
+		if err := enc.Encode(value); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return ctx.App().Config().JSONEncoder(value)
+	}
+}
+
+func compress(body []byte, encoding string) []byte {
+	switch encoding {
+	case EncodingBrotli:
+		return fasthttp.AppendBrotliBytesLevel(nil, body, brotliLevel)
+	case EncodingGzip:
+		return fasthttp.AppendGzipBytesLevel(nil, body, gzipLevel)
+	default:
+		return body
+	}
+}
+
+// acceptsFormat negotiates the format from the Accept header. Values not implementing Negotiable are always
+// served as JSON.
+func acceptsFormat(ctx *fiber.Ctx, value any) string {
+	if _, ok := value.(Negotiable); !ok {
+		return fiber.MIMEApplicationJSON
+	}
+	ctx.Vary(fiber.HeaderAccept)
+	format := ctx.Accepts(fiber.MIMEApplicationJSON, MIMEApplicationProtobuf, MIMEApplicationMsgpack)
+	if format == "" {
+		// be lenient on clients with unsatisfiable Accept headers, as JSON has always been served regardless
+		format = fiber.MIMEApplicationJSON
+	}
+	return format
+}
+
+// acceptsEncoding negotiates the content encoding from the Accept-Encoding header, preferring Brotli
+// over gzip regardless of the order the client listed them in.
+func acceptsEncoding(ctx *fiber.Ctx) string {
+	ctx.Vary(fiber.HeaderAcceptEncoding)
+	var br, gzip bool
+	for _, spec := range strings.Split(ctx.Get(fiber.HeaderAcceptEncoding), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(spec), ";")
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			if q, err := strconv.ParseFloat(params[2:], 64); err == nil && q == 0 {
+				continue
+			}
+		}
+		switch strings.TrimSpace(name) {
+		case EncodingBrotli:
+			br = true
+		case EncodingGzip:
+			gzip = true
+		case "*":
+			br, gzip = true, true
+		}
+	}
+
+	switch {
+	case br:
+		return EncodingBrotli
+	case gzip:
+		return EncodingGzip
+	default:
+		return EncodingIdentity
+	}
+}
+
+// notModified evaluates the conditional request headers as per RFC 7232, section 6: If-None-Match takes
+// precedence, and If-Modified-Since is only evaluated when If-None-Match is absent.
+func notModified(ctx *fiber.Ctx, etag string, lastModified time.Time) bool {
+	if noneMatch := ctx.Get(fiber.HeaderIfNoneMatch); noneMatch != "" {
+		for _, candidate := range strings.Split(noneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if modifiedSince := ctx.Get(fiber.HeaderIfModifiedSince); modifiedSince != "" {
+		t, err := http.ParseTime(modifiedSince)
+		if err != nil {
+			return false
+		}
+		// Last-Modified only has a precision of seconds
+		return !lastModified.Truncate(time.Second).After(t)
+	}
+
+	return false
+}
@@ -2,9 +2,9 @@ package flake
 
 import "github.com/bwmarrin/snowflake"
 
-func New() (*snowflake.Node, error) {
+// New creates the snowflake node of this instance, with the node ID it leased.
+func New(lease *NodeLease) (*snowflake.Node, error) {
 	snowflake.Epoch = 1558326573939 // 初音ミク最高です！！！ :D
 
-	// TODO: change 0 to the actual node ID
-	return snowflake.NewNode(0)
+	return snowflake.NewNode(lease.Node())
 }
@@ -0,0 +1,184 @@
+package flake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// renewScript extends the lease only if it is still held by the given ID
+	renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	// releaseScript deletes the lease only if it is still held by the given ID
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// ErrNoNodeAvailable is returned when every node ID is leased by other instances
+var ErrNoNodeAvailable = errors.New("no snowflake node ID available: every node ID is leased by another instance")
+
+// ErrLeaseExpiring is returned by Run when the lease could not be renewed before it expires, after which another
+// instance may lease the node ID
+var ErrLeaseExpiring = errors.New("snowflake node lease could not be renewed before expiring")
+
+// NodeLease is the lease of a snowflake node ID held by this instance in Redis, so that no two instances generate
+// IDs with the same node ID. The lease is a key per node ID, whose value is the ID of the instance holding it, and
+// which expires unless renewed.
+type NodeLease struct {
+	client    *redis.Client
+	keyPrefix string
+	holder    string
+	ttl       time.Duration
+	node      int64
+
+	// expiresAt is when the lease expires at the earliest unless renewed, as of the last acquisition or renewal
+	expiresAt time.Time
+}
+
+// LeaseNode leases the given node ID, failing if it is leased by another instance already.
+func LeaseNode(ctx context.Context, client *redis.Client, keyPrefix string, holder string, node int64, ttl time.Duration) (*NodeLease, error) {
+	if node < 0 || node > maxNode() {
+		return nil, fmt.Errorf("snowflake node ID must be between 0 and %d, got %d", maxNode(), node)
+	}
+	l := &NodeLease{client: client, keyPrefix: keyPrefix, holder: holder, ttl: ttl, node: node}
+	acquired, err := l.acquire(ctx, node)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		current, err := client.Get(ctx, l.key(node)).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("snowflake node ID %d is already leased by instance %q", node, current)
+	}
+	return l, nil
+}
+
+// LeaseAnyNode leases the first node ID not leased by another instance, starting from one derived from holder so
+// that instances starting at the same time rarely race for the same node ID.
+func LeaseAnyNode(ctx context.Context, client *redis.Client, keyPrefix string, holder string, ttl time.Duration) (*NodeLease, error) {
+	l := &NodeLease{client: client, keyPrefix: keyPrefix, holder: holder, ttl: ttl}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(holder))
+	count := maxNode() + 1
+	start := int64(h.Sum32()) % count
+	for i := int64(0); i < count; i++ {
+		node := (start + i) % count
+		acquired, err := l.acquire(ctx, node)
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			l.node = node
+			return l, nil
+		}
+	}
+	return nil, ErrNoNodeAvailable
+}
+
+// Node returns the leased node ID.
+func (l *NodeLease) Node() int64 {
+	return l.node
+}
+
+// Run renews the lease until ctx is done, at which point the lease is released. It returns an error if the lease
+// has been taken over by another instance, or if it could not be renewed and would expire before the next attempt,
+// as IDs generated with the node ID may collide with the ones of another instance leasing it afterwards.
+func (l *NodeLease) Run(ctx context.Context) error {
+	interval := l.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			l.release()
+			return nil
+		case <-ticker.C:
+		}
+
+		if err := l.renew(ctx); err != nil {
+			return err
+		}
+		if time.Until(l.expiresAt) <= interval {
+			return ErrLeaseExpiring
+		}
+	}
+}
+
+// renew extends the lease. Failures to reach redis are only logged, as the lease may still be renewed in time once
+// redis is reachable again; Run gives up once the lease is about to expire.
+func (l *NodeLease) renew(ctx context.Context) error {
+	timeout := l.ttl / 3
+	if remaining := time.Until(l.expiresAt); remaining < timeout {
+		timeout = remaining
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	sentAt := time.Now()
+	renewed, err := renewScript.Run(ctx, l.client, []string{l.key(l.node)}, l.holder, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		log.Warn().Err(err).Int64("node", l.node).Time("expiresAt", l.expiresAt).Msg("failed to renew snowflake node lease")
+		return nil
+	}
+	if renewed == 1 {
+		l.expiresAt = sentAt.Add(l.ttl)
+		return nil
+	}
+
+	// the lease is gone although it should not have expired yet, e.g. because it has been deleted: take it again
+	// unless another instance already did
+	acquired, err := l.acquire(ctx, l.node)
+	if err != nil {
+		log.Warn().Err(err).Int64("node", l.node).Msg("failed to re-acquire lost snowflake node lease")
+		return nil
+	}
+	if !acquired {
+		return fmt.Errorf("snowflake node ID %d has been leased by another instance", l.node)
+	}
+	log.Warn().Int64("node", l.node).Msg("snowflake node lease was lost and has been re-acquired")
+	return nil
+}
+
+func (l *NodeLease) acquire(ctx context.Context, node int64) (bool, error) {
+	sentAt := time.Now()
+	acquired, err := l.client.SetNX(ctx, l.key(node), l.holder, l.ttl).Result()
+	if acquired {
+		l.expiresAt = sentAt.Add(l.ttl)
+	}
+	return acquired, err
+}
+
+func (l *NodeLease) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := releaseScript.Run(ctx, l.client, []string{l.key(l.node)}, l.holder).Err(); err != nil {
+		log.Warn().Err(err).Int64("node", l.node).Msg("failed to release snowflake node lease")
+	}
+}
+
+func (l *NodeLease) key(node int64) string {
+	return l.keyPrefix + strconv.FormatInt(node, 10)
+}
+
+func maxNode() int64 {
+	return -1 ^ (-1 << snowflake.NodeBits)
+}
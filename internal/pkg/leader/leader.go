@@ -0,0 +1,155 @@
+// Package leader implements a lease-based leader election on top of Redis. The leader holds a key whose value is its
+// ID and keeps extending its expiry; when the leader stops renewing, e.g. because it crashed or lost connection,
+// the key expires and another candidate acquires it.
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// renewScript extends the lease only if it is still held by the given ID
+	renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	// releaseScript deletes the lease only if it is still held by the given ID
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+type Elector struct {
+	client *redis.Client
+	key    string
+	id     string
+	ttl    time.Duration
+
+	mu sync.RWMutex
+	// leaderCtx is valid, and cancelled as soon as leadership is lost, only while this instance is the leader
+	leaderCtx    context.Context
+	leaderCancel context.CancelFunc
+}
+
+// New creates an elector campaigning for the lease stored at key, identifying itself as id. The lease expires if
+// not renewed within ttl; it is renewed every third of ttl.
+func New(client *redis.Client, key string, id string, ttl time.Duration) *Elector {
+	return &Elector{
+		client: client,
+		key:    key,
+		id:     id,
+		ttl:    ttl,
+	}
+}
+
+// ID returns the ID this instance campaigns with.
+func (e *Elector) ID() string {
+	return e.id
+}
+
+// Run campaigns for leadership until ctx is done, at which point the lease is released if held.
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		e.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			e.resign()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Elector) tick(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, e.ttl/3)
+	defer cancel()
+
+	if e.IsLeader() {
+		renewed, err := renewScript.Run(ctx, e.client, []string{e.key}, e.id, e.ttl.Milliseconds()).Int()
+		if err != nil || renewed == 0 {
+			// step down right away rather than waiting for the lease to expire: another instance may take over
+			// as soon as it does, and two leaders must never run at the same time
+			log.Warn().Err(err).Str("id", e.id).Msg("leader lost its lease")
+			e.setLeader(false)
+		}
+		return
+	}
+
+	acquired, err := e.client.SetNX(ctx, e.key, e.id, e.ttl).Result()
+	if err != nil {
+		log.Warn().Err(err).Str("id", e.id).Msg("leader election failed to reach redis")
+		return
+	}
+	if acquired {
+		log.Info().Str("id", e.id).Msg("elected as leader")
+		e.setLeader(true)
+	}
+}
+
+func (e *Elector) resign() {
+	if !e.IsLeader() {
+		return
+	}
+	e.setLeader(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := releaseScript.Run(ctx, e.client, []string{e.key}, e.id).Err(); err != nil {
+		log.Warn().Err(err).Str("id", e.id).Msg("leader failed to release its lease")
+		return
+	}
+	log.Info().Str("id", e.id).Msg("leader resigned")
+}
+
+func (e *Elector) setLeader(leader bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if leader && e.leaderCtx == nil {
+		e.leaderCtx, e.leaderCancel = context.WithCancel(context.Background())
+	} else if !leader && e.leaderCtx != nil {
+		e.leaderCancel()
+		e.leaderCtx, e.leaderCancel = nil, nil
+	}
+}
+
+// IsLeader reports whether this instance currently holds the lease.
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leaderCtx != nil
+}
+
+// LeaderContext returns a context that is cancelled as soon as this instance loses leadership, and whether this
+// instance currently is the leader at all.
+func (e *Elector) LeaderContext() (context.Context, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.leaderCtx == nil {
+		return nil, false
+	}
+	return e.leaderCtx, true
+}
+
+// Leader returns the ID of the current leader, or an empty string if there is none.
+func (e *Elector) Leader(ctx context.Context) (string, error) {
+	id, err := e.client.Get(ctx, e.key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return id, err
+}
@@ -0,0 +1,273 @@
+package lp
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// eps is the tolerance used when comparing floating point values in the tableau
+	eps = 1e-9
+
+	// feasibilityEps is the tolerance of the phase one objective for a problem to be considered feasible
+	feasibilityEps = 1e-7
+
+	maxIterations = 100000
+)
+
+var (
+	ErrInfeasible     = errors.New("lp: problem is infeasible")
+	ErrUnbounded      = errors.New("lp: problem is unbounded")
+	ErrIterationLimit = errors.New("lp: iteration limit reached")
+	ErrMalformed      = errors.New("lp: malformed problem")
+)
+
+// Problem describes a linear program in the form of
+//
+//	minimize    Objective · x
+//	subject to  Constraints · x >= Bounds
+//	            x >= 0
+type Problem struct {
+	Objective   []float64
+	Constraints [][]float64
+	Bounds      []float64
+}
+
+// Solution is the optimal solution of a Problem.
+type Solution struct {
+	// X is the value of each variable, in the same order as Problem.Objective.
+	X []float64
+	// Objective is the optimal objective value.
+	Objective float64
+}
+
+// Minimize solves the given Problem using the two-phase simplex method with Bland's rule,
+// which guarantees termination on degenerate problems at the cost of some speed. It is intended
+// for the small and dense problems arising from the planner, rather than for general purpose use.
+func Minimize(p *Problem) (*Solution, error) {
+	n := len(p.Objective)
+	m := len(p.Constraints)
+	if len(p.Bounds) != m {
+		return nil, ErrMalformed
+	}
+	for _, row := range p.Constraints {
+		if len(row) != n {
+			return nil, ErrMalformed
+		}
+	}
+
+	t := newTableau(p)
+
+	// phase one: minimize the sum of artificial variables to find a basic feasible solution
+	t.setObjective(func(col int) float64 {
+		if col >= t.artStart {
+			return 1
+		}
+		return 0
+	})
+	if err := t.optimize(t.cols); err != nil {
+		return nil, err
+	}
+	if -t.objective()[t.cols] > feasibilityEps {
+		return nil, ErrInfeasible
+	}
+	t.driveOutArtificials()
+
+	// phase two: minimize the actual objective, never letting artificial variables back into the basis
+	t.setObjective(func(col int) float64 {
+		if col < n {
+			return p.Objective[col]
+		}
+		return 0
+	})
+	if err := t.optimize(t.artStart); err != nil {
+		return nil, err
+	}
+
+	x := make([]float64, n)
+	for row, col := range t.basis {
+		if col < n {
+			x[col] = math.Max(t.a[row][t.cols], 0)
+		}
+	}
+	objective := 0.0
+	for j, c := range p.Objective {
+		objective += c * x[j]
+	}
+
+	return &Solution{
+		X:         x,
+		Objective: objective,
+	}, nil
+}
+
+type tableau struct {
+	// a has m+1 rows and cols+1 columns. The last row is the objective row holding reduced costs,
+	// and the last column is the right-hand side.
+	a [][]float64
+
+	m    int
+	cols int
+
+	// artStart is the index of the first artificial column; every column after it is artificial as well
+	artStart int
+
+	// basis holds the index of the basic column for each row
+	basis []int
+}
+
+// newTableau builds the initial tableau. Each constraint `a·x >= b` is turned into an equality
+// with a surplus variable; rows with a non-negative bound get an artificial variable as their
+// initial basic variable, whereas rows with a negative bound are negated so that their slack
+// variable can be used as the initial basic variable directly.
+func newTableau(p *Problem) *tableau {
+	n := len(p.Objective)
+	m := len(p.Constraints)
+
+	artificials := 0
+	for _, b := range p.Bounds {
+		if b >= 0 {
+			artificials++
+		}
+	}
+
+	t := &tableau{
+		m:        m,
+		cols:     n + m + artificials,
+		artStart: n + m,
+		basis:    make([]int, m),
+	}
+	t.a = make([][]float64, m+1)
+	for i := range t.a {
+		t.a[i] = make([]float64, t.cols+1)
+	}
+
+	art := t.artStart
+	for i, row := range p.Constraints {
+		sign := 1.0
+		if p.Bounds[i] < 0 {
+			sign = -1.0
+		}
+		for j, v := range row {
+			t.a[i][j] = sign * v
+		}
+		t.a[i][n+i] = -sign
+		t.a[i][t.cols] = sign * p.Bounds[i]
+
+		if sign > 0 {
+			t.a[i][art] = 1
+			t.basis[i] = art
+			art++
+		} else {
+			t.basis[i] = n + i
+		}
+	}
+
+	return t
+}
+
+func (t *tableau) objective() []float64 {
+	return t.a[t.m]
+}
+
+// setObjective resets the objective row with the given costs and expresses it in terms of the
+// current non-basic variables.
+func (t *tableau) setObjective(cost func(col int) float64) {
+	obj := t.objective()
+	for j := 0; j < t.cols; j++ {
+		obj[j] = cost(j)
+	}
+	obj[t.cols] = 0
+
+	for i, col := range t.basis {
+		c := obj[col]
+		if c == 0 {
+			continue
+		}
+		for j, v := range t.a[i] {
+			obj[j] -= c * v
+		}
+	}
+}
+
+func (t *tableau) pivot(row, col int) {
+	pr := t.a[row]
+	pv := pr[col]
+	for j := range pr {
+		pr[j] /= pv
+	}
+
+	for i, ri := range t.a {
+		if i == row {
+			continue
+		}
+		f := ri[col]
+		if f == 0 {
+			continue
+		}
+		for j := range ri {
+			ri[j] -= f * pr[j]
+		}
+	}
+
+	t.basis[row] = col
+}
+
+// optimize runs simplex iterations until the objective row has no negative reduced cost within
+// the first `allowed` columns.
+func (t *tableau) optimize(allowed int) error {
+	obj := t.objective()
+
+	for iter := 0; iter < maxIterations; iter++ {
+		// Bland's rule: the entering variable is the lowest-indexed one with a negative reduced cost
+		col := -1
+		for j := 0; j < allowed; j++ {
+			if obj[j] < -eps {
+				col = j
+				break
+			}
+		}
+		if col < 0 {
+			return nil
+		}
+
+		// ... and the leaving variable is the lowest-indexed one among those tied in the ratio test
+		row := -1
+		best := 0.0
+		for i := 0; i < t.m; i++ {
+			v := t.a[i][col]
+			if v <= eps {
+				continue
+			}
+			ratio := t.a[i][t.cols] / v
+			if row < 0 || ratio < best-eps || (ratio < best+eps && t.basis[i] < t.basis[row]) {
+				row = i
+				best = ratio
+			}
+		}
+		if row < 0 {
+			return ErrUnbounded
+		}
+
+		t.pivot(row, col)
+	}
+
+	return ErrIterationLimit
+}
+
+// driveOutArtificials pivots artificial variables that remain basic (at level zero) after phase one
+// out of the basis. Rows where that is impossible are redundant and are left as is.
+func (t *tableau) driveOutArtificials() {
+	for i, col := range t.basis {
+		if col < t.artStart {
+			continue
+		}
+		for j := 0; j < t.artStart; j++ {
+			if math.Abs(t.a[i][j]) > eps {
+				t.pivot(i, j)
+				break
+			}
+		}
+	}
+}
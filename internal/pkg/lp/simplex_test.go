@@ -0,0 +1,74 @@
+package lp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestMinimize(t *testing.T) {
+	// minimize x + y, subject to x + 2y >= 4, 3x + y >= 6
+	s, err := Minimize(&Problem{
+		Objective: []float64{1, 1},
+		Constraints: [][]float64{
+			{1, 2},
+			{3, 1},
+		},
+		Bounds: []float64{4, 6},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !approxEqual(s.X[0], 1.6) || !approxEqual(s.X[1], 1.2) {
+		t.Errorf("expected x = [1.6 1.2], got %v", s.X)
+	}
+	if !approxEqual(s.Objective, 2.8) {
+		t.Errorf("expected objective 2.8, got %f", s.Objective)
+	}
+}
+
+func TestMinimizeNegativeBounds(t *testing.T) {
+	// minimize 2x + y, subject to x - y >= -1 (i.e. y <= x + 1), y >= 3
+	s, err := Minimize(&Problem{
+		Objective: []float64{2, 1},
+		Constraints: [][]float64{
+			{1, -1},
+			{0, 1},
+		},
+		Bounds: []float64{-1, 3},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !approxEqual(s.X[0], 2) || !approxEqual(s.X[1], 3) {
+		t.Errorf("expected x = [2 3], got %v", s.X)
+	}
+}
+
+func TestMinimizeInfeasible(t *testing.T) {
+	// x >= 1 and -x >= 0 cannot hold at the same time
+	_, err := Minimize(&Problem{
+		Objective:   []float64{1},
+		Constraints: [][]float64{{1}, {-1}},
+		Bounds:      []float64{1, 0},
+	})
+	if !errors.Is(err, ErrInfeasible) {
+		t.Errorf("expected ErrInfeasible, got %v", err)
+	}
+}
+
+func TestMinimizeUnbounded(t *testing.T) {
+	_, err := Minimize(&Problem{
+		Objective:   []float64{-1},
+		Constraints: [][]float64{{1}},
+		Bounds:      []float64{1},
+	})
+	if !errors.Is(err, ErrUnbounded) {
+		t.Errorf("expected ErrUnbounded, got %v", err)
+	}
+}
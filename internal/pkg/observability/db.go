@@ -0,0 +1,92 @@
+package observability
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// repoPackage is how functions of the repo package are named in stack frames
+const repoPackage = "/internal/repo."
+
+var repoMethodReplacer = strings.NewReplacer("(*", "", ")", "")
+
+// QueryHook records the duration of every query, labelled by the repo method issuing it, which is found by walking
+// up the stack from the hook, as bun calls it synchronously on the goroutine running the query, and by the
+// connection pool serving it. Every query is also wrapped in a span named after the repo method, so that the
+// queries of a trace are attributed to repo calls.
+type QueryHook struct {
+	pool string
+}
+
+var _ bun.QueryHook = (*QueryHook)(nil)
+
+type queryHookCtxKey struct{}
+
+// queryState is carried from BeforeQuery to AfterQuery in the query context
+type queryState struct {
+	method string
+	span   trace.Span
+}
+
+// NewQueryHook creates the hook of the database reached through the given pool, e.g. pgreplica.PoolPrimary.
+func NewQueryHook(pool string) *QueryHook {
+	return &QueryHook{pool: pool}
+}
+
+func (h *QueryHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	method := repoMethod()
+	ctx, span := Tracer.Start(ctx, "repo."+method, trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationKey.String(event.Operation()),
+		attribute.String("db.pool", h.pool),
+	))
+	return context.WithValue(ctx, queryHookCtxKey{}, &queryState{method: method, span: span})
+}
+
+func (h *QueryHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	state, ok := ctx.Value(queryHookCtxKey{}).(*queryState)
+	if !ok {
+		state = &queryState{method: repoMethod(), span: trace.SpanFromContext(context.Background())}
+	}
+	operation := event.Operation()
+
+	DBQueryDuration.WithLabelValues(state.method, operation, h.pool).Observe(time.Since(event.StartTime).Seconds())
+	if event.Err != nil && !errors.Is(event.Err, sql.ErrNoRows) {
+		DBQueryErrors.WithLabelValues(state.method, operation, h.pool).Inc()
+		state.span.RecordError(event.Err)
+		state.span.SetStatus(codes.Error, event.Err.Error())
+	}
+	state.span.End()
+}
+
+// repoMethod returns the innermost repo method on the stack, e.g. DropReport.CalcTotalQuantity, or "other" if the
+// query is not issued by a repo.
+func repoMethod() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if i := strings.Index(frame.Function, repoPackage); i >= 0 {
+			parts := strings.Split(repoMethodReplacer.Replace(frame.Function[i+len(repoPackage):]), ".")
+			if len(parts) > 2 {
+				// drop the suffix of closures, e.g. .func1
+				parts = parts[:2]
+			}
+			return strings.Join(parts, ".")
+		}
+		if !more {
+			return "other"
+		}
+	}
+}
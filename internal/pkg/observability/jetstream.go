@@ -0,0 +1,70 @@
+package observability
+
+import (
+	"context"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// jetStreamScrapeTimeout bounds the requests made to NATS on every scrape
+const jetStreamScrapeTimeout = 2 * time.Second
+
+// JetStreamCollector exports the state of a JetStream stream and one of its consumers, as reported by NATS at the
+// time of scraping.
+type JetStreamCollector struct {
+	js       nats.JetStreamContext
+	stream   string
+	consumer string
+
+	streamMessages      *prometheus.Desc
+	consumerPending     *prometheus.Desc
+	consumerAckPending  *prometheus.Desc
+	consumerRedelivered *prometheus.Desc
+}
+
+func NewJetStreamCollector(js nats.JetStreamContext, stream string, consumer string) *JetStreamCollector {
+	return &JetStreamCollector{
+		js:       js,
+		stream:   stream,
+		consumer: consumer,
+		streamMessages: prometheus.NewDesc(prometheus.BuildFQName(ServiceName, "jetstream", "stream_messages"),
+			"Number of messages stored in the stream", []string{"stream"}, nil),
+		consumerPending: prometheus.NewDesc(prometheus.BuildFQName(ServiceName, "jetstream", "consumer_pending_messages"),
+			"Number of messages not delivered to the consumer yet", []string{"stream", "consumer"}, nil),
+		consumerAckPending: prometheus.NewDesc(prometheus.BuildFQName(ServiceName, "jetstream", "consumer_ack_pending_messages"),
+			"Number of messages delivered to the consumer but not acknowledged yet", []string{"stream", "consumer"}, nil),
+		consumerRedelivered: prometheus.NewDesc(prometheus.BuildFQName(ServiceName, "jetstream", "consumer_redelivered_messages"),
+			"Number of messages redelivered to the consumer and not acknowledged yet", []string{"stream", "consumer"}, nil),
+	}
+}
+
+func (c *JetStreamCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.streamMessages
+	ch <- c.consumerPending
+	ch <- c.consumerAckPending
+	ch <- c.consumerRedelivered
+}
+
+func (c *JetStreamCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), jetStreamScrapeTimeout)
+	defer cancel()
+
+	stream, err := c.js.StreamInfo(c.stream, nats.Context(ctx))
+	if err != nil {
+		log.Warn().Err(err).Str("stream", c.stream).Msg("failed to get jetstream stream info for metrics")
+	} else {
+		ch <- prometheus.MustNewConstMetric(c.streamMessages, prometheus.GaugeValue, float64(stream.State.Msgs), c.stream)
+	}
+
+	consumer, err := c.js.ConsumerInfo(c.stream, c.consumer, nats.Context(ctx))
+	if err != nil {
+		log.Warn().Err(err).Str("stream", c.stream).Str("consumer", c.consumer).Msg("failed to get jetstream consumer info for metrics")
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.consumerPending, prometheus.GaugeValue, float64(consumer.NumPending), c.stream, c.consumer)
+	ch <- prometheus.MustNewConstMetric(c.consumerAckPending, prometheus.GaugeValue, float64(consumer.NumAckPending), c.stream, c.consumer)
+	ch <- prometheus.MustNewConstMetric(c.consumerRedelivered, prometheus.GaugeValue, float64(consumer.NumRedelivered), c.stream, c.consumer)
+}
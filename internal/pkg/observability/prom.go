@@ -1,11 +1,26 @@
 package observability
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/samber/lo"
+	"github.com/uptrace/bun"
+
+	"github.com/penguin-statistics/backend-next/internal/constant"
+	"github.com/penguin-statistics/backend-next/internal/pkg/cache"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgreplica"
+)
 
 const (
 	ServiceName = "penguinbackend"
 )
 
+const (
+	ReportPhasePublish = "publish"
+	ReportPhaseConsume = "consume"
+)
+
 var (
 	ReportVerifyDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Name:    prometheus.BuildFQName(ServiceName, "report", "verify_duration_seconds"),
@@ -16,10 +31,90 @@ var (
 		Name:    prometheus.BuildFQName(ServiceName, "report", "consume_duration_seconds"),
 		Help:    "Duration of report consumption in seconds",
 		Buckets: prometheus.ExponentialBuckets(0.01, 2, 10),
-	}, []string{})
+	}, []string{"server", "source_category"})
+	ReportPublishDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    prometheus.BuildFQName(ServiceName, "report", "publish_duration_seconds"),
+		Help:    "Duration of publishing a report task to NATS until acknowledged in seconds",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 12),
+	}, []string{"subject"})
+	ReportQueued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: prometheus.BuildFQName(ServiceName, "report", "queued_total"),
+		Help: "Number of reports queued for consumption",
+	}, []string{"server", "source_category"})
+	ReportPersisted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: prometheus.BuildFQName(ServiceName, "report", "persisted_total"),
+		Help: "Number of reports persisted to the database",
+	}, []string{"server", "source_category"})
+	ReportFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: prometheus.BuildFQName(ServiceName, "report", "failed_total"),
+		Help: "Number of reports failed to be queued or consumed",
+	}, []string{"server", "source_category", "phase"})
+	ReportRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: prometheus.BuildFQName(ServiceName, "report", "rejected_total"),
+		Help: "Number of reports rejected by a verifier, by the reliability assigned to them",
+	}, []string{"verifier", "reliability"})
+
+	WorkerJobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    prometheus.BuildFQName(ServiceName, "worker", "job_duration_seconds"),
+		Help:    "Duration of calculation worker job runs in seconds, including retries",
+		Buckets: prometheus.ExponentialBuckets(0.5, 2, 12),
+	}, []string{"job", "server", "source_category"})
+	WorkerJobFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: prometheus.BuildFQName(ServiceName, "worker", "job_failures_total"),
+		Help: "Number of calculation worker job runs failed after all retries",
+	}, []string{"job", "server", "source_category"})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    prometheus.BuildFQName(ServiceName, "db", "query_duration_seconds"),
+		Help:    "Duration of database queries in seconds, by the repo method issuing them and the pool serving them",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"method", "operation", "pool"})
+	DBQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: prometheus.BuildFQName(ServiceName, "db", "query_errors_total"),
+		Help: "Number of database queries failed, by the repo method issuing them and the pool serving them",
+	}, []string{"method", "operation", "pool"})
 )
 
-func Launch() {
+// SourceCategory maps the source of a report to its source category, to be used as a label in place of the source
+// itself, which is free-form.
+func SourceCategory(source string) string {
+	if lo.Contains(constant.ManualSources, source) {
+		return constant.SourceCategoryManual
+	}
+	return constant.SourceCategoryAutomated
+}
+
+func Launch(db *bun.DB, replica *pgreplica.Router, js nats.JetStreamContext) {
 	prometheus.MustRegister(ReportVerifyDuration)
 	prometheus.MustRegister(ReportConsumeDuration)
+	prometheus.MustRegister(ReportPublishDuration)
+	prometheus.MustRegister(ReportQueued)
+	prometheus.MustRegister(ReportPersisted)
+	prometheus.MustRegister(ReportFailed)
+	prometheus.MustRegister(ReportRejected)
+	prometheus.MustRegister(WorkerJobDuration)
+	prometheus.MustRegister(WorkerJobFailures)
+	prometheus.MustRegister(DBQueryDuration)
+	prometheus.MustRegister(DBQueryErrors)
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db.DB, "penguin_structured"))
+	if replica.Replica() != nil {
+		prometheus.MustRegister(collectors.NewDBStatsCollector(replica.Replica().DB, "penguin_structured_replica"))
+		prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(ServiceName, "db", "replica_lag_seconds"),
+			Help: "Replication lag of the database replica in seconds, as of its latest check",
+		}, func() float64 {
+			return replica.Status().LagSeconds
+		}))
+		prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(ServiceName, "db", "replica_usable"),
+			Help: "Whether read-only queries are routed to the database replica (1) or fall back to the primary (0)",
+		}, func() float64 {
+			if replica.Status().Usable {
+				return 1
+			}
+			return 0
+		}))
+	}
+	prometheus.MustRegister(NewJetStreamCollector(js, constant.ReportStreamName, constant.ReportConsumerName))
+	prometheus.MustRegister(cache.NewCollector(ServiceName))
 }
@@ -0,0 +1,89 @@
+package observability
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nats-io/nats.go"
+	"github.com/penguin-statistics/fiberotel"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.uber.org/fx"
+
+	"github.com/penguin-statistics/backend-next/internal/config"
+)
+
+// TracerName is the name of the tracer every span of the backend is started with
+const TracerName = "backendv3"
+
+// Tracer starts the spans of the backend. It delegates to the tracer provider set up by SetupTracing, and is a no-op
+// when tracing is disabled.
+var Tracer = otel.Tracer(TracerName)
+
+// SetupTracing installs the global tracer provider, which batches spans and exports them to the OTLP endpoint
+// configured, and the W3C trace context propagator used to carry traces across NATS.
+func SetupTracing(conf *config.Config, lc fx.Lifecycle) error {
+	if !conf.TracingEnabled {
+		return nil
+	}
+
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(conf.TracingOTLPEndpoint),
+	}
+	if conf.TracingOTLPInsecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if len(conf.TracingOTLPHeaders) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(conf.TracingOTLPHeaders))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return err
+	}
+
+	tracerProvider := tracesdk.NewTracerProvider(
+		tracesdk.WithBatcher(exporter),
+		tracesdk.WithSampler(tracesdk.ParentBased(tracesdk.TraceIDRatioBased(conf.TracingSampleRatio))),
+		tracesdk.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(TracerName),
+			attribute.String("environment", conf.TracingEnvironment),
+		)),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			// flushes spans not exported yet
+			return tracerProvider.Shutdown(ctx)
+		},
+	})
+
+	return nil
+}
+
+// TraceContext returns the context carrying the span of the HTTP request, falling back to the request context when
+// the request is not traced.
+func TraceContext(ctx *fiber.Ctx) context.Context {
+	if otelCtx, ok := ctx.Locals(fiberotel.LocalsCtxKey).(context.Context); ok {
+		return otelCtx
+	}
+	return ctx.Context()
+}
+
+// InjectTraceHeader writes the trace context of ctx into the header of a NATS message.
+func InjectTraceHeader(ctx context.Context, header nats.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(http.Header(header)))
+}
+
+// ExtractTraceHeader returns a copy of ctx continuing the trace context carried in the header of a NATS message.
+func ExtractTraceHeader(ctx context.Context, header nats.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(http.Header(header)))
+}
@@ -0,0 +1,177 @@
+// Package pgreplica routes read-only queries to a PostgreSQL streaming replica of the primary database, as long as
+// the replica is reachable and its replication lag is within bounds, and to the primary otherwise. Queries routed
+// to the replica may see data up to the maximum lag old, so only queries tolerating it should be routed.
+package pgreplica
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
+)
+
+const (
+	PoolPrimary = "primary"
+	PoolReplica = "replica"
+)
+
+// lagQuery returns whether the replica is in recovery, whether its WAL receiver is streaming from the primary, and
+// its replication lag in seconds. The time since the last replayed transaction keeps growing while the primary is
+// idle, so the lag is zero whenever everything received has been replayed; that only holds while streaming, as
+// nothing more is received otherwise. The lag is NULL if it is unknown, e.g. if nothing has been replayed yet.
+const lagQuery = `SELECT
+	pg_is_in_recovery(),
+	COALESCE((SELECT status = 'streaming' FROM pg_stat_wal_receiver), false),
+	CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+	END`
+
+var (
+	// ErrNotReplica is reported when the replica database is not in recovery, i.e. is not a replica.
+	ErrNotReplica = errors.New("replica database is not in recovery, refusing to use it as a replica")
+
+	// ErrNotStreaming is reported when the replica is not streaming from the primary, so its lag is unknown.
+	ErrNotStreaming = errors.New("replica is not streaming from the primary")
+
+	// ErrLagUnknown is reported when the replica has not replayed anything from the primary yet.
+	ErrLagUnknown = errors.New("replica lag is unknown, as nothing has been replayed yet")
+)
+
+type Router struct {
+	primary *bun.DB
+	replica *bun.DB
+	maxLag  time.Duration
+
+	mu        sync.RWMutex
+	usable    bool
+	lag       time.Duration
+	err       error
+	checkedAt time.Time
+}
+
+// New creates a router sending reads to replica while its lag is at most maxLag. replica may be nil, in which case
+// every query goes to the primary. The replica is considered unusable until checked with Check or Run.
+func New(primary *bun.DB, replica *bun.DB, maxLag time.Duration) *Router {
+	return &Router{
+		primary: primary,
+		replica: replica,
+		maxLag:  maxLag,
+	}
+}
+
+// Primary returns the primary database.
+func (r *Router) Primary() *bun.DB {
+	return r.primary
+}
+
+// Replica returns the replica database, or nil if there is none.
+func (r *Router) Replica() *bun.DB {
+	return r.replica
+}
+
+// Read returns the database to run read-only queries on: the replica if usable, the primary otherwise.
+func (r *Router) Read() *bun.DB {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.usable {
+		return r.replica
+	}
+	return r.primary
+}
+
+// Status describes the replica as of its latest check.
+type Status struct {
+	// Configured tells whether there is a replica at all
+	Configured bool `json:"configured"`
+	// Usable tells whether reads are currently routed to the replica
+	Usable     bool      `json:"usable"`
+	LagSeconds float64   `json:"lagSeconds"`
+	MaxLag     string    `json:"maxLag"`
+	CheckedAt  time.Time `json:"checkedAt"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Status returns the status of the replica as of its latest check.
+func (r *Router) Status() *Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	status := &Status{
+		Configured: r.replica != nil,
+		Usable:     r.usable,
+		LagSeconds: r.lag.Seconds(),
+		MaxLag:     r.maxLag.String(),
+		CheckedAt:  r.checkedAt,
+	}
+	if r.err != nil {
+		status.Error = r.err.Error()
+	}
+	return status
+}
+
+// Run checks the replica every interval until ctx is done.
+func (r *Router) Run(ctx context.Context, interval time.Duration) {
+	if r.replica == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		_ = r.Check(checkCtx)
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check measures the replication lag of the replica, and routes reads to it only if the lag is within bounds.
+func (r *Router) Check(ctx context.Context) error {
+	if r.replica == nil {
+		return nil
+	}
+
+	var inRecovery, streaming bool
+	var lagSeconds sql.NullFloat64
+	err := r.replica.QueryRowContext(ctx, lagQuery).Scan(&inRecovery, &streaming, &lagSeconds)
+	lag := time.Duration(lagSeconds.Float64 * float64(time.Second))
+	if err == nil && !inRecovery {
+		err = ErrNotReplica
+	}
+	if err == nil && !streaming {
+		err = ErrNotStreaming
+	}
+	if err == nil && !lagSeconds.Valid {
+		err = ErrLagUnknown
+	}
+	if err == nil && lag > r.maxLag {
+		err = fmt.Errorf("replica lag of %s exceeds the maximum of %s", lag.Round(time.Millisecond), r.maxLag)
+	}
+
+	r.mu.Lock()
+	wasUsable := r.usable
+	first := r.checkedAt.IsZero()
+	r.usable = err == nil
+	r.lag = lag
+	r.err = err
+	r.checkedAt = time.Now()
+	r.mu.Unlock()
+
+	if err != nil && (wasUsable || first) {
+		log.Warn().Err(err).Msg("database replica unusable, routing reads to the primary")
+	} else if err == nil && !wasUsable {
+		log.Info().Dur("lag", lag).Msg("database replica usable, routing reads to the replica")
+	}
+	return err
+}
@@ -18,12 +18,25 @@ func NewDropMatrixElement(db *bun.DB) *DropMatrixElement {
 	return &DropMatrixElement{db: db}
 }
 
-func (s *DropMatrixElement) BatchSaveElements(ctx context.Context, elements []*model.DropMatrixElement, server string) error {
+// BatchSaveElements replaces the saved elements of the given server and source categories with the given ones. The
+// elements of the server in source categories not among configuredCategories are deleted as well, as they are no
+// longer refreshed.
+func (s *DropMatrixElement) BatchSaveElements(ctx context.Context, elements []*model.DropMatrixElement, server string, sourceCategories []string, configuredCategories []string) error {
 	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
-		_, err := tx.NewDelete().Model((*model.DropMatrixElement)(nil)).Where("server = ?", server).Exec(ctx)
+		_, err := tx.NewDelete().
+			Model((*model.DropMatrixElement)(nil)).
+			Where("server = ?", server).
+			WhereGroup(" AND ", func(q *bun.DeleteQuery) *bun.DeleteQuery {
+				return q.Where("source_category IN (?)", bun.In(sourceCategories)).
+					WhereOr("source_category NOT IN (?)", bun.In(configuredCategories))
+			}).
+			Exec(ctx)
 		if err != nil {
 			return err
 		}
+		if len(elements) == 0 {
+			return nil
+		}
 		_, err = tx.NewInsert().Model(&elements).Exec(ctx)
 		return err
 	})
@@ -48,3 +61,19 @@ func (s *DropMatrixElement) GetElementsByServerAndSourceCategory(ctx context.Con
 	}
 	return elements, nil
 }
+
+func (s *DropMatrixElement) GetElementsByServerAndSourceCategoryAndItemId(ctx context.Context, server string, sourceCategory string, itemId int) ([]*model.DropMatrixElement, error) {
+	var elements []*model.DropMatrixElement
+	err := s.db.NewSelect().
+		Model(&elements).
+		Where("server = ?", server).
+		Where("source_category = ?", sourceCategory).
+		Where("item_id = ?", itemId).
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return elements, nil
+}
@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+
+	"github.com/penguin-statistics/backend-next/internal/model"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
+)
+
+type DropMatrixSnapshot struct {
+	db *bun.DB
+}
+
+func NewDropMatrixSnapshot(db *bun.DB) *DropMatrixSnapshot {
+	return &DropMatrixSnapshot{db: db}
+}
+
+func (s *DropMatrixSnapshot) CreateSnapshot(ctx context.Context, snapshot *model.DropMatrixSnapshot) error {
+	_, err := s.db.NewInsert().
+		Model(snapshot).
+		Returning("snapshot_id, created_at").
+		Exec(ctx)
+	return err
+}
+
+// GetSnapshots returns snapshots from the latest to the earliest, without their elements.
+func (s *DropMatrixSnapshot) GetSnapshots(ctx context.Context, server string, sourceCategory string, limit int) ([]*model.DropMatrixSnapshot, error) {
+	snapshots := make([]*model.DropMatrixSnapshot, 0)
+	err := s.db.NewSelect().
+		Model(&snapshots).
+		ExcludeColumn("elements").
+		Where("server = ?", server).
+		Where("source_category = ?", sourceCategory).
+		Order("created_at DESC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// GetLatestSnapshotBefore returns the latest snapshot recorded at or before the given time.
+func (s *DropMatrixSnapshot) GetLatestSnapshotBefore(ctx context.Context, server string, sourceCategory string, t time.Time) (*model.DropMatrixSnapshot, error) {
+	var snapshot model.DropMatrixSnapshot
+	err := s.db.NewSelect().
+		Model(&snapshot).
+		Where("server = ?", server).
+		Where("source_category = ?", sourceCategory).
+		Where("created_at <= ?", t).
+		Order("created_at DESC").
+		Limit(1).
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pgerr.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetLatestSnapshotHash returns the hash of the latest snapshot, or an empty string if there is none or it was
+// recorded without a hash.
+func (s *DropMatrixSnapshot) GetLatestSnapshotHash(ctx context.Context, server string, sourceCategory string) (string, error) {
+	var hash string
+	err := s.db.NewSelect().
+		Model((*model.DropMatrixSnapshot)(nil)).
+		ColumnExpr("COALESCE(hash, '')").
+		Where("server = ?", server).
+		Where("source_category = ?", sourceCategory).
+		Order("created_at DESC").
+		Limit(1).
+		Scan(ctx, &hash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+	return hash, nil
+}
+
+// DeleteSnapshotsBefore deletes the snapshots recorded before the given time, except for the latest snapshot,
+// and returns the number of snapshots deleted.
+func (s *DropMatrixSnapshot) DeleteSnapshotsBefore(ctx context.Context, server string, sourceCategory string, t time.Time) (int64, error) {
+	latest := s.db.NewSelect().
+		Model((*model.DropMatrixSnapshot)(nil)).
+		Column("snapshot_id").
+		Where("server = ?", server).
+		Where("source_category = ?", sourceCategory).
+		Order("created_at DESC").
+		Limit(1)
+	res, err := s.db.NewDelete().
+		Model((*model.DropMatrixSnapshot)(nil)).
+		Where("server = ?", server).
+		Where("source_category = ?", sourceCategory).
+		Where("created_at < ?", t).
+		Where("snapshot_id NOT IN (?)", latest).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -3,6 +3,7 @@ package repo
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -15,18 +16,27 @@ import (
 	modelv2 "github.com/penguin-statistics/backend-next/internal/model/v2"
 	"github.com/penguin-statistics/backend-next/internal/pkg/gameday"
 	"github.com/penguin-statistics/backend-next/internal/pkg/pgqry"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgreplica"
 )
 
 type DropReport struct {
-	DB *bun.DB
+	DB      *bun.DB
+	Replica *pgreplica.Router
 }
 
-func NewDropReport(db *bun.DB) *DropReport {
+func NewDropReport(db *bun.DB, replica *pgreplica.Router) *DropReport {
 	return &DropReport{
-		DB: db,
+		DB:      db,
+		Replica: replica,
 	}
 }
 
+// readDB returns the database to run read-only aggregation queries on, which is the replica unless it is lagging
+// behind or unreachable. Results may thus miss the reports of the last few seconds.
+func (s *DropReport) readDB() *bun.DB {
+	return s.Replica.Read()
+}
+
 func (s *DropReport) CreateDropReport(ctx context.Context, tx bun.Tx, dropReport *model.DropReport) error {
 	_, err := tx.NewInsert().
 		Model(dropReport).
@@ -51,7 +61,7 @@ func (s *DropReport) CalcTotalQuantityForDropMatrix(
 		return results, nil
 	}
 
-	subq1 := s.DB.NewSelect().
+	subq1 := s.readDB().NewSelect().
 		TableExpr("drop_reports AS dr").
 		Column("dr.report_id", "dr.stage_id", "dpe.item_id", "dpe.quantity").
 		Join("JOIN drop_pattern_elements AS dpe ON dpe.drop_pattern_id = dr.pattern_id")
@@ -60,7 +70,7 @@ func (s *DropReport) CalcTotalQuantityForDropMatrix(
 	s.handleServer(subq1, server)
 	s.handleStagesAndItems(subq1, stageIdItemIdMap)
 
-	mainq := s.DB.NewSelect().
+	mainq := s.readDB().NewSelect().
 		TableExpr("(?) AS a", subq1).
 		Column("stage_id", "item_id").
 		ColumnExpr("SUM(quantity) AS total_quantity").
@@ -83,7 +93,7 @@ func (s *DropReport) CalcTotalQuantityForPatternMatrix(
 		return results, nil
 	}
 
-	subq1 := s.DB.NewSelect().
+	subq1 := s.readDB().NewSelect().
 		TableExpr("drop_reports AS dr").
 		Column("dr.report_id", "dr.stage_id", "dr.pattern_id")
 	s.handleAccountAndReliability(subq1, accountId)
@@ -92,7 +102,7 @@ func (s *DropReport) CalcTotalQuantityForPatternMatrix(
 	s.handleStages(subq1, stageIds)
 	s.handleTimes(subq1, 1)
 
-	mainq := s.DB.NewSelect().
+	mainq := s.readDB().NewSelect().
 		TableExpr("(?) AS a", subq1).
 		Column("stage_id", "pattern_id").
 		ColumnExpr("COUNT(*) AS total_quantity").
@@ -115,7 +125,7 @@ func (s *DropReport) CalcTotalTimes(
 		return results, nil
 	}
 
-	subq1 := s.DB.NewSelect().
+	subq1 := s.readDB().NewSelect().
 		TableExpr("drop_reports AS dr").
 		Column("dr.report_id", "dr.stage_id", "dr.times")
 	s.handleAccountAndReliability(subq1, accountId)
@@ -126,7 +136,7 @@ func (s *DropReport) CalcTotalTimes(
 	s.handleServer(subq1, server)
 	s.handleStages(subq1, stageIds)
 
-	mainq := s.DB.NewSelect().
+	mainq := s.readDB().NewSelect().
 		TableExpr("(?) AS a", subq1).
 		Column("stage_id").
 		ColumnExpr("SUM(times) AS total_times").
@@ -149,7 +159,7 @@ func (s *DropReport) CalcQuantityUniqCount(
 		return results, nil
 	}
 
-	subq1 := s.DB.NewSelect().
+	subq1 := s.readDB().NewSelect().
 		TableExpr("drop_reports AS dr").
 		Column("dr.report_id", "dr.stage_id", "dpe.item_id", "dpe.quantity").
 		Join("JOIN drop_pattern_elements AS dpe ON dpe.drop_pattern_id = dr.pattern_id")
@@ -158,7 +168,7 @@ func (s *DropReport) CalcQuantityUniqCount(
 	s.handleServer(subq1, server)
 	s.handleStagesAndItems(subq1, stageIdItemIdMap)
 
-	mainq := s.DB.NewSelect().
+	mainq := s.readDB().NewSelect().
 		TableExpr("(?) AS a", subq1).
 		Column("stage_id", "item_id", "quantity").
 		ColumnExpr("COUNT(*) AS count").
@@ -184,7 +194,7 @@ func (s *DropReport) CalcTotalQuantityForTrend(
 	gameDayStart := gameday.StartTime(server, *startTime)
 	lastDayEnd := gameDayStart.Add(time.Hour * time.Duration(int(intervalLength.Hours())*(intervalNum+1)))
 
-	subq1 := s.DB.NewSelect().
+	subq1 := s.readDB().NewSelect().
 		With("intervals", s.genSubQueryForTrendSegments(gameDayStart, intervalLength, intervalNum)).
 		TableExpr("drop_reports AS dr").
 		Column("dr.report_id", "sub.group_id", "sub.interval_start", "sub.interval_end", "dr.stage_id", "dpe.item_id", "dpe.quantity").
@@ -196,7 +206,7 @@ func (s *DropReport) CalcTotalQuantityForTrend(
 	s.handleServer(subq1, server)
 	s.handleStagesAndItems(subq1, stageIdItemIdMap)
 
-	mainq := s.DB.NewSelect().
+	mainq := s.readDB().NewSelect().
 		TableExpr("(?) AS a", subq1).
 		Column("group_id", "interval_start", "interval_end", "stage_id", "item_id").
 		ColumnExpr("SUM(quantity) AS total_quantity").
@@ -222,7 +232,7 @@ func (s *DropReport) CalcTotalTimesForTrend(
 	gameDayStart := gameday.StartTime(server, *startTime)
 	lastDayEnd := gameDayStart.Add(time.Hour * time.Duration(int(intervalLength.Hours())*(intervalNum+1)))
 
-	subq1 := s.DB.NewSelect().
+	subq1 := s.readDB().NewSelect().
 		With("intervals", s.genSubQueryForTrendSegments(gameDayStart, intervalLength, intervalNum)).
 		TableExpr("drop_reports AS dr").
 		Column("dr.report_id", "sub.group_id", "sub.interval_start", "sub.interval_end", "dr.stage_id", "dr.times").
@@ -233,7 +243,7 @@ func (s *DropReport) CalcTotalTimesForTrend(
 	s.handleServer(subq1, server)
 	s.handleStages(subq1, stageIds)
 
-	mainq := s.DB.NewSelect().
+	mainq := s.readDB().NewSelect().
 		TableExpr("(?) AS a", subq1).
 		Column("group_id", "interval_start", "interval_end", "stage_id").
 		ColumnExpr("SUM(times) AS total_times").
@@ -250,7 +260,7 @@ func (s *DropReport) CalcTotalTimesForTrend(
 
 func (s *DropReport) CalcTotalSanityCostForShimSiteStats(ctx context.Context, server string) (sanity int, err error) {
 	err = pgqry.New(
-		s.DB.NewSelect().
+		s.readDB().NewSelect().
 			TableExpr("drop_reports AS dr").
 			ColumnExpr("SUM(st.sanity * dr.times)").
 			Where("dr.reliability = 0 AND dr.server = ?", server),
@@ -264,7 +274,7 @@ func (s *DropReport) CalcTotalStageQuantityForShimSiteStats(ctx context.Context,
 	results := make([]*modelv2.TotalStageTime, 0)
 
 	err := pgqry.New(
-		s.DB.NewSelect().
+		s.readDB().NewSelect().
 			TableExpr("drop_reports AS dr").
 			Column("st.ark_stage_id").
 			ColumnExpr("SUM(dr.times) AS total_times").
@@ -291,7 +301,7 @@ func (s *DropReport) CalcTotalItemQuantityForShimSiteStats(ctx context.Context,
 
 	types := []string{constant.ItemTypeMaterial, constant.ItemTypeFurniture, constant.ItemTypeChip}
 	err := pgqry.New(
-		s.DB.NewSelect().
+		s.readDB().NewSelect().
 			TableExpr("drop_reports AS dr").
 			Column("it.ark_item_id").
 			ColumnExpr("SUM(dpe.quantity) AS total_quantity").
@@ -307,6 +317,163 @@ func (s *DropReport) CalcTotalItemQuantityForShimSiteStats(ctx context.Context,
 	return results, nil
 }
 
+// CalcDailyStatsForSiteStats aggregates reports created since start by game day. dayOffset is the offset to add to a UTC
+// timestamp so that its date falls on the game day it belongs to.
+func (s *DropReport) CalcDailyStatsForSiteStats(ctx context.Context, server string, start time.Time, dayOffset time.Duration) ([]*model.SiteStatsDay, error) {
+	results := make([]*model.SiteStatsDay, 0)
+
+	err := pgqry.New(
+		s.readDB().NewSelect().
+			TableExpr("drop_reports AS dr").
+			ColumnExpr("to_char((dr.created_at AT TIME ZONE 'UTC') + ? * interval '1 second', 'YYYY-MM-DD') AS date", int(dayOffset.Seconds())).
+			ColumnExpr("COUNT(*) AS reports").
+			ColumnExpr("COUNT(*) FILTER (WHERE dr.reliability > 0) AS unreliable_reports").
+			ColumnExpr("COALESCE(SUM(dr.times) FILTER (WHERE dr.reliability = 0), 0) AS times").
+			ColumnExpr("COALESCE(SUM(st.sanity * dr.times) FILTER (WHERE dr.reliability = 0), 0) AS sanity").
+			ColumnExpr("COUNT(DISTINCT dr.account_id) FILTER (WHERE dr.reliability = 0) AS unique_accounts").
+			Where("dr.reliability >= 0 AND dr.server = ?", server).
+			Where("dr.created_at >= to_timestamp(?)", start.Unix()).
+			GroupExpr("1").
+			OrderExpr("1"),
+	).
+		UseStageById("dr.stage_id").
+		Q.Scan(ctx, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *DropReport) CalcUniqueAccountsForSiteStats(ctx context.Context, server string, start time.Time) (count int, err error) {
+	err = s.readDB().NewSelect().
+		TableExpr("drop_reports AS dr").
+		ColumnExpr("COUNT(DISTINCT dr.account_id)").
+		Where("dr.reliability = 0 AND dr.server = ?", server).
+		Where("dr.created_at >= to_timestamp(?)", start.Unix()).
+		Scan(ctx, &count)
+	return count, err
+}
+
+func (s *DropReport) CalcZoneStatsForSiteStats(ctx context.Context, server string, start time.Time) ([]*model.SiteStatsZone, error) {
+	results := make([]*model.SiteStatsZone, 0)
+
+	err := pgqry.New(
+		s.readDB().NewSelect().
+			TableExpr("drop_reports AS dr").
+			Column("zo.ark_zone_id").
+			ColumnExpr("COUNT(*) AS reports").
+			ColumnExpr("SUM(dr.times) AS times").
+			ColumnExpr("COALESCE(SUM(st.sanity * dr.times), 0) AS sanity").
+			Where("dr.reliability = 0 AND dr.server = ?", server).
+			Where("dr.created_at >= to_timestamp(?)", start.Unix()).
+			Group("zo.ark_zone_id").
+			OrderExpr("times DESC"),
+	).
+		UseStageById("dr.stage_id").
+		UseZoneById("st.zone_id").
+		Q.Scan(ctx, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *DropReport) CalcSourceStatsForSiteStats(ctx context.Context, server string, start time.Time) ([]*model.SiteStatsSource, error) {
+	results := make([]*model.SiteStatsSource, 0)
+
+	err := s.readDB().NewSelect().
+		TableExpr("drop_reports AS dr").
+		ColumnExpr("COALESCE(dre.source_name, '') AS source_name").
+		ColumnExpr("COUNT(*) AS reports").
+		ColumnExpr("COUNT(*) FILTER (WHERE dr.reliability > 0) AS unreliable_reports").
+		ColumnExpr("COALESCE(SUM(dr.times) FILTER (WHERE dr.reliability = 0), 0) AS times").
+		Join("LEFT JOIN drop_report_extras AS dre ON dre.report_id = dr.report_id").
+		Where("dr.reliability >= 0 AND dr.server = ?", server).
+		Where("dr.created_at >= to_timestamp(?)", start.Unix()).
+		GroupExpr("1").
+		OrderExpr("reports DESC").
+		Scan(ctx, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// CalcTotalQuantityForGachaBox sums up the quantity of every item drawn from the given gacha box stage.
+func (s *DropReport) CalcTotalQuantityForGachaBox(ctx context.Context, server string, stageId int, accountId null.Int) ([]*model.GachaBoxItemQuantity, error) {
+	results := make([]*model.GachaBoxItemQuantity, 0)
+	query := s.readDB().NewSelect().
+		TableExpr("drop_reports AS dr").
+		Column("dpe.item_id").
+		ColumnExpr("SUM(dpe.quantity) AS total_quantity").
+		Join("JOIN drop_pattern_elements AS dpe ON dpe.drop_pattern_id = dr.pattern_id").
+		Where("dr.stage_id = ?", stageId)
+	s.handleAccountAndReliability(query, accountId)
+	s.handleServer(query, server)
+
+	if err := query.
+		Group("dpe.item_id").
+		Scan(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// CalcFurnitureDropsByStage sums up, for each of the given stages, the times of reliable reports created within the
+// given time ranges of the stage and the quantity of furniture dropped in them.
+func (s *DropReport) CalcFurnitureDropsByStage(ctx context.Context, server string, stageTimeRanges map[int][]*model.TimeRange, furnitureItemId int) ([]*model.FurnitureDropQuantity, error) {
+	results := make([]*model.FurnitureDropQuantity, 0)
+	if len(stageTimeRanges) == 0 {
+		return results, nil
+	}
+
+	query := s.readDB().NewSelect().
+		TableExpr("drop_reports AS dr").
+		Column("dr.stage_id").
+		ColumnExpr("SUM(dr.times) AS times").
+		ColumnExpr("COALESCE(SUM(dpe.quantity), 0) AS quantity").
+		Join("LEFT JOIN drop_pattern_elements AS dpe ON dpe.drop_pattern_id = dr.pattern_id AND dpe.item_id = ?", furnitureItemId)
+	s.handleAccountAndReliability(query, null.NewInt(0, false))
+	s.handleServer(query, server)
+	s.handleStagesWithTimeRanges(query, stageTimeRanges)
+
+	if err := query.
+		Group("dr.stage_id").
+		Scan(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// CalcTotalTimesForGachaBox sums up the times of the reports on the given gacha box stage, i.e. the number of draws.
+func (s *DropReport) CalcTotalTimesForGachaBox(ctx context.Context, server string, stageId int, accountId null.Int) (times int, err error) {
+	query := s.readDB().NewSelect().
+		TableExpr("drop_reports AS dr").
+		ColumnExpr("COALESCE(SUM(dr.times), 0)").
+		Where("dr.stage_id = ?", stageId)
+	s.handleAccountAndReliability(query, accountId)
+	s.handleServer(query, server)
+
+	err = query.Scan(ctx, &times)
+	return times, err
+}
+
+// GetReliableDropReportsForExport returns anonymised reliable drop reports created within the given time span.
+func (s *DropReport) GetReliableDropReportsForExport(ctx context.Context, server string, start time.Time, end time.Time) ([]*model.ExportedDropReport, error) {
+	results := make([]*model.ExportedDropReport, 0)
+	query := s.readDB().NewSelect().
+		TableExpr("drop_reports AS dr").
+		Column("dr.stage_id", "dr.pattern_id", "dr.times", "dr.created_at", "sc.source_name").
+		Join("LEFT JOIN (?) AS sc ON sc.report_id = dr.report_id", s.genSubQueryForSourceName())
+	s.handleAccountAndReliability(query, null.NewInt(0, false))
+	s.handleCreatedAtWithTime(query, start, end)
+	s.handleServer(query, server)
+	if err := query.Order("dr.created_at").Scan(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
 func (s *DropReport) handleStagesAndItems(query *bun.SelectQuery, stageIdItemIdMap map[int][]int) {
 	stageConditions := make([]string, 0)
 	for stageId, itemIds := range stageIdItemIdMap {
@@ -341,6 +508,42 @@ func (s *DropReport) handleStages(query *bun.SelectQuery, stageIds []int) {
 	query.Where(b.String())
 }
 
+// handleStagesWithTimeRanges limits the reports to the given stages, each within any of its time ranges.
+func (s *DropReport) handleStagesWithTimeRanges(query *bun.SelectQuery, stageTimeRanges map[int][]*model.TimeRange) {
+	stageIds := make([]int, 0, len(stageTimeRanges))
+	for stageId := range stageTimeRanges {
+		stageIds = append(stageIds, stageId)
+	}
+	sort.Ints(stageIds)
+
+	stageConditions := make([]string, 0, len(stageIds))
+	args := make([]any, 0)
+	for _, stageId := range stageIds {
+		rangeConditions := make([]string, 0, len(stageTimeRanges[stageId]))
+		for _, timeRange := range stageTimeRanges[stageId] {
+			conditions := []string{"TRUE"}
+			if timeRange.StartTime != nil {
+				conditions = append(conditions, "dr.created_at >= ?")
+				args = append(args, *timeRange.StartTime)
+			}
+			if timeRange.EndTime != nil {
+				conditions = append(conditions, "dr.created_at < ?")
+				args = append(args, *timeRange.EndTime)
+			}
+			rangeConditions = append(rangeConditions, "("+strings.Join(conditions, " AND ")+")")
+		}
+		if len(rangeConditions) == 0 {
+			continue
+		}
+		stageConditions = append(stageConditions, fmt.Sprintf("(dr.stage_id = %d AND (%s))", stageId, strings.Join(rangeConditions, " OR ")))
+	}
+	if len(stageConditions) == 0 {
+		query.Where("FALSE")
+		return
+	}
+	query.Where(strings.Join(stageConditions, " OR "), args...)
+}
+
 func (s *DropReport) handleAccountAndReliability(query *bun.SelectQuery, accountId null.Int) {
 	if accountId.Valid {
 		query = query.Where("dr.reliability >= 0 AND dr.account_id = ?", accountId.Int64)
@@ -384,7 +587,7 @@ func (s *DropReport) genSubQueryForTrendSegments(gameDayStart time.Time, interva
 	fmt.Fprintf(&subQueryExprBuilder, "to_timestamp(?) + (n || ' hours')::interval AS interval_start, ")
 	fmt.Fprintf(&subQueryExprBuilder, "to_timestamp(?) + ((n + ?) || ' hours')::interval AS interval_end, ")
 	fmt.Fprintf(&subQueryExprBuilder, "(n / ?) AS group_id")
-	return s.DB.NewSelect().
+	return s.readDB().NewSelect().
 		TableExpr("generate_series(?, ? * ?, ?) AS n", 0, int(intervalLength.Hours()), intervalNum, int(intervalLength.Hours())).
 		ColumnExpr(subQueryExprBuilder.String(),
 			gameDayStart.Unix(),
@@ -395,7 +598,7 @@ func (s *DropReport) genSubQueryForTrendSegments(gameDayStart time.Time, interva
 }
 
 func (s *DropReport) genSubQueryForSourceName() *bun.SelectQuery {
-	return s.DB.NewSelect().
+	return s.readDB().NewSelect().
 		TableExpr("drop_report_extras AS dre").
 		Column("dre.report_id", "dre.source_name")
 }
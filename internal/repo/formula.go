@@ -0,0 +1,139 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+
+	"github.com/penguin-statistics/backend-next/internal/model"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
+)
+
+type Formula struct {
+	db *bun.DB
+}
+
+func NewFormula(db *bun.DB) *Formula {
+	return &Formula{db: db}
+}
+
+func (r *Formula) GetFormulas(ctx context.Context) ([]*model.Formula, error) {
+	return r.getFormulas(ctx, r.db)
+}
+
+// GetFormulasTx is the same as GetFormulas, but reads within the given transaction.
+func (r *Formula) GetFormulasTx(ctx context.Context, tx bun.Tx) ([]*model.Formula, error) {
+	return r.getFormulas(ctx, tx)
+}
+
+func (r *Formula) getFormulas(ctx context.Context, db bun.IDB) ([]*model.Formula, error) {
+	formulas := make([]*model.Formula, 0)
+	err := db.NewSelect().
+		Model(&formulas).
+		Order("ark_item_id").
+		Scan(ctx)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return formulas, nil
+}
+
+func (r *Formula) SaveFormula(ctx context.Context, tx bun.Tx, formula *model.Formula) error {
+	_, err := tx.NewInsert().
+		Model(formula).
+		On("CONFLICT (ark_item_id) DO UPDATE").
+		Set("gold_cost = EXCLUDED.gold_cost").
+		Set("costs = EXCLUDED.costs").
+		Set("extra_outcomes = EXCLUDED.extra_outcomes").
+		Set("existence = EXCLUDED.existence").
+		Returning("formula_id").
+		Exec(ctx)
+	return err
+}
+
+func (r *Formula) DeleteFormulaByArkItemId(ctx context.Context, tx bun.Tx, arkItemId string) error {
+	res, err := tx.NewDelete().
+		Model((*model.Formula)(nil)).
+		Where("ark_item_id = ?", arkItemId).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return pgerr.ErrNotFound
+	}
+	return nil
+}
+
+// ReplaceFormulas replaces every formula with the given ones.
+func (r *Formula) ReplaceFormulas(ctx context.Context, tx bun.Tx, formulas []*model.Formula) error {
+	_, err := tx.NewDelete().
+		Model((*model.Formula)(nil)).
+		Where("1 = 1").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(formulas) == 0 {
+		return nil
+	}
+	for _, formula := range formulas {
+		formula.FormulaID = 0
+	}
+	_, err = tx.NewInsert().
+		Model(&formulas).
+		Exec(ctx)
+	return err
+}
+
+func (r *Formula) CreateRevision(ctx context.Context, tx bun.Tx, revision *model.FormulaRevision) error {
+	_, err := tx.NewInsert().
+		Model(revision).
+		Returning("revision_id, created_at").
+		Exec(ctx)
+	return err
+}
+
+// HasRevisions tells whether any revision has been recorded, i.e. whether formulas have ever been modified.
+func (r *Formula) HasRevisions(ctx context.Context) (bool, error) {
+	return r.db.NewSelect().
+		Model((*model.FormulaRevision)(nil)).
+		Exists(ctx)
+}
+
+// GetRevisions returns all revisions from the latest to the earliest, without their snapshots.
+func (r *Formula) GetRevisions(ctx context.Context) ([]*model.FormulaRevision, error) {
+	revisions := make([]*model.FormulaRevision, 0)
+	err := r.db.NewSelect().
+		Model(&revisions).
+		ExcludeColumn("formulas").
+		Order("revision_id DESC").
+		Scan(ctx)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (r *Formula) GetRevisionById(ctx context.Context, revisionId int) (*model.FormulaRevision, error) {
+	var revision model.FormulaRevision
+	err := r.db.NewSelect().
+		Model(&revision).
+		Where("revision_id = ?", revisionId).
+		Scan(ctx)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pgerr.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &revision, nil
+}
@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+
+	"github.com/penguin-statistics/backend-next/internal/model"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
+)
+
+type GachaBox struct {
+	db *bun.DB
+}
+
+func NewGachaBox(db *bun.DB) *GachaBox {
+	return &GachaBox{db: db}
+}
+
+func (r *GachaBox) GetPools(ctx context.Context) ([]*model.GachaBoxPool, error) {
+	pools := make([]*model.GachaBoxPool, 0)
+	err := r.db.NewSelect().
+		Model(&pools).
+		Order("stage_id").
+		Scan(ctx)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return pools, nil
+}
+
+func (r *GachaBox) GetPoolByStageId(ctx context.Context, stageId int) (*model.GachaBoxPool, error) {
+	var pool model.GachaBoxPool
+	err := r.db.NewSelect().
+		Model(&pool).
+		Where("stage_id = ?", stageId).
+		Scan(ctx)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pgerr.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &pool, nil
+}
+
+func (r *GachaBox) SavePool(ctx context.Context, pool *model.GachaBoxPool) error {
+	_, err := r.db.NewInsert().
+		Model(pool).
+		On("CONFLICT (stage_id) DO UPDATE").
+		Set("items = EXCLUDED.items").
+		Set("draw_cost = EXCLUDED.draw_cost").
+		Set("cost_item_id = EXCLUDED.cost_item_id").
+		Set("updated_at = EXCLUDED.updated_at").
+		Returning("pool_id").
+		Exec(ctx)
+	return err
+}
+
+func (r *GachaBox) DeletePoolByStageId(ctx context.Context, stageId int) error {
+	res, err := r.db.NewDelete().
+		Model((*model.GachaBoxPool)(nil)).
+		Where("stage_id = ?", stageId).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return pgerr.ErrNotFound
+	}
+	return nil
+}
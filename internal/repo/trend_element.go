@@ -18,12 +18,25 @@ func NewTrendElement(db *bun.DB) *TrendElement {
 	return &TrendElement{db: db}
 }
 
-func (s *TrendElement) BatchSaveElements(ctx context.Context, elements []*model.TrendElement, server string) error {
+// BatchSaveElements replaces the saved elements of the given server and source categories with the given ones. The
+// elements of the server in source categories not among configuredCategories are deleted as well, as they are no
+// longer refreshed.
+func (s *TrendElement) BatchSaveElements(ctx context.Context, elements []*model.TrendElement, server string, sourceCategories []string, configuredCategories []string) error {
 	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
-		_, err := tx.NewDelete().Model((*model.TrendElement)(nil)).Where("server = ?", server).Exec(ctx)
+		_, err := tx.NewDelete().
+			Model((*model.TrendElement)(nil)).
+			Where("server = ?", server).
+			WhereGroup(" AND ", func(q *bun.DeleteQuery) *bun.DeleteQuery {
+				return q.Where("source_category IN (?)", bun.In(sourceCategories)).
+					WhereOr("source_category NOT IN (?)", bun.In(configuredCategories))
+			}).
+			Exec(ctx)
 		if err != nil {
 			return err
 		}
+		if len(elements) == 0 {
+			return nil
+		}
 		_, err = tx.NewInsert().Model(&elements).Exec(ctx)
 		return err
 	})
@@ -23,12 +23,6 @@ import (
 	"github.com/gofiber/helmet/v2"
 	"github.com/penguin-statistics/fiberotel"
 	"github.com/rs/zerolog/log"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/jaeger"
-	"go.opentelemetry.io/otel/sdk/resource"
-	tracesdk "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 
 	"github.com/penguin-statistics/backend-next/internal/config"
 	"github.com/penguin-statistics/backend-next/internal/constant"
@@ -66,8 +60,8 @@ func Create(conf *config.Config, flake *snowflake.Node) *fiber.App {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "*",
 		AllowMethods:     "GET, POST, DELETE, OPTIONS",
-		AllowHeaders:     "Content-Type, Authorization, X-Requested-With, X-Penguin-Variant, sentry-trace",
-		ExposeHeaders:    "Content-Type, X-Penguin-Set-PenguinID, X-Penguin-Upgrade, X-Penguin-Compatible, X-Penguin-Request-ID",
+		AllowHeaders:     "Content-Type, Authorization, X-Requested-With, X-Penguin-Variant, sentry-trace, If-None-Match, If-Modified-Since",
+		ExposeHeaders:    "Content-Type, ETag, Last-Modified, X-Penguin-Set-PenguinID, X-Penguin-Upgrade, X-Penguin-Compatible, X-Penguin-Request-ID",
 		AllowCredentials: true,
 	}))
 	// requestid is used by report service to identify requests and generate taskId there afterwards
@@ -100,22 +94,8 @@ func Create(conf *config.Config, flake *snowflake.Node) *fiber.App {
 	fiberprom.RegisterAt(app, "/metrics")
 
 	if conf.TracingEnabled {
-		exporter, err := jaeger.New(jaeger.WithCollectorEndpoint())
-		if err != nil {
-			panic(err)
-		}
-		tracerProvider := tracesdk.NewTracerProvider(
-			tracesdk.WithSyncer(exporter),
-			tracesdk.WithResource(resource.NewWithAttributes(
-				semconv.SchemaURL,
-				semconv.ServiceNameKey.String("backendv3"),
-				attribute.String("environment", "dev"),
-			)),
-		)
-		otel.SetTracerProvider(tracerProvider)
-
 		app.Use(fiberotel.New(fiberotel.Config{
-			Tracer:   tracerProvider.Tracer("backendv3"),
+			Tracer:   observability.Tracer,
 			SpanName: "HTTP {{ .Method }} {{ .Path }}",
 		}))
 	}
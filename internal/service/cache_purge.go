@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/penguin-statistics/backend-next/internal/constant"
+	"github.com/penguin-statistics/backend-next/internal/infra"
+	"github.com/penguin-statistics/backend-next/internal/model/cache"
+	"github.com/penguin-statistics/backend-next/internal/model/types"
+)
+
+// CachePurge purges caches on every instance. A purge is broadcast over NATS, and every instance, including the one
+// broadcasting it, purges its own caches and replies with an acknowledgement.
+type CachePurge struct {
+	NATS     *nats.Conn
+	Instance string
+}
+
+func NewCachePurge(nc *nats.Conn) (*CachePurge, error) {
+	s := &CachePurge{
+		NATS:     nc,
+		Instance: infra.InstanceID(),
+	}
+	if _, err := nc.Subscribe(constant.CachePurgeSubject, s.handle); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Purge broadcasts the purge of the given caches, and returns the acknowledgements of the instances that replied
+// within constant.CachePurgeAckTimeout. An instance that does not reply in time, e.g. because it is unreachable,
+// is simply missing from the acknowledgements.
+func (s *CachePurge) Purge(ctx context.Context, pairs []types.PurgeCachePair) ([]*types.PurgeCacheAck, error) {
+	payload, err := json.Marshal(pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	inbox := nats.NewInbox()
+	sub, err := s.NATS.SubscribeSync(inbox)
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Unsubscribe()
+
+	if err := s.NATS.PublishRequest(constant.CachePurgeSubject, inbox, payload); err != nil {
+		return nil, err
+	}
+
+	acks := make([]*types.PurgeCacheAck, 0)
+	deadline := time.Now().Add(constant.CachePurgeAckTimeout)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 || ctx.Err() != nil {
+			break
+		}
+		msg, err := sub.NextMsg(remaining)
+		if err == nats.ErrTimeout {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		var ack types.PurgeCacheAck
+		if err := json.Unmarshal(msg.Data, &ack); err != nil {
+			log.Warn().Err(err).Msg("failed to parse cache purge acknowledgement")
+			continue
+		}
+		acks = append(acks, &ack)
+	}
+	return acks, nil
+}
+
+func (s *CachePurge) handle(msg *nats.Msg) {
+	var pairs []types.PurgeCachePair
+	if err := json.Unmarshal(msg.Data, &pairs); err != nil {
+		log.Warn().Err(err).Msg("failed to parse cache purge")
+		return
+	}
+
+	ack := &types.PurgeCacheAck{
+		Instance: s.Instance,
+		OK:       true,
+		Results:  make([]*types.PurgeCacheResult, 0, len(pairs)),
+	}
+	for _, pair := range pairs {
+		name, key := pair.Name, pair.Key
+		if !key.Valid {
+			if i := strings.Index(name, ":"); i >= 0 {
+				name, key = name[:i], null.StringFrom(name[i+1:])
+			}
+		}
+
+		result := &types.PurgeCacheResult{
+			Name: name,
+			Key:  key,
+		}
+		if err := cache.Delete(name, key); err != nil {
+			result.Error = err.Error()
+			ack.OK = false
+		}
+		ack.Results = append(ack.Results, result)
+	}
+	log.Info().Bool("ok", ack.OK).Int("pairs", len(pairs)).Msg("cache purged")
+
+	if msg.Reply == "" {
+		return
+	}
+	reply, err := json.Marshal(ack)
+	if err != nil {
+		return
+	}
+	if err := msg.Respond(reply); err != nil {
+		log.Warn().Err(err).Msg("failed to acknowledge cache purge")
+	}
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -9,6 +11,7 @@ import (
 	"github.com/ahmetb/go-linq/v3"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
 	"gopkg.in/guregu/null.v3"
 
 	"github.com/penguin-statistics/backend-next/internal/constant"
@@ -20,7 +23,7 @@ import (
 )
 
 /*
-This service has four functions:
+This service has six functions:
 
 	1. Get Global Drop Matrix
 		a. getDropMatrixElements() to get elements from DB
@@ -40,15 +43,26 @@ This service has four functions:
 	4. Re-calculate Global Drop Matrix
 		a. calcDropMatrixForTimeRanges() for each timeRange
 		b. save elements into DB
+		c. record a snapshot of the elements for change history
+
+	5. Get Drops of an Item
+		a. get saved elements of the item from DB
+		b. group elements by stage, and annotate them with time ranges, rates and sanity costs
+
+	6. Apply Shrinkage Estimates (optional, on top of a v2 drop matrix)
+		a. group elements of the same item by zone category and by sanity cost
+		b. fit a prior from comparable stages, and shrink the rate of each element toward it
 */
 
 type DropMatrix struct {
-	TimeRangeService         *TimeRange
-	DropReportService        *DropReport
-	DropInfoService          *DropInfo
-	DropMatrixElementService *DropMatrixElement
-	StageService             *Stage
-	ItemService              *Item
+	TimeRangeService          *TimeRange
+	DropReportService         *DropReport
+	DropInfoService           *DropInfo
+	DropMatrixElementService  *DropMatrixElement
+	DropMatrixSnapshotService *DropMatrixSnapshot
+	StageService              *Stage
+	ItemService               *Item
+	ZoneService               *Zone
 }
 
 func NewDropMatrix(
@@ -56,47 +70,96 @@ func NewDropMatrix(
 	dropReportService *DropReport,
 	dropInfoService *DropInfo,
 	dropMatrixElementService *DropMatrixElement,
+	dropMatrixSnapshotService *DropMatrixSnapshot,
 	stageService *Stage,
 	itemService *Item,
+	zoneService *Zone,
 ) *DropMatrix {
 	return &DropMatrix{
-		TimeRangeService:         timeRangeService,
-		DropReportService:        dropReportService,
-		DropInfoService:          dropInfoService,
-		DropMatrixElementService: dropMatrixElementService,
-		StageService:             stageService,
-		ItemService:              itemService,
+		TimeRangeService:          timeRangeService,
+		DropReportService:         dropReportService,
+		DropInfoService:           dropInfoService,
+		DropMatrixElementService:  dropMatrixElementService,
+		DropMatrixSnapshotService: dropMatrixSnapshotService,
+		StageService:              stageService,
+		ItemService:               itemService,
+		ZoneService:               zoneService,
 	}
 }
 
-// Cache: shimMaxAccumulableDropMatrixResults#server|showClosedZoned:{server}|{showClosedZones}, 24 hrs, records last modified time
+// Cache: shimMaxAccumulableDropMatrixResults#server|showClosedZoned:{server}|{showClosedZones}, fresh for 1 hr and served stale up to 24 hrs, records last modified time
 func (s *DropMatrix) GetShimMaxAccumulableDropMatrixResults(
 	ctx context.Context, server string, showClosedZones bool, stageFilterStr string, itemFilterStr string, accountId null.Int,
 ) (*modelv2.DropMatrixQueryResult, error) {
-	valueFunc := func() (*modelv2.DropMatrixQueryResult, error) {
-		savedDropMatrixResults, err := s.getMaxAccumulableDropMatrixResults(ctx, server, accountId, constant.SourceCategoryAll)
+	if !accountId.Valid && stageFilterStr == "" && itemFilterStr == "" {
+		var results modelv2.DropMatrixQueryResult
+		key := server + constant.CacheSep + strconv.FormatBool(showClosedZones)
+		_, err := cache.ShimMaxAccumulableDropMatrixResults.StaleGetSet(ctx, key, &results, s.shimMaxAccumulableDropMatrixResultsFunc(server, showClosedZones), constant.ShimResultsFreshTTL, constant.ShimResultsExpireTTL)
 		if err != nil {
 			return nil, err
 		}
-		slowResults, err := s.applyShimForDropMatrixQuery(ctx, server, showClosedZones, stageFilterStr, itemFilterStr, savedDropMatrixResults)
+		return &results, nil
+	} else {
+		savedDropMatrixResults, err := s.getMaxAccumulableDropMatrixResults(ctx, server, accountId, constant.SourceCategoryAll)
 		if err != nil {
 			return nil, err
 		}
-		return slowResults, nil
+		return s.applyShimForDropMatrixQuery(ctx, server, showClosedZones, stageFilterStr, itemFilterStr, savedDropMatrixResults)
 	}
+}
 
+// GetShrunkMaxAccumulableDropMatrixResults returns the global drop matrix of the server with shrinkage estimates
+// applied, which is cached along with the global drop matrix itself.
+// Cache: shrunkMaxAccumulableDropMatrixResults#server|showClosedZoned:{server}|{showClosedZones}, fresh for 1 hr and served stale up to 24 hrs, records last modified time
+func (s *DropMatrix) GetShrunkMaxAccumulableDropMatrixResults(ctx context.Context, server string, showClosedZones bool) (*modelv2.DropMatrixQueryResult, error) {
 	var results modelv2.DropMatrixQueryResult
-	if !accountId.Valid && stageFilterStr == "" && itemFilterStr == "" {
+	key := server + constant.CacheSep + strconv.FormatBool(showClosedZones)
+	_, err := cache.ShrunkMaxAccumulableDropMatrixResults.StaleGetSet(ctx, key, &results, s.shrunkMaxAccumulableDropMatrixResultsFunc(server, showClosedZones), constant.ShimResultsFreshTTL, constant.ShimResultsExpireTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+// RefreshShimMaxAccumulableDropMatrixResults recomputes the cached shim results of the server, with and without
+// shrinkage estimates, and swaps them in. Shrinkage estimates are recomputed from the refreshed shim results.
+func (s *DropMatrix) RefreshShimMaxAccumulableDropMatrixResults(ctx context.Context, server string) error {
+	for _, showClosedZones := range []bool{true, false} {
 		key := server + constant.CacheSep + strconv.FormatBool(showClosedZones)
-		calculated, err := cache.ShimMaxAccumulableDropMatrixResults.MutexGetSet(key, &results, valueFunc, 24*time.Hour)
+		if err := cache.ShimMaxAccumulableDropMatrixResults.Revalidate(ctx, key, s.shimMaxAccumulableDropMatrixResultsFunc(server, showClosedZones), constant.ShimResultsExpireTTL); err != nil {
+			return err
+		}
+	}
+	for _, showClosedZones := range []bool{true, false} {
+		key := server + constant.CacheSep + strconv.FormatBool(showClosedZones)
+		if err := cache.ShrunkMaxAccumulableDropMatrixResults.Revalidate(ctx, key, s.shrunkMaxAccumulableDropMatrixResultsFunc(server, showClosedZones), constant.ShimResultsExpireTTL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DropMatrix) shrunkMaxAccumulableDropMatrixResultsFunc(server string, showClosedZones bool) func(ctx context.Context) (*modelv2.DropMatrixQueryResult, error) {
+	return func(ctx context.Context) (*modelv2.DropMatrixQueryResult, error) {
+		shimResults, err := s.GetShimMaxAccumulableDropMatrixResults(ctx, server, showClosedZones, "", "", null.NewInt(0, false))
 		if err != nil {
 			return nil, err
-		} else if calculated {
-			cache.LastModifiedTime.Set("[shimMaxAccumulableDropMatrixResults#server|showClosedZoned:"+key+"]", time.Now(), 0)
 		}
-		return &results, nil
-	} else {
-		return valueFunc()
+		return s.ApplyShrinkageEstimates(ctx, server, shimResults)
+	}
+}
+
+func (s *DropMatrix) shimMaxAccumulableDropMatrixResultsFunc(server string, showClosedZones bool) func(ctx context.Context) (*modelv2.DropMatrixQueryResult, error) {
+	return func(ctx context.Context) (*modelv2.DropMatrixQueryResult, error) {
+		savedDropMatrixResults, err := s.getMaxAccumulableDropMatrixResults(ctx, server, null.NewInt(0, false), constant.SourceCategoryAll)
+		if err != nil {
+			return nil, err
+		}
+		slowResults, err := s.applyShimForDropMatrixQuery(ctx, server, showClosedZones, "", "", savedDropMatrixResults)
+		if err != nil {
+			return nil, err
+		}
+		return slowResults, nil
 	}
 }
 
@@ -110,7 +173,7 @@ func (s *DropMatrix) GetShimCustomizedDropMatrixResults(
 	return s.applyShimForDropMatrixQuery(ctx, server, true, "", "", customizedDropMatrixQueryResult)
 }
 
-func (s *DropMatrix) RefreshAllDropMatrixElements(ctx context.Context, server string, sourceCategories []string) error {
+func (s *DropMatrix) RefreshAllDropMatrixElements(ctx context.Context, server string, sourceCategories []string, configuredCategories []string) error {
 	allTimeRanges, err := s.TimeRangeService.GetTimeRangesByServer(ctx, server)
 	if err != nil {
 		return err
@@ -128,20 +191,231 @@ func (s *DropMatrix) RefreshAllDropMatrixElements(ctx context.Context, server st
 		}
 		return currentBatch, nil
 	})
+	if err != nil {
+		return err
+	}
 
 	// process results
-	if err := s.DropMatrixElementService.BatchSaveElements(ctx, elements, server); err != nil {
+	if err := s.DropMatrixElementService.BatchSaveElements(ctx, elements, server, sourceCategories, configuredCategories); err != nil {
+		return err
+	}
+	if err := s.DropMatrixSnapshotService.RecordSnapshots(ctx, server, elements); err != nil {
 		return err
 	}
-	if err := cache.ShimMaxAccumulableDropMatrixResults.Delete(server + constant.CacheSep + "true"); err != nil {
+	if lo.Contains(sourceCategories, constant.SourceCategoryAll) {
+		if err := s.RefreshShimMaxAccumulableDropMatrixResults(ctx, server); err != nil {
+			return err
+		}
+	}
+	if err := cache.ItemDrops.Flush(); err != nil {
 		return err
 	}
-	if err := cache.ShimMaxAccumulableDropMatrixResults.Delete(server + constant.CacheSep + "false"); err != nil {
+	if err := cache.GachaBoxDistribution.Flush(); err != nil {
 		return err
 	}
 	return nil
 }
 
+// Cache: itemDrops#server|arkItemId:{server}|{arkItemId}, 24 hrs, records last modified time
+func (s *DropMatrix) GetItemDrops(ctx context.Context, server string, arkItemId string) (*model.ItemDrops, error) {
+	valueFunc := func() (*model.ItemDrops, error) {
+		item, err := s.ItemService.GetItemByArkId(ctx, arkItemId)
+		if err != nil {
+			return nil, err
+		}
+		elements, err := s.DropMatrixElementService.GetElementsByServerAndSourceCategoryAndItemId(ctx, server, constant.SourceCategoryAll, item.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		stagesMapById, err := s.StageService.GetStagesMapById(ctx)
+		if err != nil {
+			return nil, err
+		}
+		timeRangesMap, err := s.TimeRangeService.GetTimeRangesMap(ctx, server)
+		if err != nil {
+			return nil, err
+		}
+		currentDropInfos, err := s.DropInfoService.GetCurrentDropInfosByServer(ctx, server)
+		if err != nil {
+			return nil, err
+		}
+		openingStageIds := make(map[int]bool)
+		for _, dropInfo := range currentDropInfos {
+			openingStageIds[dropInfo.StageID] = true
+		}
+
+		stagesById := make(map[int]*model.ItemDropStage)
+		for _, el := range elements {
+			stage, ok := stagesMapById[el.StageID]
+			if !ok {
+				continue
+			}
+			timeRange, ok := timeRangesMap[el.RangeID]
+			if !ok {
+				continue
+			}
+
+			itemDropStage, ok := stagesById[el.StageID]
+			if !ok {
+				itemDropStage = &model.ItemDropStage{
+					StageID: stage.ArkStageID,
+					Open:    openingStageIds[el.StageID],
+					Ranges:  make([]*model.ItemDropRange, 0),
+				}
+				stagesById[el.StageID] = itemDropStage
+			}
+
+			itemDropRange := &model.ItemDropRange{
+				StartTime: timeRange.StartTime.UnixMilli(),
+				Quantity:  el.Quantity,
+				Times:     el.Times,
+			}
+			if endTime := timeRange.EndTime.UnixMilli(); endTime != constant.FakeEndTimeMilli {
+				itemDropRange.EndTime = null.NewInt(endTime, true)
+			}
+			if el.Times > 0 {
+				itemDropRange.Rate = util.RoundFloat64(float64(el.Quantity)/float64(el.Times), 6)
+			}
+			if el.Quantity > 0 && stage.Sanity.Valid && stage.Sanity.Int64 > 0 {
+				itemDropRange.SanityPerItem = null.FloatFrom(util.RoundFloat64(float64(stage.Sanity.Int64)*float64(el.Times)/float64(el.Quantity), 4))
+			}
+			itemDropStage.Ranges = append(itemDropStage.Ranges, itemDropRange)
+		}
+
+		stages := make([]*model.ItemDropStage, 0, len(stagesById))
+		for _, itemDropStage := range stagesById {
+			sort.Slice(itemDropStage.Ranges, func(i, j int) bool {
+				return itemDropStage.Ranges[i].StartTime < itemDropStage.Ranges[j].StartTime
+			})
+			stages = append(stages, itemDropStage)
+		}
+		sort.Slice(stages, func(i, j int) bool {
+			return stages[i].StageID < stages[j].StageID
+		})
+
+		return &model.ItemDrops{
+			ItemID: arkItemId,
+			Server: server,
+			Stages: stages,
+		}, nil
+	}
+
+	var results model.ItemDrops
+	key := server + constant.CacheSep + arkItemId
+	calculated, err := cache.ItemDrops.MutexGetSet(key, &results, valueFunc, 24*time.Hour)
+	if err != nil {
+		return nil, err
+	} else if calculated {
+		cache.LastModifiedTime.Set("[itemDrops#server|arkItemId:"+key+"]", time.Now(), 0)
+	}
+	return &results, nil
+}
+
+// ApplyShrinkageEstimates returns a copy of the drop matrix with every element annotated with its drop rate shrunk
+// toward a prior, which is derived from the same item's rates on other stages in zones of the same category, or if
+// those are not sampled enough, on other stages of the same sanity cost. Elements of gacha box stages, and elements
+// with no sufficiently sampled comparable stages, are left unannotated.
+//
+// The prior is always derived from the global drop matrix of the server, including closed zones, so that personal
+// or filtered matrices are shrunk toward the rates of every player rather than toward their own few samples.
+func (s *DropMatrix) ApplyShrinkageEstimates(ctx context.Context, server string, queryResult *modelv2.DropMatrixQueryResult) (*modelv2.DropMatrixQueryResult, error) {
+	globalResult, err := s.GetShimMaxAccumulableDropMatrixResults(ctx, server, true, "", "", null.NewInt(0, false))
+	if err != nil {
+		return nil, err
+	}
+	stagesMapByArkId, err := s.StageService.GetStagesMapByArkId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	zones, err := s.ZoneService.GetZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	zoneCategories := make(map[int]string, len(zones))
+	for _, zone := range zones {
+		zoneCategories[zone.ZoneID] = zone.Category
+	}
+
+	// comparable elements of the same item, grouped by zone category and by sanity cost respectively
+	byZoneCategory := make(map[string][]*modelv2.OneDropMatrixElement)
+	bySanity := make(map[string][]*modelv2.OneDropMatrixElement)
+	zoneCategoryKey := func(el *modelv2.OneDropMatrixElement, stage *model.Stage) string {
+		return el.ItemID + constant.CacheSep + zoneCategories[stage.ZoneID]
+	}
+	sanityKey := func(el *modelv2.OneDropMatrixElement, stage *model.Stage) string {
+		return el.ItemID + constant.CacheSep + strconv.FormatInt(stage.Sanity.Int64, 10)
+	}
+	for _, el := range globalResult.Matrix {
+		stage, ok := stagesMapByArkId[el.StageID]
+		if !ok || stage.ExtraProcessType.String == constant.ExtraProcessTypeGachaBox {
+			continue
+		}
+		byZoneCategory[zoneCategoryKey(el, stage)] = append(byZoneCategory[zoneCategoryKey(el, stage)], el)
+		if stage.Sanity.Valid {
+			bySanity[sanityKey(el, stage)] = append(bySanity[sanityKey(el, stage)], el)
+		}
+	}
+
+	results := &modelv2.DropMatrixQueryResult{
+		Matrix: make([]*modelv2.OneDropMatrixElement, 0, len(queryResult.Matrix)),
+	}
+	for _, el := range queryResult.Matrix {
+		// elements are shared with the cached matrix, so never modify them in place
+		annotated := *el
+		results.Matrix = append(results.Matrix, &annotated)
+
+		stage, ok := stagesMapByArkId[el.StageID]
+		if !ok || stage.ExtraProcessType.String == constant.ExtraProcessTypeGachaBox {
+			continue
+		}
+
+		priorSource := constant.ShrinkagePriorSourceZoneCategory
+		samples := comparableSamples(byZoneCategory[zoneCategoryKey(el, stage)], el.StageID)
+		if totalTimes(samples) < constant.ShrinkageMinPriorTimes && stage.Sanity.Valid {
+			priorSource = constant.ShrinkagePriorSourceSanityTier
+			samples = comparableSamples(bySanity[sanityKey(el, stage)], el.StageID)
+		}
+		if totalTimes(samples) < constant.ShrinkageMinPriorTimes {
+			continue
+		}
+
+		priorRate, priorStrength, ok := util.EstimateGammaPrior(samples)
+		if !ok {
+			priorStrength = constant.ShrinkageDefaultPriorStrength
+		}
+		priorStrength = math.Max(constant.ShrinkageMinPriorStrength, math.Min(constant.ShrinkageMaxPriorStrength, priorStrength))
+
+		annotated.Shrinkage = &modelv2.DropRateEstimate{
+			Rate:                util.RoundFloat64(util.ShrinkRate(el.Quantity, el.Times, priorRate, priorStrength), 6),
+			PriorRate:           util.RoundFloat64(priorRate, 6),
+			PriorSource:         priorSource,
+			PriorStrength:       util.RoundFloat64(priorStrength, 2),
+			EffectiveSampleSize: util.RoundFloat64(float64(el.Times)+priorStrength, 2),
+		}
+	}
+	return results, nil
+}
+
+// comparableSamples converts elements to rate samples, excluding those of the given stage itself.
+func comparableSamples(elements []*modelv2.OneDropMatrixElement, excludedArkStageId string) []util.RateSample {
+	samples := make([]util.RateSample, 0, len(elements))
+	for _, el := range elements {
+		if el.StageID == excludedArkStageId || el.Times == 0 {
+			continue
+		}
+		samples = append(samples, util.RateSample{Quantity: el.Quantity, Times: el.Times})
+	}
+	return samples
+}
+
+func totalTimes(samples []util.RateSample) int {
+	total := 0
+	for _, sample := range samples {
+		total += sample.Times
+	}
+	return total
+}
+
 // calc DropMatrixQueryResult for customized conditions
 func (s *DropMatrix) QueryDropMatrix(
 	ctx context.Context, server string, timeRanges []*model.TimeRange, stageIdFilter []int, itemIdFilter []int, accountId null.Int, sourceCategory string,
@@ -17,8 +17,8 @@ func NewDropMatrixElement(dropMatrixElementRepo *repo.DropMatrixElement) *DropMa
 	}
 }
 
-func (s *DropMatrixElement) BatchSaveElements(ctx context.Context, elements []*model.DropMatrixElement, server string) error {
-	return s.DropMatrixElementRepo.BatchSaveElements(ctx, elements, server)
+func (s *DropMatrixElement) BatchSaveElements(ctx context.Context, elements []*model.DropMatrixElement, server string, sourceCategories []string, configuredCategories []string) error {
+	return s.DropMatrixElementRepo.BatchSaveElements(ctx, elements, server, sourceCategories, configuredCategories)
 }
 
 func (s *DropMatrixElement) DeleteByServer(ctx context.Context, server string) error {
@@ -28,3 +28,7 @@ func (s *DropMatrixElement) DeleteByServer(ctx context.Context, server string) e
 func (s *DropMatrixElement) GetElementsByServerAndSourceCategory(ctx context.Context, server string, sourceCategory string) ([]*model.DropMatrixElement, error) {
 	return s.DropMatrixElementRepo.GetElementsByServerAndSourceCategory(ctx, server, sourceCategory)
 }
+
+func (s *DropMatrixElement) GetElementsByServerAndSourceCategoryAndItemId(ctx context.Context, server string, sourceCategory string, itemId int) ([]*model.DropMatrixElement, error) {
+	return s.DropMatrixElementRepo.GetElementsByServerAndSourceCategoryAndItemId(ctx, server, sourceCategory, itemId)
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/penguin-statistics/backend-next/internal/config"
+	"github.com/penguin-statistics/backend-next/internal/constant"
+	"github.com/penguin-statistics/backend-next/internal/model"
+	"github.com/penguin-statistics/backend-next/internal/model/types"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
+	"github.com/penguin-statistics/backend-next/internal/repo"
+)
+
+const dropMatrixSnapshotsListLimit = 200
+
+type DropMatrixSnapshot struct {
+	ConfigReloader         *config.Reloader
+	DropMatrixSnapshotRepo *repo.DropMatrixSnapshot
+	StageService           *Stage
+	ItemService            *Item
+}
+
+func NewDropMatrixSnapshot(configReloader *config.Reloader, dropMatrixSnapshotRepo *repo.DropMatrixSnapshot, stageService *Stage, itemService *Item) *DropMatrixSnapshot {
+	return &DropMatrixSnapshot{
+		ConfigReloader:         configReloader,
+		DropMatrixSnapshotRepo: dropMatrixSnapshotRepo,
+		StageService:           stageService,
+		ItemService:            itemService,
+	}
+}
+
+// RecordSnapshots records one snapshot per source category present in the given elements, unless the elements are
+// the same as in the latest snapshot of the source category, and deletes snapshots older than the retention.
+func (s *DropMatrixSnapshot) RecordSnapshots(ctx context.Context, server string, elements []*model.DropMatrixElement) error {
+	elementsBySourceCategory := make(map[string][]*model.DropMatrixSnapshotElement)
+	for _, el := range elements {
+		elementsBySourceCategory[el.SourceCategory] = append(elementsBySourceCategory[el.SourceCategory], &model.DropMatrixSnapshotElement{
+			StageID:  el.StageID,
+			ItemID:   el.ItemID,
+			RangeID:  el.RangeID,
+			Quantity: el.Quantity,
+			Times:    el.Times,
+		})
+	}
+
+	retention := s.ConfigReloader.Current().DropMatrixSnapshotRetention
+	for sourceCategory, snapshotElements := range elementsBySourceCategory {
+		hash, err := hashSnapshotElements(snapshotElements)
+		if err != nil {
+			return err
+		}
+		latestHash, err := s.DropMatrixSnapshotRepo.GetLatestSnapshotHash(ctx, server, sourceCategory)
+		if err != nil {
+			return err
+		}
+		if hash != latestHash {
+			err = s.DropMatrixSnapshotRepo.CreateSnapshot(ctx, &model.DropMatrixSnapshot{
+				Server:         server,
+				SourceCategory: sourceCategory,
+				ElementCount:   len(snapshotElements),
+				Hash:           hash,
+				Elements:       snapshotElements,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if retention > 0 {
+			deleted, err := s.DropMatrixSnapshotRepo.DeleteSnapshotsBefore(ctx, server, sourceCategory, time.Now().Add(-retention))
+			if err != nil {
+				return err
+			}
+			if deleted > 0 {
+				log.Info().
+					Str("server", server).
+					Str("sourceCategory", sourceCategory).
+					Int64("deleted", deleted).
+					Msg("deleted drop matrix snapshots past retention")
+			}
+		}
+	}
+	return nil
+}
+
+// hashSnapshotElements sorts the elements in place, so that the hash does not depend on the order they were
+// calculated in, and returns the hex-encoded SHA-256 of their JSON encoding.
+func hashSnapshotElements(elements []*model.DropMatrixSnapshotElement) (string, error) {
+	sort.Slice(elements, func(i, j int) bool {
+		a, b := elements[i], elements[j]
+		if a.StageID != b.StageID {
+			return a.StageID < b.StageID
+		}
+		if a.ItemID != b.ItemID {
+			return a.ItemID < b.ItemID
+		}
+		return a.RangeID < b.RangeID
+	})
+	b, err := json.Marshal(elements)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (s *DropMatrixSnapshot) GetSnapshots(ctx context.Context, query *types.DropMatrixSnapshotsQuery) ([]*model.DropMatrixSnapshot, error) {
+	sourceCategory := query.SourceCategory
+	if sourceCategory == "" {
+		sourceCategory = constant.SourceCategoryAll
+	}
+	return s.DropMatrixSnapshotRepo.GetSnapshots(ctx, strings.ToUpper(query.Server), sourceCategory, dropMatrixSnapshotsListLimit)
+}
+
+// GetChanges compares the latest snapshots recorded before the two requested times, and returns the elements
+// whose drop rate changed by at least the threshold, as well as the elements that only appear in one of them.
+func (s *DropMatrixSnapshot) GetChanges(ctx context.Context, query *types.DropMatrixChangesQuery) (*model.DropMatrixChanges, error) {
+	server := strings.ToUpper(query.Server)
+	sourceCategory := query.SourceCategory
+	if sourceCategory == "" {
+		sourceCategory = constant.SourceCategoryAll
+	}
+
+	from, err := s.DropMatrixSnapshotRepo.GetLatestSnapshotBefore(ctx, server, sourceCategory, time.UnixMilli(query.From))
+	if errors.Is(err, pgerr.ErrNotFound) {
+		return nil, pgerr.ErrInvalidReq.Msg("no snapshot was recorded before the from time")
+	} else if err != nil {
+		return nil, err
+	}
+	to, err := s.DropMatrixSnapshotRepo.GetLatestSnapshotBefore(ctx, server, sourceCategory, time.UnixMilli(query.To))
+	if err != nil {
+		return nil, err
+	}
+
+	stagesMap, err := s.StageService.GetStagesMapById(ctx)
+	if err != nil {
+		return nil, err
+	}
+	itemsMap, err := s.ItemService.GetItemsMapById(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matches := func(el *model.DropMatrixSnapshotElement) bool {
+		if query.StageID != "" {
+			if stage, ok := stagesMap[el.StageID]; !ok || stage.ArkStageID != query.StageID {
+				return false
+			}
+		}
+		if query.ItemID != "" {
+			if item, ok := itemsMap[el.ItemID]; !ok || item.ArkItemID != query.ItemID {
+				return false
+			}
+		}
+		return true
+	}
+
+	type elementKey struct {
+		stageId, itemId, rangeId int
+	}
+	fromElements := make(map[elementKey]*model.DropMatrixSnapshotElement)
+	for _, el := range from.Elements {
+		if matches(el) {
+			fromElements[elementKey{el.StageID, el.ItemID, el.RangeID}] = el
+		}
+	}
+	toElements := make(map[elementKey]*model.DropMatrixSnapshotElement)
+	for _, el := range to.Elements {
+		if matches(el) {
+			toElements[elementKey{el.StageID, el.ItemID, el.RangeID}] = el
+		}
+	}
+	keys := make(map[elementKey]struct{}, len(toElements))
+	for key := range fromElements {
+		keys[key] = struct{}{}
+	}
+	for key := range toElements {
+		keys[key] = struct{}{}
+	}
+
+	changes := make([]*model.DropMatrixElementChange, 0)
+	for key := range keys {
+		change := &model.DropMatrixElementChange{
+			RangeID: key.rangeId,
+		}
+		if stage, ok := stagesMap[key.stageId]; ok {
+			change.StageID = stage.ArkStageID
+		}
+		if item, ok := itemsMap[key.itemId]; ok {
+			change.ItemID = item.ArkItemID
+		}
+
+		fromEl, inFrom := fromElements[key]
+		toEl, inTo := toElements[key]
+		if inFrom {
+			change.FromTimes = fromEl.Times
+			change.FromQuantity = fromEl.Quantity
+			change.FromRate = dropRate(fromEl.Quantity, fromEl.Times)
+		}
+		if inTo {
+			change.ToTimes = toEl.Times
+			change.ToQuantity = toEl.Quantity
+			change.ToRate = dropRate(toEl.Quantity, toEl.Times)
+		}
+		change.RateDelta = change.ToRate - change.FromRate
+		if timesDelta := change.ToTimes - change.FromTimes; timesDelta > 0 {
+			change.IntervalRate = null.FloatFrom(float64(change.ToQuantity-change.FromQuantity) / float64(timesDelta))
+		}
+
+		if inFrom && inTo && math.Abs(change.RateDelta) < query.Threshold {
+			continue
+		}
+		if inFrom && inTo && change.RateDelta == 0 && change.ToTimes == change.FromTimes {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return math.Abs(changes[i].RateDelta) > math.Abs(changes[j].RateDelta)
+	})
+
+	// snapshots in the response are for reference only; elements are omitted
+	from.Elements = nil
+	to.Elements = nil
+
+	return &model.DropMatrixChanges{
+		Server:         server,
+		SourceCategory: sourceCategory,
+		From:           from,
+		To:             to,
+		Changes:        changes,
+	}, nil
+}
+
+func dropRate(quantity, times int) float64 {
+	if times == 0 {
+		return 0
+	}
+	return float64(quantity) / float64(times)
+}
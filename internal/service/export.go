@@ -0,0 +1,574 @@
+package service
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/penguin-statistics/backend-next/internal/config"
+	"github.com/penguin-statistics/backend-next/internal/constant"
+	"github.com/penguin-statistics/backend-next/internal/model"
+	"github.com/penguin-statistics/backend-next/internal/repo"
+)
+
+const (
+	exportManifestFile = "manifest.json"
+	exportDateLayout   = "2006-01-02"
+
+	exportSnapshotsDir = "snapshots"
+	exportReportsDir   = "reports"
+
+	exportFormatCSV   = "csv"
+	exportFormatJSONL = "jsonl.gz"
+)
+
+// Export writes open data exports of the calculated matrices and anonymised drop reports to a local
+// directory, and maintains a manifest of all exported files.
+type Export struct {
+	Dir string
+	// ReportsBackfillDays is the number of past days looked back for drop reports not exported yet
+	ReportsBackfillDays int
+
+	DropMatrixElementService    *DropMatrixElement
+	PatternMatrixElementService *PatternMatrixElement
+	TrendElementService         *TrendElement
+	TimeRangeService            *TimeRange
+	StageService                *Stage
+	ItemService                 *Item
+	DropReportRepo              *repo.DropReport
+	DropPatternElementRepo      *repo.DropPatternElement
+
+	// m prevents concurrent runs from writing the same files
+	m sync.Mutex
+}
+
+func NewExport(
+	conf *config.Config,
+	dropMatrixElementService *DropMatrixElement,
+	patternMatrixElementService *PatternMatrixElement,
+	trendElementService *TrendElement,
+	timeRangeService *TimeRange,
+	stageService *Stage,
+	itemService *Item,
+	dropReportRepo *repo.DropReport,
+	dropPatternElementRepo *repo.DropPatternElement,
+) *Export {
+	return &Export{
+		Dir:                         conf.ExportDir,
+		ReportsBackfillDays:         conf.ExportReportsBackfillDays,
+		DropMatrixElementService:    dropMatrixElementService,
+		PatternMatrixElementService: patternMatrixElementService,
+		TrendElementService:         trendElementService,
+		TimeRangeService:            timeRangeService,
+		StageService:                stageService,
+		ItemService:                 itemService,
+		DropReportRepo:              dropReportRepo,
+		DropPatternElementRepo:      dropPatternElementRepo,
+	}
+}
+
+type exportRow interface {
+	csvRecord() []string
+}
+
+type exportDataset struct {
+	name   string
+	header []string
+	rows   []exportRow
+}
+
+type exportedDropMatrixRow struct {
+	StageID  string   `json:"stageId"`
+	ItemID   string   `json:"itemId"`
+	Start    int64    `json:"start"`
+	End      null.Int `json:"end"`
+	Times    int      `json:"times"`
+	Quantity int      `json:"quantity"`
+}
+
+func (r *exportedDropMatrixRow) csvRecord() []string {
+	return []string{r.StageID, r.ItemID, strconv.FormatInt(r.Start, 10), formatNullInt(r.End), strconv.Itoa(r.Times), strconv.Itoa(r.Quantity)}
+}
+
+type exportedPatternMatrixRow struct {
+	StageID  string          `json:"stageId"`
+	Drops    []*exportedDrop `json:"drops"`
+	Start    int64           `json:"start"`
+	End      null.Int        `json:"end"`
+	Times    int             `json:"times"`
+	Quantity int             `json:"quantity"`
+}
+
+func (r *exportedPatternMatrixRow) csvRecord() []string {
+	return []string{r.StageID, formatExportedDrops(r.Drops), strconv.FormatInt(r.Start, 10), formatNullInt(r.End), strconv.Itoa(r.Times), strconv.Itoa(r.Quantity)}
+}
+
+type exportedTrendRow struct {
+	StageID  string `json:"stageId"`
+	ItemID   string `json:"itemId"`
+	Start    int64  `json:"start"`
+	End      int64  `json:"end"`
+	Times    int    `json:"times"`
+	Quantity int    `json:"quantity"`
+}
+
+func (r *exportedTrendRow) csvRecord() []string {
+	return []string{r.StageID, r.ItemID, strconv.FormatInt(r.Start, 10), strconv.FormatInt(r.End, 10), strconv.Itoa(r.Times), strconv.Itoa(r.Quantity)}
+}
+
+type exportedDropReportRow struct {
+	StageID   string          `json:"stageId"`
+	Drops     []*exportedDrop `json:"drops"`
+	Times     int             `json:"times"`
+	CreatedAt int64           `json:"createdAt"`
+	Source    string          `json:"source"`
+}
+
+func (r *exportedDropReportRow) csvRecord() []string {
+	return []string{r.StageID, formatExportedDrops(r.Drops), strconv.Itoa(r.Times), strconv.FormatInt(r.CreatedAt, 10), r.Source}
+}
+
+type exportedDrop struct {
+	ItemID   string `json:"itemId"`
+	Quantity int    `json:"quantity"`
+}
+
+// formatExportedDrops formats drops as `itemId:quantity` segments joined by `|`
+func formatExportedDrops(drops []*exportedDrop) string {
+	segments := make([]string, len(drops))
+	for i, drop := range drops {
+		segments[i] = drop.ItemID + ":" + strconv.Itoa(drop.Quantity)
+	}
+	return strings.Join(segments, "|")
+}
+
+func formatNullInt(i null.Int) string {
+	if !i.Valid {
+		return ""
+	}
+	return strconv.FormatInt(i.Int64, 10)
+}
+
+// exportLookups holds the mappings from numerical IDs to the string form IDs used in the exports
+type exportLookups struct {
+	stages   map[int]*model.Stage
+	items    map[int]*model.Item
+	patterns map[int][]*exportedDrop
+}
+
+func (l *exportLookups) arkStageId(stageId int) string {
+	if stage, ok := l.stages[stageId]; ok {
+		return stage.ArkStageID
+	}
+	return ""
+}
+
+func (l *exportLookups) arkItemId(itemId int) string {
+	if item, ok := l.items[itemId]; ok {
+		return item.ArkItemID
+	}
+	return ""
+}
+
+// Run exports snapshots of the drop matrix, pattern matrix and trend elements of every server for the date of now,
+// as well as the anonymised drop reports of every past day not exported yet, looking back from the previous day to
+// the latest exported day within ReportsBackfillDays, and then regenerates the manifest.
+func (s *Export) Run(ctx context.Context, now time.Time) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	lookups, err := s.getLookups(ctx)
+	if err != nil {
+		return err
+	}
+
+	now = now.UTC()
+	today := now.Truncate(24 * time.Hour)
+	yesterday := today.Add(-24 * time.Hour)
+
+	for _, server := range constant.Servers {
+		datasets, err := s.getSnapshotDatasets(ctx, server, lookups)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get snapshot datasets for server %s", server)
+		}
+		for _, dataset := range datasets {
+			if err := s.writeDataset(path.Join(exportSnapshotsDir, today.Format(exportDateLayout), server), dataset); err != nil {
+				return err
+			}
+		}
+
+		// drop reports of a day are only exported once the day is over, and never rewritten afterwards
+		for _, day := range s.unexportedReportDays(server, yesterday) {
+			dataset, err := s.getDropReportsDataset(ctx, server, day, day.Add(24*time.Hour), lookups)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get drop reports dataset for server %s of %s", server, day.Format(exportDateLayout))
+			}
+			if err := s.writeDataset(s.reportsDir(server, day), dataset); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.writeManifest()
+}
+
+// unexportedReportDays returns the days whose drop reports of the server have not been exported yet, from the day
+// after the latest exported one to the given last day, but at most ReportsBackfillDays of them, in chronological
+// order.
+func (s *Export) unexportedReportDays(server string, last time.Time) []time.Time {
+	days := make([]time.Time, 0)
+	for day := last; len(days) < s.ReportsBackfillDays; day = day.Add(-24 * time.Hour) {
+		if _, err := os.Stat(filepath.Join(s.Dir, filepath.FromSlash(s.reportsDir(server, day)), "drop_reports."+exportFormatJSONL)); err == nil {
+			break
+		}
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Before(days[j])
+	})
+	return days
+}
+
+// reportsDir returns the directory of the drop reports of the server of the day, relative to the export directory
+func (s *Export) reportsDir(server string, day time.Time) string {
+	return path.Join(exportReportsDir, day.Format(exportDateLayout), server)
+}
+
+// GetManifest returns the manifest of all exported files. An empty manifest is returned if nothing has been exported yet.
+func (s *Export) GetManifest() (*model.ExportManifest, error) {
+	var manifest model.ExportManifest
+	content, err := os.ReadFile(filepath.Join(s.Dir, exportManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &model.ExportManifest{
+			Files: make([]*model.ExportManifestFile, 0),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+func (s *Export) getLookups(ctx context.Context) (*exportLookups, error) {
+	stages, err := s.StageService.GetStagesMapById(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.ItemService.GetItemsMapById(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lookups := &exportLookups{
+		stages:   stages,
+		items:    items,
+		patterns: make(map[int][]*exportedDrop),
+	}
+
+	elements, err := s.DropPatternElementRepo.GetDropPatternElements(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, element := range elements {
+		lookups.patterns[element.DropPatternID] = append(lookups.patterns[element.DropPatternID], &exportedDrop{
+			ItemID:   lookups.arkItemId(element.ItemID),
+			Quantity: element.Quantity,
+		})
+	}
+	for _, drops := range lookups.patterns {
+		sort.Slice(drops, func(i, j int) bool {
+			return drops[i].ItemID < drops[j].ItemID
+		})
+	}
+
+	return lookups, nil
+}
+
+func (s *Export) getSnapshotDatasets(ctx context.Context, server string, lookups *exportLookups) ([]*exportDataset, error) {
+	timeRangesMap, err := s.TimeRangeService.GetTimeRangesMap(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	timeRangeBounds := func(rangeId int) (int64, null.Int) {
+		timeRange, ok := timeRangesMap[rangeId]
+		if !ok || timeRange.StartTime == nil {
+			return 0, null.NewInt(0, false)
+		}
+		end := null.NewInt(0, false)
+		if timeRange.EndTime != nil && timeRange.EndTime.UnixMilli() != constant.FakeEndTimeMilli {
+			end = null.IntFrom(timeRange.EndTime.UnixMilli())
+		}
+		return timeRange.StartTime.UnixMilli(), end
+	}
+
+	dropMatrixElements, err := s.DropMatrixElementService.GetElementsByServerAndSourceCategory(ctx, server, constant.SourceCategoryAll)
+	if err != nil {
+		return nil, err
+	}
+	dropMatrix := &exportDataset{
+		name:   "drop_matrix",
+		header: []string{"stageId", "itemId", "start", "end", "times", "quantity"},
+		rows:   make([]exportRow, 0, len(dropMatrixElements)),
+	}
+	for _, el := range dropMatrixElements {
+		start, end := timeRangeBounds(el.RangeID)
+		dropMatrix.rows = append(dropMatrix.rows, &exportedDropMatrixRow{
+			StageID:  lookups.arkStageId(el.StageID),
+			ItemID:   lookups.arkItemId(el.ItemID),
+			Start:    start,
+			End:      end,
+			Times:    el.Times,
+			Quantity: el.Quantity,
+		})
+	}
+
+	patternMatrixElements, err := s.PatternMatrixElementService.GetElementsByServerAndSourceCategory(ctx, server, constant.SourceCategoryAll)
+	if err != nil {
+		return nil, err
+	}
+	patternMatrix := &exportDataset{
+		name:   "pattern_matrix",
+		header: []string{"stageId", "drops", "start", "end", "times", "quantity"},
+		rows:   make([]exportRow, 0, len(patternMatrixElements)),
+	}
+	for _, el := range patternMatrixElements {
+		start, end := timeRangeBounds(el.RangeID)
+		drops := lookups.patterns[el.PatternID]
+		if drops == nil {
+			drops = make([]*exportedDrop, 0)
+		}
+		patternMatrix.rows = append(patternMatrix.rows, &exportedPatternMatrixRow{
+			StageID:  lookups.arkStageId(el.StageID),
+			Drops:    drops,
+			Start:    start,
+			End:      end,
+			Times:    el.Times,
+			Quantity: el.Quantity,
+		})
+	}
+
+	trendElements, err := s.TrendElementService.GetElementsByServerAndSourceCategory(ctx, server, constant.SourceCategoryAll)
+	if err != nil {
+		return nil, err
+	}
+	trend := &exportDataset{
+		name:   "trend",
+		header: []string{"stageId", "itemId", "start", "end", "times", "quantity"},
+		rows:   make([]exportRow, 0, len(trendElements)),
+	}
+	for _, el := range trendElements {
+		if el.StartTime == nil || el.EndTime == nil {
+			continue
+		}
+		trend.rows = append(trend.rows, &exportedTrendRow{
+			StageID:  lookups.arkStageId(el.StageID),
+			ItemID:   lookups.arkItemId(el.ItemID),
+			Start:    el.StartTime.UnixMilli(),
+			End:      el.EndTime.UnixMilli(),
+			Times:    el.Times,
+			Quantity: el.Quantity,
+		})
+	}
+
+	return []*exportDataset{dropMatrix, patternMatrix, trend}, nil
+}
+
+func (s *Export) getDropReportsDataset(ctx context.Context, server string, start time.Time, end time.Time, lookups *exportLookups) (*exportDataset, error) {
+	reports, err := s.DropReportRepo.GetReliableDropReportsForExport(ctx, server, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	dataset := &exportDataset{
+		name:   "drop_reports",
+		header: []string{"stageId", "drops", "times", "createdAt", "source"},
+		rows:   make([]exportRow, 0, len(reports)),
+	}
+	for _, report := range reports {
+		drops := lookups.patterns[report.PatternID]
+		if drops == nil {
+			drops = make([]*exportedDrop, 0)
+		}
+		dataset.rows = append(dataset.rows, &exportedDropReportRow{
+			StageID:   lookups.arkStageId(report.StageID),
+			Drops:     drops,
+			Times:     report.Times,
+			CreatedAt: report.CreatedAt.UnixMilli(),
+			Source:    report.SourceName.String,
+		})
+	}
+	return dataset, nil
+}
+
+// writeDataset writes the dataset in every export format under the given directory relative to the export directory
+func (s *Export) writeDataset(dir string, dataset *exportDataset) error {
+	err := s.writeFileAtomically(path.Join(dir, dataset.name+"."+exportFormatCSV), func(w io.Writer) error {
+		cw := csv.NewWriter(w)
+		if err := cw.Write(dataset.header); err != nil {
+			return err
+		}
+		for _, row := range dataset.rows {
+			if err := cw.Write(row.csvRecord()); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s dataset as csv", dataset.name)
+	}
+
+	err = s.writeFileAtomically(path.Join(dir, dataset.name+"."+exportFormatJSONL), func(w io.Writer) error {
+		gw := gzip.NewWriter(w)
+		enc := json.NewEncoder(gw)
+		for _, row := range dataset.rows {
+			if err := enc.Encode(row); err != nil {
+				return err
+			}
+		}
+		return gw.Close()
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s dataset as jsonl.gz", dataset.name)
+	}
+
+	return nil
+}
+
+// writeFileAtomically writes to a temporary file first and renames it afterwards, so that a partially written
+// file is never served. The temporary file is a dotfile, which the export controller does not serve either.
+func (s *Export) writeFileAtomically(relPath string, write func(w io.Writer) error) error {
+	dest := filepath.Join(s.Dir, filepath.FromSlash(relPath))
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(dest), ".export-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), dest)
+}
+
+// writeManifest walks the export directory and writes the manifest. Checksums are reused from the previous manifest
+// for files whose size and modification time are unchanged.
+func (s *Export) writeManifest() error {
+	previous, err := s.GetManifest()
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read previous export manifest, recalculating all checksums")
+		previous = &model.ExportManifest{}
+	}
+	previousFiles := make(map[string]*model.ExportManifestFile, len(previous.Files))
+	for _, file := range previous.Files {
+		previousFiles[file.Path] = file
+	}
+
+	files := make([]*model.ExportManifestFile, 0)
+	err = filepath.WalkDir(s.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.Dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		// expected to be {kind}/{date}/{server}/{dataset}.{format}
+		segments := strings.Split(rel, "/")
+		if len(segments) != 4 || (segments[0] != exportSnapshotsDir && segments[0] != exportReportsDir) {
+			return nil
+		}
+		dataset, format, ok := strings.Cut(segments[3], ".")
+		if !ok || (format != exportFormatCSV && format != exportFormatJSONL) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		file := &model.ExportManifestFile{
+			Path:       rel,
+			Dataset:    dataset,
+			Server:     segments[2],
+			Date:       segments[1],
+			Format:     format,
+			Size:       info.Size(),
+			ModifiedAt: info.ModTime().UTC(),
+		}
+		if prev, ok := previousFiles[rel]; ok && prev.Size == file.Size && prev.ModifiedAt.Equal(file.ModifiedAt) {
+			file.SHA256 = prev.SHA256
+		} else {
+			checksum, err := sha256File(p)
+			if err != nil {
+				return err
+			}
+			file.SHA256 = checksum
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to walk export directory")
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	return s.writeFileAtomically(exportManifestFile, func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(&model.ExportManifest{
+			GeneratedAt: time.Now(),
+			Files:       files,
+		})
+	})
+}
+
+func sha256File(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
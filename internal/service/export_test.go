@@ -0,0 +1,50 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExportUnexportedReportDays(t *testing.T) {
+	last := time.Date(2022, 6, 10, 0, 0, 0, 0, time.UTC)
+	day := func(offset int) time.Time {
+		return last.AddDate(0, 0, -offset)
+	}
+
+	tests := []struct {
+		name     string
+		exported []int
+		days     []time.Time
+	}{
+		{name: "up to date", exported: []int{0}, days: []time.Time{}},
+		{name: "one day missing", exported: []int{1}, days: []time.Time{day(0)}},
+		// older gaps before the latest exported day are not looked at
+		{name: "several days missing", exported: []int{3, 5}, days: []time.Time{day(2), day(1), day(0)}},
+		{name: "nothing exported", exported: nil, days: []time.Time{day(2), day(1), day(0)}},
+	}
+	for _, tt := range tests {
+		s := &Export{Dir: t.TempDir(), ReportsBackfillDays: 3}
+		for _, offset := range tt.exported {
+			dir := filepath.Join(s.Dir, filepath.FromSlash(s.reportsDir("CN", day(offset))))
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "drop_reports."+exportFormatJSONL), nil, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		days := s.unexportedReportDays("CN", last)
+		if len(days) != len(tt.days) {
+			t.Errorf("%s: expected %d days, got %d", tt.name, len(tt.days), len(days))
+			continue
+		}
+		for i := range days {
+			if !days[i].Equal(tt.days[i]) {
+				t.Errorf("%s: expected day %d to be %s, got %s", tt.name, i, tt.days[i], days[i])
+			}
+		}
+	}
+}
@@ -3,38 +3,399 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
+	"github.com/uptrace/bun"
+	"gopkg.in/guregu/null.v3"
+
 	"github.com/penguin-statistics/backend-next/internal/constant"
+	"github.com/penguin-statistics/backend-next/internal/model"
 	"github.com/penguin-statistics/backend-next/internal/model/cache"
+	"github.com/penguin-statistics/backend-next/internal/model/types"
+	modelv2 "github.com/penguin-statistics/backend-next/internal/model/v2"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
 	"github.com/penguin-statistics/backend-next/internal/repo"
 )
 
 type Formula struct {
+	DB           *bun.DB
+	FormulaRepo  *repo.Formula
 	PropertyRepo *repo.Property
+	ItemService  *Item
 }
 
-func NewFormula(propertyRepo *repo.Property) *Formula {
+func NewFormula(db *bun.DB, formulaRepo *repo.Formula, propertyRepo *repo.Property, itemService *Item) *Formula {
 	return &Formula{
+		DB:           db,
+		FormulaRepo:  formulaRepo,
 		PropertyRepo: propertyRepo,
+		ItemService:  itemService,
 	}
 }
 
-// Cache: (singular) formula, 24hrs
-func (s *Formula) GetFormula(ctx context.Context) (json.RawMessage, error) {
-	var formula json.RawMessage
-	err := cache.Formula.Get(&formula)
+// legacyFormula is a crafting formula in the shape stored in the legacy formula property.
+type legacyFormula struct {
+	ID       string `json:"id"`
+	GoldCost int    `json:"goldCost"`
+	Costs    []struct {
+		ID    string `json:"id"`
+		Count int    `json:"count"`
+	} `json:"costs"`
+	ExtraOutcome []struct {
+		ID     string `json:"id"`
+		Weight int    `json:"weight"`
+	} `json:"extraOutcome"`
+}
+
+// GetFormulas returns all formulas. Until formulas are modified for the first time, e.g. by importing them, they
+// are read from the legacy formula property instead, so that formulas are served right after deployment.
+// Cache: (singular) formulas, 24hrs
+func (s *Formula) GetFormulas(ctx context.Context) ([]*model.Formula, error) {
+	var formulas []*model.Formula
+	err := cache.Formulas.MutexGetSet(&formulas, func() ([]*model.Formula, error) {
+		formulas, err := s.FormulaRepo.GetFormulas(ctx)
+		if err != nil || len(formulas) > 0 {
+			return formulas, err
+		}
+		// an empty table may as well be the result of deleting every formula on purpose
+		modified, err := s.FormulaRepo.HasRevisions(ctx)
+		if err != nil || modified {
+			return formulas, err
+		}
+		formulas, err = s.getLegacyFormulas(ctx)
+		if errors.Is(err, pgerr.ErrNotFound) {
+			return make([]*model.Formula, 0), nil
+		}
+		return formulas, err
+	}, 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	return formulas, nil
+}
+
+// Cache: (singular) shimFormula, 24hrs; records last modified time
+func (s *Formula) GetShimFormula(ctx context.Context) ([]*modelv2.Formula, error) {
+	var shimFormulas []*modelv2.Formula
+	err := cache.ShimFormula.Get(&shimFormulas)
 	if err == nil {
-		return formula, nil
+		return shimFormulas, nil
+	}
+
+	formulas, err := s.GetFormulas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	itemsMap, err := s.ItemService.GetItemsMapByArkId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	itemName := func(arkItemId string) string {
+		item, ok := itemsMap[arkItemId]
+		if !ok {
+			return ""
+		}
+		return gjson.GetBytes(item.Name, "zh").String()
+	}
+
+	shimFormulas = make([]*modelv2.Formula, 0, len(formulas))
+	for _, formula := range formulas {
+		costs := make([]*modelv2.FormulaCost, 0, len(formula.Costs))
+		for _, cost := range formula.Costs {
+			costs = append(costs, &modelv2.FormulaCost{
+				ID:    cost.ArkItemID,
+				Name:  itemName(cost.ArkItemID),
+				Count: cost.Count,
+			})
+		}
+		extraOutcome := make([]*modelv2.FormulaExtraOutcome, 0, len(formula.ExtraOutcomes))
+		for _, outcome := range formula.ExtraOutcomes {
+			extraOutcome = append(extraOutcome, &modelv2.FormulaExtraOutcome{
+				ID:     outcome.ArkItemID,
+				Name:   itemName(outcome.ArkItemID),
+				Weight: outcome.Weight,
+				Count:  1,
+			})
+		}
+		shimFormulas = append(shimFormulas, &modelv2.Formula{
+			ID:           formula.ArkItemID,
+			Name:         itemName(formula.ArkItemID),
+			Costs:        costs,
+			GoldCost:     formula.GoldCost,
+			ExtraOutcome: extraOutcome,
+			TotalWeight:  formula.TotalWeight(),
+		})
+	}
+
+	cache.ShimFormula.Set(shimFormulas, 24*time.Hour)
+	cache.LastModifiedTime.Set("[shimFormula]", time.Now(), 0)
+	return shimFormulas, nil
+}
+
+// SaveFormula creates or updates the formula crafting the given item, and records a new revision.
+func (s *Formula) SaveFormula(ctx context.Context, arkItemId string, req *types.SaveFormulaRequest) (*model.Formula, error) {
+	formula := &model.Formula{
+		ArkItemID:     arkItemId,
+		GoldCost:      req.GoldCost,
+		Costs:         make([]*model.FormulaCost, 0, len(req.Costs)),
+		ExtraOutcomes: make([]*model.FormulaExtraOutcome, 0, len(req.ExtraOutcomes)),
+	}
+	for _, cost := range req.Costs {
+		formula.Costs = append(formula.Costs, &model.FormulaCost{
+			ArkItemID: cost.ItemID,
+			Count:     cost.Count,
+		})
+	}
+	for _, outcome := range req.ExtraOutcomes {
+		formula.ExtraOutcomes = append(formula.ExtraOutcomes, &model.FormulaExtraOutcome{
+			ArkItemID: outcome.ItemID,
+			Weight:    outcome.Weight,
+		})
+	}
+	if len(req.Existence) > 0 {
+		existence := make(map[string]map[string]bool, len(req.Existence))
+		for server, exist := range req.Existence {
+			existence[strings.ToUpper(server)] = map[string]bool{"exist": exist}
+		}
+		existenceJson, err := json.Marshal(existence)
+		if err != nil {
+			return nil, err
+		}
+		formula.Existence = existenceJson
 	}
 
+	if err := s.validateFormula(ctx, formula); err != nil {
+		return nil, err
+	}
+
+	err := s.modifyFormulas(ctx, req.Comment, func(ctx context.Context, tx bun.Tx) error {
+		return s.FormulaRepo.SaveFormula(ctx, tx, formula)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return formula, nil
+}
+
+// DeleteFormula deletes the formula crafting the given item, and records a new revision.
+func (s *Formula) DeleteFormula(ctx context.Context, arkItemId string, comment null.String) error {
+	return s.modifyFormulas(ctx, comment, func(ctx context.Context, tx bun.Tx) error {
+		return s.FormulaRepo.DeleteFormulaByArkItemId(ctx, tx, arkItemId)
+	})
+}
+
+func (s *Formula) GetRevisions(ctx context.Context) ([]*model.FormulaRevision, error) {
+	return s.FormulaRepo.GetRevisions(ctx)
+}
+
+func (s *Formula) GetRevisionById(ctx context.Context, revisionId int) (*model.FormulaRevision, error) {
+	return s.FormulaRepo.GetRevisionById(ctx, revisionId)
+}
+
+// RollbackToRevision replaces all formulas with the snapshot of the given revision, and records a new revision.
+func (s *Formula) RollbackToRevision(ctx context.Context, revisionId int, comment null.String) error {
+	revision, err := s.FormulaRepo.GetRevisionById(ctx, revisionId)
+	if err != nil {
+		return err
+	}
+	if !comment.Valid {
+		comment = null.StringFrom("rollback to revision #" + strconv.Itoa(revisionId))
+	}
+
+	return s.modifyFormulas(ctx, comment, func(ctx context.Context, tx bun.Tx) error {
+		return s.FormulaRepo.ReplaceFormulas(ctx, tx, revision.Formulas)
+	})
+}
+
+// ImportLegacyFormulas replaces all formulas with the ones stored in the legacy formula property, and records a new revision.
+func (s *Formula) ImportLegacyFormulas(ctx context.Context, comment null.String) ([]*model.Formula, error) {
+	formulas, err := s.getLegacyFormulas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, formula := range formulas {
+		if err := s.validateFormula(ctx, formula); err != nil {
+			return nil, err
+		}
+	}
+	if !comment.Valid {
+		comment = null.StringFrom("import from legacy formula property")
+	}
+
+	err = s.modifyFormulas(ctx, comment, func(ctx context.Context, tx bun.Tx) error {
+		return s.FormulaRepo.ReplaceFormulas(ctx, tx, formulas)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return formulas, nil
+}
+
+// getLegacyFormulas reads the formulas stored in the legacy formula property.
+func (s *Formula) getLegacyFormulas(ctx context.Context) ([]*model.Formula, error) {
 	property, err := s.PropertyRepo.GetPropertyByKey(ctx, constant.FormulaPropertyKey)
 	if err != nil {
 		return nil, err
 	}
+	var legacyFormulas []*legacyFormula
+	if err := json.Unmarshal([]byte(property.Value), &legacyFormulas); err != nil {
+		return nil, pgerr.ErrInvalidReq.Msg("failed to parse legacy formula property: %s", err)
+	}
+
+	formulas := make([]*model.Formula, 0, len(legacyFormulas))
+	for _, legacy := range legacyFormulas {
+		formula := &model.Formula{
+			ArkItemID:     legacy.ID,
+			GoldCost:      legacy.GoldCost,
+			Costs:         make([]*model.FormulaCost, 0, len(legacy.Costs)),
+			ExtraOutcomes: make([]*model.FormulaExtraOutcome, 0, len(legacy.ExtraOutcome)),
+		}
+		for _, cost := range legacy.Costs {
+			formula.Costs = append(formula.Costs, &model.FormulaCost{
+				ArkItemID: cost.ID,
+				Count:     cost.Count,
+			})
+		}
+		for _, outcome := range legacy.ExtraOutcome {
+			formula.ExtraOutcomes = append(formula.ExtraOutcomes, &model.FormulaExtraOutcome{
+				ArkItemID: outcome.ID,
+				Weight:    outcome.Weight,
+			})
+		}
+		formulas = append(formulas, formula)
+	}
+	return formulas, nil
+}
 
-	msg := json.RawMessage([]byte(property.Value))
-	go cache.Formula.Set(msg, time.Hour)
+// modifyFormulas runs fn in a transaction, then checks that the resulting formulas do not craft any item out of
+// itself and records a snapshot of all formulas as a new revision within the same transaction, and finally flushes
+// the formula caches.
+func (s *Formula) modifyFormulas(ctx context.Context, comment null.String, fn func(ctx context.Context, tx bun.Tx) error) error {
+	err := s.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := fn(ctx, tx); err != nil {
+			return err
+		}
+		formulas, err := s.FormulaRepo.GetFormulasTx(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if err := validateFormulaGraph(formulas); err != nil {
+			return err
+		}
+		return s.FormulaRepo.CreateRevision(ctx, tx, &model.FormulaRevision{
+			Comment:  comment,
+			Formulas: formulas,
+		})
+	})
+	if err != nil {
+		return err
+	}
 
-	return msg, nil
+	if err := cache.Formulas.Delete(); err != nil {
+		return err
+	}
+	return cache.ShimFormula.Delete()
+}
+
+func (s *Formula) validateFormula(ctx context.Context, formula *model.Formula) error {
+	itemsMap, err := s.ItemService.GetItemsMapByArkId(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := itemsMap[formula.ArkItemID]; !ok {
+		return pgerr.ErrInvalidReq.Msg("formula target item %s does not exist", formula.ArkItemID)
+	}
+	if formula.GoldCost < 0 {
+		return pgerr.ErrInvalidReq.Msg("formula for %s has a negative gold cost", formula.ArkItemID)
+	}
+	if len(formula.Costs) == 0 {
+		return pgerr.ErrInvalidReq.Msg("formula for %s has no costs", formula.ArkItemID)
+	}
+
+	seen := make(map[string]bool, len(formula.Costs))
+	for _, cost := range formula.Costs {
+		if _, ok := itemsMap[cost.ArkItemID]; !ok {
+			return pgerr.ErrInvalidReq.Msg("formula for %s: cost item %s does not exist", formula.ArkItemID, cost.ArkItemID)
+		}
+		if cost.ArkItemID == formula.ArkItemID {
+			return pgerr.ErrInvalidReq.Msg("formula for %s consumes its own target item", formula.ArkItemID)
+		}
+		if seen[cost.ArkItemID] {
+			return pgerr.ErrInvalidReq.Msg("formula for %s: cost item %s is duplicated", formula.ArkItemID, cost.ArkItemID)
+		}
+		if cost.Count <= 0 {
+			return pgerr.ErrInvalidReq.Msg("formula for %s: cost item %s has a non-positive count", formula.ArkItemID, cost.ArkItemID)
+		}
+		seen[cost.ArkItemID] = true
+	}
+
+	seen = make(map[string]bool, len(formula.ExtraOutcomes))
+	for _, outcome := range formula.ExtraOutcomes {
+		if _, ok := itemsMap[outcome.ArkItemID]; !ok {
+			return pgerr.ErrInvalidReq.Msg("formula for %s: extra outcome item %s does not exist", formula.ArkItemID, outcome.ArkItemID)
+		}
+		if seen[outcome.ArkItemID] {
+			return pgerr.ErrInvalidReq.Msg("formula for %s: extra outcome item %s is duplicated", formula.ArkItemID, outcome.ArkItemID)
+		}
+		if outcome.Weight <= 0 {
+			return pgerr.ErrInvalidReq.Msg("formula for %s: extra outcome item %s has a non-positive weight", formula.ArkItemID, outcome.ArkItemID)
+		}
+		seen[outcome.ArkItemID] = true
+	}
+
+	return nil
+}
+
+// validateFormulaGraph checks that no item is crafted, directly or through intermediate items, out of itself, e.g.
+// A out of B and B out of A, by searching the graph from target items to their cost items for a cycle.
+func validateFormulaGraph(formulas []*model.Formula) error {
+	costs := make(map[string][]string, len(formulas))
+	for _, formula := range formulas {
+		for _, cost := range formula.Costs {
+			costs[formula.ArkItemID] = append(costs[formula.ArkItemID], cost.ArkItemID)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(costs))
+	// path is the chain of items being crafted out of each other from the item the search started from
+	var path []string
+	var visit func(item string) []string
+	visit = func(item string) []string {
+		switch state[item] {
+		case visiting:
+			start := lo.IndexOf(path, item)
+			return append(append([]string{}, path[start:]...), item)
+		case visited:
+			return nil
+		}
+		state[item] = visiting
+		path = append(path, item)
+		for _, cost := range costs[item] {
+			if cycle := visit(cost); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[item] = visited
+		return nil
+	}
+
+	// visit in a stable order, so that the same cycle is reported every time
+	for _, formula := range formulas {
+		if cycle := visit(formula.ArkItemID); cycle != nil {
+			return pgerr.ErrInvalidReq.Msg("formulas craft item %s out of itself: %s", cycle[0], strings.Join(cycle, " -> "))
+		}
+	}
+	return nil
 }
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/penguin-statistics/backend-next/internal/constant"
+	"github.com/penguin-statistics/backend-next/internal/model"
+	"github.com/penguin-statistics/backend-next/internal/model/cache"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
+	"github.com/penguin-statistics/backend-next/internal/repo"
+	"github.com/penguin-statistics/backend-next/internal/util"
+)
+
+// Furniture serves furniture drop rates. Furniture drops are stored as the furniture pseudo-item in drop patterns,
+// and still take part in the drop matrix for compatibility; the rates here are calculated separately from it.
+type Furniture struct {
+	DropReportRepo   *repo.DropReport
+	DropInfoService  *DropInfo
+	TimeRangeService *TimeRange
+	StageService     *Stage
+	ItemService      *Item
+}
+
+func NewFurniture(dropReportRepo *repo.DropReport, dropInfoService *DropInfo, timeRangeService *TimeRange, stageService *Stage, itemService *Item) *Furniture {
+	return &Furniture{
+		DropReportRepo:   dropReportRepo,
+		DropInfoService:  dropInfoService,
+		TimeRangeService: timeRangeService,
+		StageService:     stageService,
+		ItemService:      itemService,
+	}
+}
+
+// Cache: furnitureDrops#server:{server}, 24hrs
+func (s *Furniture) GetFurnitureDrops(ctx context.Context, server string) (*model.FurnitureDrops, error) {
+	var results model.FurnitureDrops
+	err := cache.FurnitureDrops.Get(server, &results)
+	if err == nil {
+		return &results, nil
+	}
+
+	return s.RefreshFurnitureDrops(ctx, server)
+}
+
+// RefreshFurnitureDrops calculates the furniture drop rate of every stage that has ever had a furniture drop info
+// in the server, over the reliable reports of the stage within the time ranges of its furniture drop infos.
+func (s *Furniture) RefreshFurnitureDrops(ctx context.Context, server string) (*model.FurnitureDrops, error) {
+	valueFunc := func() (*model.FurnitureDrops, error) {
+		furniture, err := s.ItemService.GetItemByArkId(ctx, constant.FurnitureArkItemID)
+		if err != nil {
+			return nil, err
+		}
+		dropInfos, err := s.DropInfoService.GetDropInfosByServer(ctx, server)
+		if err != nil && err != pgerr.ErrNotFound {
+			return nil, err
+		}
+		currentDropInfos, err := s.DropInfoService.GetCurrentDropInfosByServer(ctx, server)
+		if err != nil && err != pgerr.ErrNotFound {
+			return nil, err
+		}
+		isFurnitureDropInfo := func(dropInfo *model.DropInfo) bool {
+			return dropInfo.DropType == constant.DropTypeFurniture ||
+				(dropInfo.ItemID.Valid && int(dropInfo.ItemID.Int64) == furniture.ItemID)
+		}
+
+		timeRangesMap, err := s.TimeRangeService.GetTimeRangesMap(ctx, server)
+		if err != nil {
+			return nil, err
+		}
+
+		// reports outside the time ranges of the furniture drop infos of a stage are not known to have had a chance
+		// of dropping furniture, so only the ones within are counted, like the drop matrix does
+		stageTimeRanges := make(map[int][]*model.TimeRange)
+		seen := make(map[int]map[int]bool)
+		for _, dropInfo := range dropInfos {
+			if !isFurnitureDropInfo(dropInfo) {
+				continue
+			}
+			timeRange, ok := timeRangesMap[dropInfo.RangeID]
+			if !ok {
+				continue
+			}
+			if seen[dropInfo.StageID] == nil {
+				seen[dropInfo.StageID] = make(map[int]bool)
+			}
+			if !seen[dropInfo.StageID][dropInfo.RangeID] {
+				seen[dropInfo.StageID][dropInfo.RangeID] = true
+				stageTimeRanges[dropInfo.StageID] = append(stageTimeRanges[dropInfo.StageID], timeRange)
+			}
+		}
+		openStageIds := make(map[int]bool)
+		for _, dropInfo := range currentDropInfos {
+			if isFurnitureDropInfo(dropInfo) {
+				openStageIds[dropInfo.StageID] = true
+			}
+		}
+
+		quantities, err := s.DropReportRepo.CalcFurnitureDropsByStage(ctx, server, stageTimeRanges, furniture.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		stagesMap, err := s.StageService.GetStagesMapById(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		stages := make([]*model.FurnitureDropStage, 0, len(quantities))
+		for _, quantity := range quantities {
+			stage, ok := stagesMap[quantity.StageID]
+			if !ok || quantity.Times == 0 {
+				continue
+			}
+			// a run drops at most one furniture; clamp in case of malformed reports
+			dropped := quantity.Quantity
+			if dropped > quantity.Times {
+				dropped = quantity.Times
+			}
+			lower, upper := util.WilsonScoreInterval(dropped, quantity.Times, constant.FurnitureConfidenceZ)
+			stages = append(stages, &model.FurnitureDropStage{
+				StageID:         stage.ArkStageID,
+				Open:            openStageIds[quantity.StageID],
+				Times:           quantity.Times,
+				Quantity:        dropped,
+				Rate:            util.RoundFloat64(float64(dropped)/float64(quantity.Times), 6),
+				ConfidenceLower: util.RoundFloat64(lower, 6),
+				ConfidenceUpper: util.RoundFloat64(upper, 6),
+			})
+		}
+		sort.Slice(stages, func(i, j int) bool {
+			return stages[i].StageID < stages[j].StageID
+		})
+
+		return &model.FurnitureDrops{
+			Server:          server,
+			Stages:          stages,
+			ConfidenceLevel: constant.FurnitureConfidenceLevel,
+			UpdatedAt:       time.Now().UnixMilli(),
+		}, nil
+	}
+
+	var results model.FurnitureDrops
+	cache.FurnitureDrops.Delete(server)
+	_, err := cache.FurnitureDrops.MutexGetSet(server, &results, valueFunc, 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cache.LastModifiedTime.Set("[furnitureDrops#server:"+server+"]", time.Now(), 0)
+	return &results, nil
+}
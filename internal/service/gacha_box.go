@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/penguin-statistics/backend-next/internal/constant"
+	"github.com/penguin-statistics/backend-next/internal/model"
+	"github.com/penguin-statistics/backend-next/internal/model/cache"
+	"github.com/penguin-statistics/backend-next/internal/model/types"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
+	"github.com/penguin-statistics/backend-next/internal/repo"
+)
+
+// GachaBox serves analytics of gacha box stages. A gacha box holds a fixed pool of items which are drawn without
+// replacement; once every item has been drawn the box is cleared and a full one takes its place. A draw may yield
+// more than one of an item, so the number of draws is counted from the times of the reports rather than from the
+// quantity of items drawn.
+type GachaBox struct {
+	GachaBoxRepo   *repo.GachaBox
+	DropReportRepo *repo.DropReport
+	StageService   *Stage
+	ItemService    *Item
+}
+
+func NewGachaBox(gachaBoxRepo *repo.GachaBox, dropReportRepo *repo.DropReport, stageService *Stage, itemService *Item) *GachaBox {
+	return &GachaBox{
+		GachaBoxRepo:   gachaBoxRepo,
+		DropReportRepo: dropReportRepo,
+		StageService:   stageService,
+		ItemService:    itemService,
+	}
+}
+
+// Cache: (singular) gachaBoxPools, 24hrs
+func (s *GachaBox) GetPools(ctx context.Context) ([]*model.GachaBoxPool, error) {
+	var pools []*model.GachaBoxPool
+	err := cache.GachaBoxPools.MutexGetSet(&pools, func() ([]*model.GachaBoxPool, error) {
+		pools, err := s.GachaBoxRepo.GetPools(ctx)
+		if err != nil {
+			return nil, err
+		}
+		stagesMap, err := s.StageService.GetStagesMapById(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, pool := range pools {
+			if stage, ok := stagesMap[pool.StageID]; ok {
+				pool.ArkStageID = stage.ArkStageID
+			}
+		}
+		return pools, nil
+	}, 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	return pools, nil
+}
+
+func (s *GachaBox) GetPoolByArkStageId(ctx context.Context, arkStageId string) (*model.GachaBoxPool, error) {
+	pools, err := s.GetPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pool := range pools {
+		if pool.ArkStageID == arkStageId {
+			return pool, nil
+		}
+	}
+	return nil, pgerr.ErrNotFound
+}
+
+// SavePool creates or replaces the pool definition of the given gacha box stage.
+func (s *GachaBox) SavePool(ctx context.Context, arkStageId string, req *types.SaveGachaBoxPoolRequest) (*model.GachaBoxPool, error) {
+	stage, err := s.StageService.GetStageByArkId(ctx, arkStageId)
+	if err != nil {
+		return nil, err
+	}
+	if !stage.ExtraProcessType.Valid || stage.ExtraProcessType.String != constant.ExtraProcessTypeGachaBox {
+		return nil, pgerr.ErrInvalidReq.Msg("stage %s is not a gacha box stage", arkStageId)
+	}
+
+	itemsMap, err := s.ItemService.GetItemsMapByArkId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pool := &model.GachaBoxPool{
+		StageID:    stage.StageID,
+		ArkStageID: stage.ArkStageID,
+		Items:      make([]*model.GachaBoxPoolItem, 0, len(req.Items)),
+		DrawCost:   req.DrawCost,
+		CostItemID: req.CostItemID,
+		UpdatedAt:  time.Now(),
+	}
+	seen := make(map[string]bool, len(req.Items))
+	for _, item := range req.Items {
+		if _, ok := itemsMap[item.ItemID]; !ok {
+			return nil, pgerr.ErrInvalidReq.Msg("pool item %s does not exist", item.ItemID)
+		}
+		if seen[item.ItemID] {
+			return nil, pgerr.ErrInvalidReq.Msg("pool item %s is duplicated", item.ItemID)
+		}
+		seen[item.ItemID] = true
+		pool.Items = append(pool.Items, &model.GachaBoxPoolItem{
+			ArkItemID: item.ItemID,
+			Quantity:  item.Quantity,
+		})
+	}
+	if pool.CostItemID.Valid {
+		if _, ok := itemsMap[pool.CostItemID.String]; !ok {
+			return nil, pgerr.ErrInvalidReq.Msg("cost item %s does not exist", pool.CostItemID.String)
+		}
+	}
+
+	if err := s.GachaBoxRepo.SavePool(ctx, pool); err != nil {
+		return nil, err
+	}
+	if err := s.flush(); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
+func (s *GachaBox) DeletePool(ctx context.Context, arkStageId string) error {
+	stage, err := s.StageService.GetStageByArkId(ctx, arkStageId)
+	if err != nil {
+		return err
+	}
+	if err := s.GachaBoxRepo.DeletePoolByStageId(ctx, stage.StageID); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+// Cache: gachaBoxDistribution#server|arkStageId, 24hrs; records last modified time
+func (s *GachaBox) GetDistribution(ctx context.Context, server string, arkStageId string) (*model.GachaBoxDistribution, error) {
+	valueFunc := func() (*model.GachaBoxDistribution, error) {
+		pool, drawn, draws, err := s.getPoolAndDraws(ctx, server, arkStageId, null.NewInt(0, false))
+		if err != nil {
+			return nil, err
+		}
+		return calcGachaBoxDistribution(server, arkStageId, pool, drawn, draws), nil
+	}
+
+	var distribution model.GachaBoxDistribution
+	key := server + constant.CacheSep + arkStageId
+	calculated, err := cache.GachaBoxDistribution.MutexGetSet(key, &distribution, valueFunc, 24*time.Hour)
+	if err != nil {
+		return nil, err
+	} else if calculated {
+		cache.LastModifiedTime.Set("[gachaBoxDistribution#server|arkStageId:"+key+"]", time.Now(), 0)
+	}
+	return &distribution, nil
+}
+
+// GetRemainingPool infers the box the given account is currently drawing from, assuming every draw of the account
+// on the stage has been reported.
+func (s *GachaBox) GetRemainingPool(ctx context.Context, server string, arkStageId string, accountId int) (*model.GachaBoxRemainingPool, error) {
+	pool, drawn, draws, err := s.getPoolAndDraws(ctx, server, arkStageId, null.IntFrom(int64(accountId)))
+	if err != nil {
+		return nil, err
+	}
+	return calcGachaBoxRemainingPool(server, arkStageId, pool, drawn, draws), nil
+}
+
+// getPoolAndDraws returns the pool of the given stage, the quantity drawn of every item keyed by its string form ID,
+// and the number of draws. If accountId is valid, only draws reported by that account are counted.
+func (s *GachaBox) getPoolAndDraws(ctx context.Context, server string, arkStageId string, accountId null.Int) (*model.GachaBoxPool, map[string]int, int, error) {
+	pool, err := s.GetPoolByArkStageId(ctx, arkStageId)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	quantities, err := s.DropReportRepo.CalcTotalQuantityForGachaBox(ctx, server, pool.StageID, accountId)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	draws, err := s.DropReportRepo.CalcTotalTimesForGachaBox(ctx, server, pool.StageID, accountId)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	itemsMap, err := s.ItemService.GetItemsMapById(ctx)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	drawn := make(map[string]int, len(quantities))
+	for _, quantity := range quantities {
+		item, ok := itemsMap[quantity.ItemID]
+		if !ok {
+			continue
+		}
+		drawn[item.ArkItemID] += quantity.Quantity
+	}
+	return pool, drawn, draws, nil
+}
+
+// calcGachaBoxDistribution compares the defined shares of the items of pool with the observed quantities drawn of
+// them per draw.
+func calcGachaBoxDistribution(server string, arkStageId string, pool *model.GachaBoxPool, drawn map[string]int, draws int) *model.GachaBoxDistribution {
+	// items drawn but not in the pool definition are still listed, with a defined quantity of zero
+	quantities := make(map[string]int, len(pool.Items))
+	for _, item := range pool.Items {
+		quantities[item.ArkItemID] = item.Quantity
+	}
+	itemIds := make([]string, 0, len(pool.Items))
+	for _, item := range pool.Items {
+		itemIds = append(itemIds, item.ArkItemID)
+	}
+	extraIds := make([]string, 0)
+	for itemId := range drawn {
+		if _, ok := quantities[itemId]; !ok {
+			extraIds = append(extraIds, itemId)
+		}
+	}
+	sort.Strings(extraIds)
+	itemIds = append(itemIds, extraIds...)
+
+	size := pool.Size()
+
+	distribution := &model.GachaBoxDistribution{
+		StageID: arkStageId,
+		Server:  server,
+		Pool:    pool,
+		Draws:   draws,
+		Items:   make([]*model.GachaBoxDistributionItem, 0, len(itemIds)),
+	}
+	for _, itemId := range itemIds {
+		item := &model.GachaBoxDistributionItem{
+			ItemID:           itemId,
+			DefinedQuantity:  quantities[itemId],
+			ObservedQuantity: drawn[itemId],
+		}
+		if size > 0 {
+			item.DefinedShare = float64(item.DefinedQuantity) / float64(size)
+		}
+		if draws > 0 {
+			item.ObservedShare = float64(item.ObservedQuantity) / float64(draws)
+		}
+		distribution.Items = append(distribution.Items, item)
+	}
+	return distribution
+}
+
+// calcGachaBoxRemainingPool infers the state of the current box from the quantities drawn of every item and the
+// number of draws. Draws beyond the boxes already cleared are attributed to the current box, and an item is never
+// considered to have less than zero left, so that reports not matching the pool definition, e.g. ones made before
+// the pool was defined correctly, do not result in negative quantities.
+func calcGachaBoxRemainingPool(server string, arkStageId string, pool *model.GachaBoxPool, drawn map[string]int, draws int) *model.GachaBoxRemainingPool {
+	size := pool.Size()
+	boxesCleared := 0
+	remainingDraws := 0
+	if size > 0 {
+		boxesCleared = draws / size
+		remainingDraws = size - draws%size
+	}
+
+	remainingPool := &model.GachaBoxRemainingPool{
+		StageID:        arkStageId,
+		Server:         server,
+		Draws:          draws,
+		BoxesCleared:   boxesCleared,
+		RemainingDraws: remainingDraws,
+		RemainingCost:  remainingDraws * pool.DrawCost,
+		CostItemID:     pool.CostItemID,
+		Items:          make([]*model.GachaBoxRemainingPoolItem, 0, len(pool.Items)),
+	}
+	remainingItems := 0
+	for _, item := range pool.Items {
+		drawnInBox := drawn[item.ArkItemID] - boxesCleared*item.Quantity
+		if drawnInBox < 0 {
+			drawnInBox = 0
+		}
+		remaining := item.Quantity - drawnInBox
+		if remaining < 0 {
+			remaining = 0
+		}
+		remainingItems += remaining
+		remainingPool.Items = append(remainingPool.Items, &model.GachaBoxRemainingPoolItem{
+			ItemID:    item.ArkItemID,
+			Drawn:     drawnInBox,
+			Remaining: remaining,
+		})
+	}
+	for _, item := range remainingPool.Items {
+		if remainingItems > 0 {
+			item.Probability = float64(item.Remaining) / float64(remainingItems)
+		}
+	}
+	return remainingPool
+}
+
+func (s *GachaBox) flush() error {
+	if err := cache.GachaBoxPools.Delete(); err != nil {
+		return err
+	}
+	return cache.GachaBoxDistribution.Flush()
+}
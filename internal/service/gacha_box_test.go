@@ -0,0 +1,129 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/penguin-statistics/backend-next/internal/model"
+)
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func newTestGachaBoxPool() *model.GachaBoxPool {
+	return &model.GachaBoxPool{
+		Items: []*model.GachaBoxPoolItem{
+			{ArkItemID: "A", Quantity: 2},
+			{ArkItemID: "B", Quantity: 1},
+		},
+		DrawCost: 10,
+	}
+}
+
+func TestCalcGachaBoxRemainingPool(t *testing.T) {
+	tests := []struct {
+		name           string
+		drawn          map[string]int
+		draws          int
+		boxesCleared   int
+		remainingDraws int
+		remaining      map[string]int
+		probability    map[string]float64
+	}{
+		{
+			name:           "no draws",
+			drawn:          map[string]int{},
+			draws:          0,
+			boxesCleared:   0,
+			remainingDraws: 3,
+			remaining:      map[string]int{"A": 2, "B": 1},
+			probability:    map[string]float64{"A": 2.0 / 3, "B": 1.0 / 3},
+		},
+		{
+			name:           "second box",
+			drawn:          map[string]int{"A": 3, "B": 1},
+			draws:          4,
+			boxesCleared:   1,
+			remainingDraws: 2,
+			remaining:      map[string]int{"A": 1, "B": 1},
+			probability:    map[string]float64{"A": 0.5, "B": 0.5},
+		},
+		{
+			// the remaining draws follow the number of draws, not the quantity drawn
+			name:           "draw yielding more than one",
+			drawn:          map[string]int{"A": 2},
+			draws:          1,
+			boxesCleared:   0,
+			remainingDraws: 2,
+			remaining:      map[string]int{"A": 0, "B": 1},
+			probability:    map[string]float64{"A": 0, "B": 1},
+		},
+		{
+			name:           "more drawn than defined",
+			drawn:          map[string]int{"A": 5},
+			draws:          2,
+			boxesCleared:   0,
+			remainingDraws: 1,
+			remaining:      map[string]int{"A": 0, "B": 1},
+			probability:    map[string]float64{"A": 0, "B": 1},
+		},
+	}
+	for _, tt := range tests {
+		pool := calcGachaBoxRemainingPool("CN", "gacha", newTestGachaBoxPool(), tt.drawn, tt.draws)
+		if pool.Draws != tt.draws {
+			t.Errorf("%s: expected %d draws, got %d", tt.name, tt.draws, pool.Draws)
+		}
+		if pool.BoxesCleared != tt.boxesCleared {
+			t.Errorf("%s: expected %d boxes cleared, got %d", tt.name, tt.boxesCleared, pool.BoxesCleared)
+		}
+		if pool.RemainingDraws != tt.remainingDraws {
+			t.Errorf("%s: expected %d remaining draws, got %d", tt.name, tt.remainingDraws, pool.RemainingDraws)
+		}
+		if pool.RemainingCost != tt.remainingDraws*10 {
+			t.Errorf("%s: expected a remaining cost of %d, got %d", tt.name, tt.remainingDraws*10, pool.RemainingCost)
+		}
+		for _, item := range pool.Items {
+			if item.Remaining != tt.remaining[item.ItemID] {
+				t.Errorf("%s: expected %d of %s remaining, got %d", tt.name, tt.remaining[item.ItemID], item.ItemID, item.Remaining)
+			}
+			if !approxEqual(item.Probability, tt.probability[item.ItemID]) {
+				t.Errorf("%s: expected a probability of %f for %s, got %f", tt.name, tt.probability[item.ItemID], item.ItemID, item.Probability)
+			}
+		}
+	}
+}
+
+func TestCalcGachaBoxDistribution(t *testing.T) {
+	distribution := calcGachaBoxDistribution("CN", "gacha", newTestGachaBoxPool(), map[string]int{"A": 3, "B": 1, "C": 2}, 4)
+	if distribution.Draws != 4 {
+		t.Errorf("expected 4 draws, got %d", distribution.Draws)
+	}
+
+	expected := []struct {
+		itemId        string
+		defined       int
+		definedShare  float64
+		observed      int
+		observedShare float64
+	}{
+		{"A", 2, 2.0 / 3, 3, 0.75},
+		{"B", 1, 1.0 / 3, 1, 0.25},
+		// items drawn but not in the pool come last
+		{"C", 0, 0, 2, 0.5},
+	}
+	if len(distribution.Items) != len(expected) {
+		t.Fatalf("expected %d items, got %d", len(expected), len(distribution.Items))
+	}
+	for i, e := range expected {
+		item := distribution.Items[i]
+		if item.ItemID != e.itemId || item.DefinedQuantity != e.defined || item.ObservedQuantity != e.observed {
+			t.Errorf("expected item %d to be %s defined %d observed %d, got %s defined %d observed %d",
+				i, e.itemId, e.defined, e.observed, item.ItemID, item.DefinedQuantity, item.ObservedQuantity)
+		}
+		if !approxEqual(item.DefinedShare, e.definedShare) || !approxEqual(item.ObservedShare, e.observedShare) {
+			t.Errorf("expected %s shares %f defined, %f observed, got %f, %f",
+				e.itemId, e.definedShare, e.observedShare, item.DefinedShare, item.ObservedShare)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"net"
+	"time"
 
 	"github.com/oschwald/geoip2-golang"
 	"github.com/pkg/errors"
@@ -32,3 +33,13 @@ func (s *GeoIP) InChinaMainland(ip string) bool {
 	}
 	return country.Country.IsoCode == "CN"
 }
+
+// BuildTime returns when the GeoIP database was built, which tells how outdated it is.
+func (s *GeoIP) BuildTime() time.Time {
+	return time.Unix(int64(s.db.Metadata().BuildEpoch), 0)
+}
+
+// DatabaseType returns the type of the GeoIP database, e.g. GeoLite2-Country.
+func (s *GeoIP) DatabaseType() string {
+	return s.db.Metadata().DatabaseType
+}
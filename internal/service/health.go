@@ -2,11 +2,20 @@ package service
 
 import (
 	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/nats-io/nats.go"
 	"github.com/pkg/errors"
 	"github.com/uptrace/bun"
+
+	"github.com/penguin-statistics/backend-next/internal/config"
+	"github.com/penguin-statistics/backend-next/internal/constant"
+	"github.com/penguin-statistics/backend-next/internal/pkg/leader"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgreplica"
 )
 
 var (
@@ -15,18 +24,299 @@ var (
 	ErrNATSNotReachable     = errors.New("nats not reachable")
 )
 
+const (
+	ComponentStatusUp       = "up"
+	ComponentStatusDegraded = "degraded"
+	ComponentStatusDown     = "down"
+
+	// componentCheckTimeout bounds the check of every single component
+	componentCheckTimeout = 3 * time.Second
+)
+
 type Health struct {
-	DB    *bun.DB
-	Redis *redis.Client
-	NATS  *nats.Conn
+	ConfigReloader *config.Reloader
+	DB             *bun.DB
+	DBReplica      *pgreplica.Router
+	Redis          *redis.Client
+	NATS           *nats.Conn
+	NatsJS         nats.JetStreamContext
+	Elector        *leader.Elector
+	GeoIPService   *GeoIP
+	WarmupService  *Warmup
 }
 
-func NewHealth(db *bun.DB, redis *redis.Client, nats *nats.Conn) *Health {
+func NewHealth(configReloader *config.Reloader, db *bun.DB, dbReplica *pgreplica.Router, redis *redis.Client, nats *nats.Conn, natsJs nats.JetStreamContext, elector *leader.Elector, geoIPService *GeoIP, warmupService *Warmup) *Health {
 	return &Health{
-		DB:    db,
-		Redis: redis,
-		NATS:  nats,
+		ConfigReloader: configReloader,
+		DB:             db,
+		DBReplica:      dbReplica,
+		Redis:          redis,
+		NATS:           nats,
+		NatsJS:         natsJs,
+		Elector:        elector,
+		GeoIPService:   geoIPService,
+		WarmupService:  warmupService,
+	}
+}
+
+// ComponentHealth is the result of the check of a single component.
+type ComponentHealth struct {
+	Status string `json:"status"`
+	// Critical tells whether the instance is unable to serve while the component is down
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+	Detail    any     `json:"detail,omitempty"`
+}
+
+// Readiness is the result of the readiness check. Status is down if any critical component is down, degraded if
+// any other component is not up, and up otherwise.
+type Readiness struct {
+	Status     string                      `json:"status"`
+	CheckedAt  time.Time                   `json:"checkedAt"`
+	Components map[string]*ComponentHealth `json:"components"`
+}
+
+type componentCheck struct {
+	name     string
+	critical bool
+	// check returns the detail of the component, and either an error if the component is down, or a status to
+	// override the status of a component without an error, e.g. to report it as degraded
+	check func(ctx context.Context) (detail any, status string, err error)
+}
+
+// Readiness checks every component this instance depends on to serve, concurrently.
+func (s *Health) Readiness(ctx context.Context) *Readiness {
+	checks := []componentCheck{
+		{name: "database", critical: true, check: s.checkDatabase},
+		{name: "redis", critical: true, check: s.checkRedis},
+		{name: "nats", critical: true, check: s.checkNATS},
+		{name: "reportStream", critical: true, check: s.checkReportStream},
+		{name: "reportConsumer", check: s.checkReportConsumer},
+		{name: "warmup", critical: true, check: s.checkWarmup},
+		{name: "geoip", check: s.checkGeoIP},
+		{name: "worker", check: s.checkWorker},
+	}
+	if s.DBReplica.Replica() != nil {
+		checks = append(checks, componentCheck{name: "databaseReplica", check: s.checkDatabaseReplica})
+	}
+
+	readiness := &Readiness{
+		Status:     ComponentStatusUp,
+		CheckedAt:  time.Now(),
+		Components: make(map[string]*ComponentHealth, len(checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(len(checks))
+	for _, c := range checks {
+		go func(c componentCheck) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, componentCheckTimeout)
+			defer cancel()
+			started := time.Now()
+			detail, status, err := c.check(checkCtx)
+			component := &ComponentHealth{
+				Status:    ComponentStatusUp,
+				Critical:  c.critical,
+				LatencyMs: float64(time.Since(started).Microseconds()) / 1000,
+				Detail:    detail,
+			}
+			if err != nil {
+				component.Status = ComponentStatusDown
+				component.Error = err.Error()
+			} else if status != "" {
+				component.Status = status
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			readiness.Components[c.name] = component
+		}(c)
+	}
+	wg.Wait()
+
+	for _, component := range readiness.Components {
+		if component.Status == ComponentStatusDown && component.Critical {
+			readiness.Status = ComponentStatusDown
+		} else if component.Status != ComponentStatusUp && readiness.Status == ComponentStatusUp {
+			readiness.Status = ComponentStatusDegraded
+		}
+	}
+	return readiness
+}
+
+func (s *Health) checkDatabase(ctx context.Context) (any, string, error) {
+	return nil, "", s.DB.PingContext(ctx)
+}
+
+// checkDatabaseReplica reports the replica as of its latest lag check; it is degraded while reads fall back to the
+// primary.
+func (s *Health) checkDatabaseReplica(ctx context.Context) (any, string, error) {
+	status := s.DBReplica.Status()
+	if !status.Usable {
+		return status, ComponentStatusDegraded, nil
+	}
+	return status, "", nil
+}
+
+func (s *Health) checkRedis(ctx context.Context) (any, string, error) {
+	return nil, "", s.Redis.Ping(ctx).Err()
+}
+
+func (s *Health) checkNATS(ctx context.Context) (any, string, error) {
+	// nats does automatic ping for 20 seconds interval (configurated at infra/nats.go)
+	status := s.NATS.Status()
+	detail := map[string]any{
+		"status": status.String(),
+	}
+	if status != nats.CONNECTED && status != nats.DRAINING_PUBS && status != nats.DRAINING_SUBS {
+		return detail, "", errors.Wrap(ErrNATSNotReachable, status.String())
+	}
+	return detail, "", nil
+}
+
+func (s *Health) checkReportStream(ctx context.Context) (any, string, error) {
+	info, err := s.NatsJS.StreamInfo(constant.ReportStreamName, nats.Context(ctx))
+	if err != nil {
+		return nil, "", err
+	}
+	return map[string]any{
+		"messages":  info.State.Msgs,
+		"bytes":     info.State.Bytes,
+		"consumers": info.State.Consumers,
+	}, "", nil
+}
+
+func (s *Health) checkReportConsumer(ctx context.Context) (any, string, error) {
+	info, err := s.NatsJS.ConsumerInfo(constant.ReportStreamName, constant.ReportConsumerName, nats.Context(ctx))
+	if err != nil {
+		return nil, "", err
+	}
+	detail := map[string]any{
+		"pending":     info.NumPending,
+		"ackPending":  info.NumAckPending,
+		"redelivered": info.NumRedelivered,
+		"waiting":     info.NumWaiting,
+	}
+	if info.NumPending > s.ConfigReloader.Current().ReportMaxPending {
+		return detail, ComponentStatusDegraded, nil
+	}
+	return detail, "", nil
+}
+
+func (s *Health) checkWarmup(ctx context.Context) (any, string, error) {
+	status := s.WarmupService.Status()
+	if !s.WarmupService.Ready() {
+		return status, "", errors.New("cache warm-up not finished yet")
+	}
+	if status.State == WarmupStateDegraded {
+		return status, ComponentStatusDegraded, nil
+	}
+	return status, "", nil
+}
+
+func (s *Health) checkGeoIP(ctx context.Context) (any, string, error) {
+	// a well-known address should always resolve, unless the database is broken
+	if _, err := s.GeoIPService.Country("1.1.1.1"); err != nil {
+		return nil, "", err
+	}
+	return map[string]any{
+		"type":    s.GeoIPService.DatabaseType(),
+		"builtAt": s.GeoIPService.BuildTime(),
+	}, "", nil
+}
+
+// WorkerServerHealth describes how recently the calculation worker jobs of a server succeeded.
+type WorkerServerHealth struct {
+	// LastSucceededAt is when the latest successful run of any job of the server finished
+	LastSucceededAt *time.Time `json:"lastSucceededAt"`
+	AgeSeconds      float64    `json:"ageSeconds"`
+	Stale           bool       `json:"stale"`
+}
+
+// checkWorker reports the worker as degraded for servers whose jobs have not succeeded recently. It is reported up
+// without checking when the worker is disabled on this instance and no other instance leads it either, i.e. when
+// it is intentionally not running anywhere.
+func (s *Health) checkWorker(ctx context.Context) (any, string, error) {
+	if !s.ConfigReloader.Current().WorkerEnabled {
+		leaderId, err := s.Elector.Leader(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		if leaderId == "" {
+			return map[string]any{
+				"enabled": false,
+			}, "", nil
+		}
+	}
+
+	records, err := s.Redis.HGetAll(ctx, constant.WorkerLastSuccessKey).Result()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// job names are in the form of type.server[.sourceCategory]
+	latest := make(map[string]time.Time)
+	for name, value := range records {
+		parts := strings.Split(name, ".")
+		if len(parts) < 2 {
+			continue
+		}
+		unix, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		at := time.Unix(unix, 0)
+		if current, ok := latest[parts[1]]; !ok || at.After(current) {
+			latest[parts[1]] = at
+		}
+	}
+
+	maxAge := s.ConfigReloader.Current().WorkerMaxAge
+	status := ""
+	servers := make(map[string]*WorkerServerHealth, len(constant.Servers))
+	for _, server := range constant.Servers {
+		at, ok := latest[server]
+		if !ok {
+			servers[server] = &WorkerServerHealth{Stale: true}
+			status = ComponentStatusDegraded
+			continue
+		}
+		age := time.Since(at)
+		servers[server] = &WorkerServerHealth{
+			LastSucceededAt: &at,
+			AgeSeconds:      age.Seconds(),
+			Stale:           age > maxAge,
+		}
+		if age > maxAge {
+			status = ComponentStatusDegraded
+		}
+	}
+	return servers, status, nil
+}
+
+// WorkerLeader describes which instance currently runs the calculation worker.
+type WorkerLeader struct {
+	// ID is the ID of the current leader; empty if no instance holds the lease
+	ID string `json:"id"`
+	// Self is the ID of this instance
+	Self     string `json:"self"`
+	IsLeader bool   `json:"isLeader"`
+}
+
+func (s *Health) WorkerLeader(ctx context.Context) (*WorkerLeader, error) {
+	id, err := s.Elector.Leader(ctx)
+	if err != nil {
+		return nil, errors.Wrap(ErrRedisNotReachable, err.Error())
 	}
+	return &WorkerLeader{
+		ID:       id,
+		Self:     s.Elector.ID(),
+		IsLeader: s.Elector.IsLeader(),
+	}, nil
 }
 
 func (s *Health) Ping(ctx context.Context) error {
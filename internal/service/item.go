@@ -28,7 +28,7 @@ func NewItem(itemRepo *repo.Item) *Item {
 	}
 }
 
-// Cache: items, 24hrs
+// Cache: (singular) items, 24hrs; records last modified time
 func (s *Item) GetItems(ctx context.Context) ([]*model.Item, error) {
 	var items []*model.Item
 	err := cache.Items.Get(&items)
@@ -40,7 +40,8 @@ func (s *Item) GetItems(ctx context.Context) ([]*model.Item, error) {
 	if err != nil {
 		return nil, err
 	}
-	go cache.Items.Set(items, time.Hour)
+	cache.Items.Set(items, time.Hour)
+	cache.LastModifiedTime.Set("[items]", time.Now(), 0)
 	return items, nil
 }
 
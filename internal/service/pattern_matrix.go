@@ -2,10 +2,10 @@ package service
 
 import (
 	"context"
-	"time"
 
 	"github.com/ahmetb/go-linq/v3"
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 	"gopkg.in/guregu/null.v3"
 
 	"github.com/penguin-statistics/backend-next/internal/constant"
@@ -47,35 +47,44 @@ func NewPatternMatrix(
 	}
 }
 
-// Cache: shimLatestPatternMatrixResults#server:{server}, 24hrs, records last modified time
+// Cache: shimLatestPatternMatrixResults#server:{server}, fresh for 1 hr and served stale up to 24 hrs, records last modified time
 func (s *PatternMatrix) GetShimLatestPatternMatrixResults(ctx context.Context, server string, accountId null.Int) (*modelv2.PatternMatrixQueryResult, error) {
-	valueFunc := func() (*modelv2.PatternMatrixQueryResult, error) {
-		queryResult, err := s.getLatestPatternMatrixResults(ctx, server, accountId, constant.SourceCategoryAll)
+	if !accountId.Valid {
+		var results modelv2.PatternMatrixQueryResult
+		_, err := cache.ShimLatestPatternMatrixResults.StaleGetSet(ctx, server, &results, s.shimLatestPatternMatrixResultsFunc(server), constant.ShimResultsFreshTTL, constant.ShimResultsExpireTTL)
 		if err != nil {
 			return nil, err
 		}
-		slowResults, err := s.applyShimForPatternMatrixQuery(ctx, queryResult)
+		return &results, nil
+	} else {
+		queryResult, err := s.getLatestPatternMatrixResults(ctx, server, accountId, constant.SourceCategoryAll)
 		if err != nil {
 			return nil, err
 		}
-		return slowResults, nil
+		return s.applyShimForPatternMatrixQuery(ctx, queryResult)
 	}
+}
 
-	var results modelv2.PatternMatrixQueryResult
-	if !accountId.Valid {
-		calculated, err := cache.ShimLatestPatternMatrixResults.MutexGetSet(server, &results, valueFunc, 24*time.Hour)
+// RefreshShimLatestPatternMatrixResults recomputes the cached shim results of the server and swaps them in.
+func (s *PatternMatrix) RefreshShimLatestPatternMatrixResults(ctx context.Context, server string) error {
+	return cache.ShimLatestPatternMatrixResults.Revalidate(ctx, server, s.shimLatestPatternMatrixResultsFunc(server), constant.ShimResultsExpireTTL)
+}
+
+func (s *PatternMatrix) shimLatestPatternMatrixResultsFunc(server string) func(ctx context.Context) (*modelv2.PatternMatrixQueryResult, error) {
+	return func(ctx context.Context) (*modelv2.PatternMatrixQueryResult, error) {
+		queryResult, err := s.getLatestPatternMatrixResults(ctx, server, null.NewInt(0, false), constant.SourceCategoryAll)
 		if err != nil {
 			return nil, err
-		} else if calculated {
-			cache.LastModifiedTime.Set("[shimLatestPatternMatrixResults#server:"+server+"]", time.Now(), 0)
 		}
-		return &results, nil
-	} else {
-		return valueFunc()
+		slowResults, err := s.applyShimForPatternMatrixQuery(ctx, queryResult)
+		if err != nil {
+			return nil, err
+		}
+		return slowResults, nil
 	}
 }
 
-func (s *PatternMatrix) RefreshAllPatternMatrixElements(ctx context.Context, server string, sourceCategories []string) error {
+func (s *PatternMatrix) RefreshAllPatternMatrixElements(ctx context.Context, server string, sourceCategories []string, configuredCategories []string) error {
 	timeRangesMap, err := s.TimeRangeService.GetTimeRangesMap(ctx, server)
 	if err != nil {
 		return err
@@ -102,10 +111,13 @@ func (s *PatternMatrix) RefreshAllPatternMatrixElements(ctx context.Context, ser
 		return errors.Wrap(err, "failed to calculate pattern matrix")
 	}
 
-	if err := s.PatternMatrixElementService.BatchSaveElements(ctx, elements, server); err != nil {
+	if err := s.PatternMatrixElementService.BatchSaveElements(ctx, elements, server, sourceCategories, configuredCategories); err != nil {
 		return err
 	}
-	return cache.ShimLatestPatternMatrixResults.Delete(server)
+	if !lo.Contains(sourceCategories, constant.SourceCategoryAll) {
+		return nil
+	}
+	return s.RefreshShimLatestPatternMatrixResults(ctx, server)
 }
 
 func (s *PatternMatrix) getLatestPatternMatrixResults(ctx context.Context, server string, accountId null.Int, sourceCategory string) (*model.PatternMatrixQueryResult, error) {
@@ -17,8 +17,8 @@ func NewPatternMatrixElement(patternMatrixElementRepo *repo.PatternMatrixElement
 	}
 }
 
-func (s *PatternMatrixElement) BatchSaveElements(ctx context.Context, elements []*model.PatternMatrixElement, server string) error {
-	return s.PatternMatrixElementRepo.BatchSaveElements(ctx, elements, server)
+func (s *PatternMatrixElement) BatchSaveElements(ctx context.Context, elements []*model.PatternMatrixElement, server string, sourceCategories []string, configuredCategories []string) error {
+	return s.PatternMatrixElementRepo.BatchSaveElements(ctx, elements, server, sourceCategories, configuredCategories)
 }
 
 func (s *PatternMatrixElement) DeleteByServer(ctx context.Context, server string) error {
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/penguin-statistics/backend-next/internal/constant"
+	"github.com/penguin-statistics/backend-next/internal/model"
+	"github.com/penguin-statistics/backend-next/internal/model/types"
+	"github.com/penguin-statistics/backend-next/internal/pkg/lp"
+	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
+	"github.com/penguin-statistics/backend-next/internal/util"
+)
+
+// plannerEps is the threshold below which a stage run or a crafting is omitted from the plan
+const plannerEps = 1e-6
+
+var ErrPlanInfeasible = pgerr.ErrInvalidReq.Msg("the requested items could not be obtained from currently open stages with the given options")
+
+type Planner struct {
+	DropMatrixService *DropMatrix
+	StageService      *Stage
+	ItemService       *Item
+	FormulaService    *Formula
+}
+
+func NewPlanner(dropMatrixService *DropMatrix, stageService *Stage, itemService *Item, formulaService *Formula) *Planner {
+	return &Planner{
+		DropMatrixService: dropMatrixService,
+		StageService:      stageService,
+		ItemService:       itemService,
+		FormulaService:    formulaService,
+	}
+}
+
+// plannerStage is a stage eligible for the plan, with the drop rate of every item in it.
+type plannerStage struct {
+	arkStageId string
+	sanity     float64
+	rates      map[string]float64
+}
+
+// Plan calculates the sanity-optimal combination of stage runs and craftings to obtain the requested items,
+// using the max-accumulable drop matrix of currently open stages.
+func (s *Planner) Plan(ctx context.Context, req *types.PlannerRequest) (*model.PlannerResult, error) {
+	// the server is validated case-insensitively
+	server := strings.ToUpper(req.Server)
+	allowCrafting := true
+	if req.Options.AllowCrafting.Valid {
+		allowCrafting = req.Options.AllowCrafting.Bool
+	}
+	byproductRate := constant.PlannerDefaultByproductRate
+	if req.Options.ByproductRate.Valid {
+		byproductRate = req.Options.ByproductRate.Float64
+		if byproductRate < 0 || byproductRate > 1 {
+			return nil, pgerr.ErrInvalidReq.Msg("byproductRate must be between 0 and 1")
+		}
+	}
+	minTimes := constant.PlannerDefaultMinTimes
+	if req.Options.MinTimes.Valid {
+		minTimes = int(req.Options.MinTimes.Int64)
+	}
+
+	itemsMap, err := s.ItemService.GetItemsMapByArkId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for arkItemId := range req.Needs {
+		if _, ok := itemsMap[arkItemId]; !ok {
+			return nil, pgerr.ErrInvalidReq.Msg("unknown item: %s", arkItemId)
+		}
+	}
+
+	formulasMap := make(map[string]*model.Formula)
+	if allowCrafting {
+		formulas, err := s.FormulaService.GetFormulas(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, formula := range formulas {
+			if formula.ExistsIn(server) {
+				formulasMap[formula.ArkItemID] = formula
+			}
+		}
+	}
+
+	// only items that are needed, or that could be crafted into needed items, are constrained
+	relevantItems := make(map[string]bool)
+	queue := make([]string, 0, len(req.Needs))
+	for arkItemId, quantity := range req.Needs {
+		if quantity > 0 {
+			queue = append(queue, arkItemId)
+		}
+	}
+	for len(queue) > 0 {
+		arkItemId := queue[0]
+		queue = queue[1:]
+		if relevantItems[arkItemId] {
+			continue
+		}
+		relevantItems[arkItemId] = true
+		if formula, ok := formulasMap[arkItemId]; ok {
+			for _, cost := range formula.Costs {
+				queue = append(queue, cost.ArkItemID)
+			}
+		}
+	}
+	if len(relevantItems) == 0 {
+		return &model.PlannerResult{
+			Stages:    make([]*model.PlannerStage, 0),
+			Craftings: make([]*model.PlannerCrafting, 0),
+		}, nil
+	}
+
+	stages, err := s.getEligibleStages(ctx, server, req.Options.ExcludedStages, minTimes, relevantItems)
+	if err != nil {
+		return nil, err
+	}
+	formulas := make([]*model.Formula, 0)
+	for arkItemId := range relevantItems {
+		if formula, ok := formulasMap[arkItemId]; ok {
+			formulas = append(formulas, formula)
+		}
+	}
+	sort.Slice(formulas, func(i, j int) bool {
+		return formulas[i].ArkItemID < formulas[j].ArkItemID
+	})
+
+	itemIds := lo.Keys(relevantItems)
+	sort.Strings(itemIds)
+	rowIndex := make(map[string]int, len(itemIds))
+	for i, arkItemId := range itemIds {
+		rowIndex[arkItemId] = i
+	}
+
+	// variables are stage runs followed by craftings; each relevant item has a constraint of
+	// `produced - consumed >= needed - owned`
+	n := len(stages) + len(formulas)
+	problem := &lp.Problem{
+		Objective:   make([]float64, n),
+		Constraints: make([][]float64, len(itemIds)),
+		Bounds:      make([]float64, len(itemIds)),
+	}
+	for i, arkItemId := range itemIds {
+		problem.Constraints[i] = make([]float64, n)
+		problem.Bounds[i] = float64(req.Needs[arkItemId] - req.Owned[arkItemId])
+	}
+	for j, stage := range stages {
+		problem.Objective[j] = stage.sanity
+		for arkItemId, rate := range stage.rates {
+			if i, ok := rowIndex[arkItemId]; ok {
+				problem.Constraints[i][j] += rate
+			}
+		}
+	}
+	for k, formula := range formulas {
+		j := len(stages) + k
+		problem.Constraints[rowIndex[formula.ArkItemID]][j] += 1
+		for _, cost := range formula.Costs {
+			problem.Constraints[rowIndex[cost.ArkItemID]][j] -= float64(cost.Count)
+		}
+		if totalWeight := formula.TotalWeight(); totalWeight > 0 {
+			for _, outcome := range formula.ExtraOutcomes {
+				if i, ok := rowIndex[outcome.ArkItemID]; ok {
+					problem.Constraints[i][j] += byproductRate * float64(outcome.Weight) / float64(totalWeight)
+				}
+			}
+		}
+	}
+
+	solution, err := lp.Minimize(problem)
+	if errors.Is(err, lp.ErrInfeasible) {
+		return nil, ErrPlanInfeasible
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to solve plan")
+	}
+
+	result := &model.PlannerResult{
+		Stages:    make([]*model.PlannerStage, 0),
+		Craftings: make([]*model.PlannerCrafting, 0),
+	}
+	for j, stage := range stages {
+		times := solution.X[j]
+		if times < plannerEps {
+			continue
+		}
+		items := make(map[string]float64, len(stage.rates))
+		for arkItemId, rate := range stage.rates {
+			items[arkItemId] = util.RoundFloat64(rate*times, 2)
+		}
+		result.Stages = append(result.Stages, &model.PlannerStage{
+			StageID: stage.arkStageId,
+			Times:   util.RoundFloat64(times, 2),
+			Sanity:  util.RoundFloat64(stage.sanity*times, 2),
+			Items:   items,
+		})
+		result.TotalSanity += stage.sanity * times
+	}
+	for k, formula := range formulas {
+		times := solution.X[len(stages)+k]
+		if times < plannerEps {
+			continue
+		}
+		materials := make(map[string]float64, len(formula.Costs))
+		for _, cost := range formula.Costs {
+			materials[cost.ArkItemID] = util.RoundFloat64(float64(cost.Count)*times, 2)
+		}
+		goldCost := float64(formula.GoldCost) * times
+		result.Craftings = append(result.Craftings, &model.PlannerCrafting{
+			ItemID:    formula.ArkItemID,
+			Times:     util.RoundFloat64(times, 2),
+			GoldCost:  util.RoundFloat64(goldCost, 2),
+			Materials: materials,
+		})
+		result.TotalGoldCost += goldCost
+	}
+	sort.Slice(result.Stages, func(i, j int) bool {
+		return result.Stages[i].Sanity > result.Stages[j].Sanity
+	})
+	result.TotalSanity = util.RoundFloat64(result.TotalSanity, 2)
+	result.TotalGoldCost = util.RoundFloat64(result.TotalGoldCost, 2)
+
+	return result, nil
+}
+
+// getEligibleStages returns open stages that drop at least one relevant item, are not excluded, are not gacha box stages,
+// and have a positive sanity cost. Drop rates are only considered if they have at least minTimes samples.
+func (s *Planner) getEligibleStages(ctx context.Context, server string, excludedStages []string, minTimes int, relevantItems map[string]bool) ([]*plannerStage, error) {
+	matrix, err := s.DropMatrixService.GetShimMaxAccumulableDropMatrixResults(ctx, server, false, "", "", null.NewInt(0, false))
+	if err != nil {
+		return nil, err
+	}
+	stagesMap, err := s.StageService.GetStagesMapByArkId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[string]bool, len(excludedStages))
+	for _, arkStageId := range excludedStages {
+		excluded[arkStageId] = true
+	}
+
+	stagesByArkId := make(map[string]*plannerStage)
+	for _, el := range matrix.Matrix {
+		if excluded[el.StageID] || el.Times < minTimes || el.Times == 0 {
+			continue
+		}
+		stage, ok := stagesMap[el.StageID]
+		if !ok || !stage.Sanity.Valid || stage.Sanity.Int64 <= 0 || stage.ExtraProcessType.String == constant.ExtraProcessTypeGachaBox {
+			continue
+		}
+
+		ps, ok := stagesByArkId[el.StageID]
+		if !ok {
+			ps = &plannerStage{
+				arkStageId: el.StageID,
+				sanity:     float64(stage.Sanity.Int64),
+				rates:      make(map[string]float64),
+			}
+			stagesByArkId[el.StageID] = ps
+		}
+		ps.rates[el.ItemID] = float64(el.Quantity) / float64(el.Times)
+	}
+
+	stages := make([]*plannerStage, 0, len(stagesByArkId))
+	for _, stage := range stagesByArkId {
+		for arkItemId, rate := range stage.rates {
+			if relevantItems[arkItemId] && rate > 0 {
+				stages = append(stages, stage)
+				break
+			}
+		}
+	}
+	// keep the variable order stable so that degenerate problems always yield the same plan
+	sort.Slice(stages, func(i, j int) bool {
+		return stages[i].arkStageId < stages[j].arkStageId
+	})
+
+	return stages, nil
+}
@@ -12,9 +12,14 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/uptrace/bun"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/penguin-statistics/backend-next/internal/constant"
 	"github.com/penguin-statistics/backend-next/internal/model/types"
+	"github.com/penguin-statistics/backend-next/internal/pkg/observability"
 	"github.com/penguin-statistics/backend-next/internal/pkg/pgerr"
 	"github.com/penguin-statistics/backend-next/internal/pkg/pgid"
 	"github.com/penguin-statistics/backend-next/internal/repo"
@@ -66,7 +71,7 @@ func NewReport(db *bun.DB, redisClient *redis.Client, natsJs nats.JetStreamConte
 func (s *Report) pipelineAccount(ctx *fiber.Ctx) (accountId int, err error) {
 	account, err := s.AccountService.GetAccountFromRequest(ctx)
 	if err != nil {
-		createdAccount, err := s.AccountService.CreateAccountWithRandomPenguinId(ctx.Context())
+		createdAccount, err := s.AccountService.CreateAccountWithRandomPenguinId(observability.TraceContext(ctx))
 		if err != nil {
 			return 0, err
 		}
@@ -131,19 +136,55 @@ func (s *Report) commitReportTask(ctx *fiber.Ctx, subject string, task *types.Re
 		return "", err
 	}
 
-	pub, err := s.NatsJS.PublishAsync(subject, reportTaskJSON)
+	spanCtx, span := observability.Tracer.Start(observability.TraceContext(ctx), "report.publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("nats"),
+			semconv.MessagingDestinationKey.String(subject),
+			attribute.String("report.task_id", taskId),
+			attribute.Int("report.count", len(task.Reports)),
+		),
+	)
+	defer span.End()
+
+	sourceCategory := observability.SourceCategory(task.Source)
+	failed := func(err error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		observability.ReportFailed.
+			WithLabelValues(task.Server, sourceCategory, observability.ReportPhasePublish).
+			Add(float64(len(task.Reports)))
+	}
+
+	// carry the trace over to the report worker consuming the task
+	msg := nats.NewMsg(subject)
+	msg.Data = reportTaskJSON
+	observability.InjectTraceHeader(spanCtx, msg.Header)
+
+	start := time.Now()
+	pub, err := s.NatsJS.PublishMsgAsync(msg)
 	if err != nil {
+		failed(err)
 		return "", err
 	}
 
 	select {
 	case err := <-pub.Err():
+		failed(err)
 		return "", err
 	case <-pub.Ok():
+		observability.ReportPublishDuration.
+			WithLabelValues(subject).
+			Observe(time.Since(start).Seconds())
+		observability.ReportQueued.
+			WithLabelValues(task.Server, sourceCategory).
+			Add(float64(len(task.Reports)))
 		return taskId, nil
 	case <-ctx.Context().Done():
+		failed(ctx.Context().Err())
 		return "", ctx.Context().Err()
 	case <-time.After(time.Second * 10):
+		failed(ErrNatsTimeout)
 		return "", ErrNatsTimeout
 	}
 }
@@ -157,7 +198,7 @@ func (s *Report) PreprocessAndQueueSingularReport(ctx *fiber.Ctx, req *types.Sin
 	}
 
 	// merge drops with same (dropType, itemId) pair
-	drops, err := s.pipelineMergeDropsAndMapDropTypes(ctx.Context(), req.Drops)
+	drops, err := s.pipelineMergeDropsAndMapDropTypes(observability.TraceContext(ctx), req.Drops)
 	if err != nil {
 		return "", err
 	}
@@ -171,7 +212,7 @@ func (s *Report) PreprocessAndQueueSingularReport(ctx *fiber.Ctx, req *types.Sin
 	}
 
 	// for gachabox drop, we need to aggregate `times` according to `quantity` for report.Drops
-	err = s.pipelineAggregateGachaboxDrops(ctx.Context(), singleReport)
+	err = s.pipelineAggregateGachaboxDrops(observability.TraceContext(ctx), singleReport)
 	if err != nil {
 		return "", err
 	}
@@ -203,7 +244,7 @@ func (s *Report) PreprocessAndQueueBatchReport(ctx *fiber.Ctx, req *types.BatchR
 
 	for i, drop := range req.BatchDrops {
 		// merge drops with same (dropType, itemId) pair
-		drops, err := s.pipelineMergeDropsAndMapDropTypes(ctx.Context(), drop.Drops)
+		drops, err := s.pipelineMergeDropsAndMapDropTypes(observability.TraceContext(ctx), drop.Drops)
 		if err != nil {
 			return "", err
 		}
@@ -217,7 +258,7 @@ func (s *Report) PreprocessAndQueueBatchReport(ctx *fiber.Ctx, req *types.BatchR
 			Metadata:        &metadata,
 		}
 
-		err = s.pipelineAggregateGachaboxDrops(ctx.Context(), report)
+		err = s.pipelineAggregateGachaboxDrops(observability.TraceContext(ctx), report)
 		if err != nil {
 			return "", err
 		}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"time"
 
+	"github.com/penguin-statistics/backend-next/internal/constant"
+	"github.com/penguin-statistics/backend-next/internal/model"
 	"github.com/penguin-statistics/backend-next/internal/model/cache"
 	modelv2 "github.com/penguin-statistics/backend-next/internal/model/v2"
+	"github.com/penguin-statistics/backend-next/internal/pkg/gameday"
 	"github.com/penguin-statistics/backend-next/internal/repo"
+	"github.com/penguin-statistics/backend-next/internal/util"
 )
 
 type SiteStats struct {
@@ -69,3 +73,101 @@ func (s *SiteStats) RefreshShimSiteStats(ctx context.Context, server string) (*m
 	cache.LastModifiedTime.Set("[shimSiteStats#server:"+server+"]", time.Now(), 0)
 	return &results, nil
 }
+
+// Cache: siteStats#server:{server}, 24hrs
+func (s *SiteStats) GetSiteStats(ctx context.Context, server string) (*model.SiteStats, error) {
+	var results model.SiteStats
+	err := cache.SiteStats.Get(server, &results)
+	if err == nil {
+		return &results, nil
+	}
+
+	return s.RefreshSiteStats(ctx, server)
+}
+
+// RefreshSiteStats calculates the expanded site stats over the last constant.SiteStatsDays game days, with daily series
+// as well as per-zone and per-source breakdowns.
+func (s *SiteStats) RefreshSiteStats(ctx context.Context, server string) (*model.SiteStats, error) {
+	valueFunc := func() (*model.SiteStats, error) {
+		now := time.Now()
+		start := gameday.StartTime(server, now).AddDate(0, 0, -(constant.SiteStatsDays - 1))
+		// dates of UTC timestamps shifted by this offset are the game days they belong to
+		_, zoneOffset := now.In(constant.LocMap[server]).Zone()
+		dayOffset := time.Duration(zoneOffset)*time.Second - time.Duration(constant.GameDayStartHour)*time.Hour
+
+		reportedDays, err := s.DropReportRepo.CalcDailyStatsForSiteStats(ctx, server, start, dayOffset)
+		if err != nil {
+			return nil, err
+		}
+		// fill in days without any report so that the series is continuous
+		reportedDaysMap := make(map[string]*model.SiteStatsDay, len(reportedDays))
+		for _, day := range reportedDays {
+			reportedDaysMap[day.Date] = day
+		}
+		daily := make([]*model.SiteStatsDay, 0, constant.SiteStatsDays)
+		for t := start; t.Before(now); t = t.AddDate(0, 0, 1) {
+			date := t.In(constant.LocMap[server]).Format("2006-01-02")
+			if day, ok := reportedDaysMap[date]; ok {
+				daily = append(daily, day)
+			} else {
+				daily = append(daily, &model.SiteStatsDay{Date: date})
+			}
+		}
+
+		uniqueAccounts, err := s.DropReportRepo.CalcUniqueAccountsForSiteStats(ctx, server, start)
+		if err != nil {
+			return nil, err
+		}
+
+		zones, err := s.DropReportRepo.CalcZoneStatsForSiteStats(ctx, server, start)
+		if err != nil {
+			return nil, err
+		}
+
+		sources, err := s.DropReportRepo.CalcSourceStatsForSiteStats(ctx, server, start)
+		if err != nil {
+			return nil, err
+		}
+
+		total := &model.SiteStatsTotal{
+			UniqueAccounts: uniqueAccounts,
+		}
+		for _, day := range daily {
+			day.UnreliableShare = unreliableShare(day.UnreliableReports, day.Reports)
+			total.Reports += day.Reports
+			total.UnreliableReports += day.UnreliableReports
+			total.Times += day.Times
+			total.Sanity += day.Sanity
+		}
+		total.UnreliableShare = unreliableShare(total.UnreliableReports, total.Reports)
+		for _, source := range sources {
+			source.UnreliableShare = unreliableShare(source.UnreliableReports, source.Reports)
+		}
+
+		return &model.SiteStats{
+			Server:    server,
+			StartTime: start.UnixMilli(),
+			Total:     total,
+			Daily:     daily,
+			Zones:     zones,
+			Sources:   sources,
+			UpdatedAt: now.UnixMilli(),
+		}, nil
+	}
+
+	var results model.SiteStats
+	cache.SiteStats.Delete(server)
+	_, err := cache.SiteStats.MutexGetSet(server, &results, valueFunc, 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cache.LastModifiedTime.Set("[siteStats#server:"+server+"]", time.Now(), 0)
+	return &results, nil
+}
+
+func unreliableShare(unreliable, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return util.RoundFloat64(float64(unreliable)/float64(total), 4)
+}
@@ -26,7 +26,7 @@ func NewStage(stageRepo *repo.Stage) *Stage {
 	}
 }
 
-// Cache: (singular) stages, 24hrs
+// Cache: (singular) stages, 24hrs; records last modified time
 func (s *Stage) GetStages(ctx context.Context) ([]*model.Stage, error) {
 	var stages []*model.Stage
 	err := cache.Stages.Get(&stages)
@@ -35,8 +35,12 @@ func (s *Stage) GetStages(ctx context.Context) ([]*model.Stage, error) {
 	}
 
 	stages, err = s.StageRepo.GetStages(ctx)
-	go cache.Stages.Set(stages, time.Hour)
-	return stages, err
+	if err != nil {
+		return nil, err
+	}
+	cache.Stages.Set(stages, time.Hour)
+	cache.LastModifiedTime.Set("[stages]", time.Now(), 0)
+	return stages, nil
 }
 
 func (s *Stage) GetStageById(ctx context.Context, stageId int) (*model.Stage, error) {
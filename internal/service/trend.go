@@ -7,6 +7,7 @@ import (
 	"github.com/ahmetb/go-linq/v3"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
 	"gopkg.in/guregu/null.v3"
 
 	"github.com/penguin-statistics/backend-next/internal/constant"
@@ -51,9 +52,23 @@ func NewTrend(
 	}
 }
 
-// Cache: shimSavedTrendResults#server:{server}, 24hrs, records last modified time
+// Cache: shimSavedTrendResults#server:{server}, fresh for 1 hr and served stale up to 24 hrs, records last modified time
 func (s *Trend) GetShimSavedTrendResults(ctx context.Context, server string) (*modelv2.TrendQueryResult, error) {
-	valueFunc := func() (*modelv2.TrendQueryResult, error) {
+	var shimResult modelv2.TrendQueryResult
+	_, err := cache.ShimSavedTrendResults.StaleGetSet(ctx, server, &shimResult, s.shimSavedTrendResultsFunc(server), constant.ShimResultsFreshTTL, constant.ShimResultsExpireTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &shimResult, nil
+}
+
+// RefreshShimSavedTrendResults recomputes the cached shim results of the server and swaps them in.
+func (s *Trend) RefreshShimSavedTrendResults(ctx context.Context, server string) error {
+	return cache.ShimSavedTrendResults.Revalidate(ctx, server, s.shimSavedTrendResultsFunc(server), constant.ShimResultsExpireTTL)
+}
+
+func (s *Trend) shimSavedTrendResultsFunc(server string) func(ctx context.Context) (*modelv2.TrendQueryResult, error) {
+	return func(ctx context.Context) (*modelv2.TrendQueryResult, error) {
 		queryResult, err := s.getSavedTrendResults(ctx, server, constant.SourceCategoryAll)
 		if err != nil {
 			return nil, err
@@ -64,15 +79,6 @@ func (s *Trend) GetShimSavedTrendResults(ctx context.Context, server string) (*m
 		}
 		return slowShimResult, nil
 	}
-
-	var shimResult modelv2.TrendQueryResult
-	calculated, err := cache.ShimSavedTrendResults.MutexGetSet(server, &shimResult, valueFunc, 24*time.Hour)
-	if err != nil {
-		return nil, err
-	} else if calculated {
-		cache.LastModifiedTime.Set("[shimSavedTrendResults#server:"+server+"]", time.Now(), 0)
-	}
-	return &shimResult, nil
 }
 
 func (s *Trend) GetShimCustomizedTrendResults(
@@ -95,7 +101,7 @@ func (s *Trend) QueryTrend(
 	return s.convertTrendElementsToTrendQueryResult(trendElements)
 }
 
-func (s *Trend) RefreshTrendElements(ctx context.Context, server string, sourceCategories []string) error {
+func (s *Trend) RefreshTrendElements(ctx context.Context, server string, sourceCategories []string, configuredCategories []string) error {
 	maxAccumulableTimeRanges, err := s.TimeRangeService.GetMaxAccumulableTimeRangesByServer(ctx, server)
 	if err != nil {
 		return err
@@ -182,10 +188,13 @@ func (s *Trend) RefreshTrendElements(ctx context.Context, server string, sourceC
 		return errors.Wrap(err, "failed to refresh trend elements")
 	}
 
-	if err := s.TrendElementService.BatchSaveElements(ctx, elements, server); err != nil {
+	if err := s.TrendElementService.BatchSaveElements(ctx, elements, server, sourceCategories, configuredCategories); err != nil {
 		return err
 	}
-	return cache.ShimSavedTrendResults.Delete(server)
+	if !lo.Contains(sourceCategories, constant.SourceCategoryAll) {
+		return nil
+	}
+	return s.RefreshShimSavedTrendResults(ctx, server)
 }
 
 func (s *Trend) getSavedTrendResults(ctx context.Context, server string, sourceCategory string) (*model.TrendQueryResult, error) {
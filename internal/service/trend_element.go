@@ -17,8 +17,8 @@ func NewTrendElement(trendElementRepo *repo.TrendElement) *TrendElement {
 	}
 }
 
-func (s *TrendElement) BatchSaveElements(ctx context.Context, elements []*model.TrendElement, server string) error {
-	return s.TrendElementRepo.BatchSaveElements(ctx, elements, server)
+func (s *TrendElement) BatchSaveElements(ctx context.Context, elements []*model.TrendElement, server string, sourceCategories []string, configuredCategories []string) error {
+	return s.TrendElementRepo.BatchSaveElements(ctx, elements, server, sourceCategories, configuredCategories)
 }
 
 func (s *TrendElement) DeleteByServer(ctx context.Context, server string) error {
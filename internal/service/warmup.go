@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/penguin-statistics/backend-next/internal/config"
+	"github.com/penguin-statistics/backend-next/internal/constant"
+	"github.com/penguin-statistics/backend-next/internal/pkg/async"
+)
+
+const (
+	WarmupStateIdle     = "idle"
+	WarmupStateWarming  = "warming"
+	WarmupStateReady    = "ready"
+	WarmupStateDegraded = "degraded"
+)
+
+// WarmupStatus describes the progress of the warm-up.
+type WarmupStatus struct {
+	// State is idle before the warm-up starts, warming while it runs, then ready once every task succeeded, or
+	// degraded if any task failed or had not finished before the warm-up timed out
+	State      string     `json:"state"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Tasks      int        `json:"tasks"`
+	Completed  int        `json:"completed"`
+	Failed     []string   `json:"failed,omitempty"`
+	// Pending lists the tasks not finished yet; tasks still running when the warm-up times out keep running
+	Pending []string `json:"pending,omitempty"`
+}
+
+type warmupTask struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// Warmup precomputes cached values on startup, so that the first requests after a deploy do not pay for them.
+// The instance reports itself ready only once the warm-up finished or timed out.
+type Warmup struct {
+	Config               *config.Config
+	ItemService          *Item
+	StageService         *Stage
+	ZoneService          *Zone
+	DropMatrixService    *DropMatrix
+	PatternMatrixService *PatternMatrix
+	TrendService         *Trend
+
+	mu      sync.RWMutex
+	status  WarmupStatus
+	pending map[string]struct{}
+}
+
+func NewWarmup(conf *config.Config, itemService *Item, stageService *Stage, zoneService *Zone, dropMatrixService *DropMatrix, patternMatrixService *PatternMatrix, trendService *Trend) *Warmup {
+	return &Warmup{
+		Config:               conf,
+		ItemService:          itemService,
+		StageService:         stageService,
+		ZoneService:          zoneService,
+		DropMatrixService:    dropMatrixService,
+		PatternMatrixService: patternMatrixService,
+		TrendService:         trendService,
+		status: WarmupStatus{
+			State: WarmupStateIdle,
+		},
+	}
+}
+
+// targets maps every warm-up target to its tasks, one per server for server-specific targets
+func (s *Warmup) targets() map[string][]*warmupTask {
+	global := func(name string, run func(ctx context.Context) error) []*warmupTask {
+		return []*warmupTask{{name: name, run: run}}
+	}
+	perServer := func(name string, run func(ctx context.Context, server string) error) []*warmupTask {
+		tasks := make([]*warmupTask, 0, len(constant.Servers))
+		for _, server := range constant.Servers {
+			server := server
+			tasks = append(tasks, &warmupTask{
+				name: name + "." + server,
+				run: func(ctx context.Context) error {
+					return run(ctx, server)
+				},
+			})
+		}
+		return tasks
+	}
+
+	return map[string][]*warmupTask{
+		"items": global("items", func(ctx context.Context) error {
+			_, err := s.ItemService.GetItems(ctx)
+			return err
+		}),
+		"stages": global("stages", func(ctx context.Context) error {
+			_, err := s.StageService.GetStages(ctx)
+			return err
+		}),
+		"zones": global("zones", func(ctx context.Context) error {
+			_, err := s.ZoneService.GetZones(ctx)
+			return err
+		}),
+		"shimItems": global("shimItems", func(ctx context.Context) error {
+			_, err := s.ItemService.GetShimItems(ctx)
+			return err
+		}),
+		"shimZones": global("shimZones", func(ctx context.Context) error {
+			_, err := s.ZoneService.GetShimZones(ctx)
+			return err
+		}),
+		"shimStages": perServer("shimStages", func(ctx context.Context, server string) error {
+			_, err := s.StageService.GetShimStages(ctx, server)
+			return err
+		}),
+		"shimDropMatrix": perServer("shimDropMatrix", func(ctx context.Context, server string) error {
+			for _, showClosedZones := range []bool{true, false} {
+				if _, err := s.DropMatrixService.GetShimMaxAccumulableDropMatrixResults(ctx, server, showClosedZones, "", "", null.NewInt(0, false)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+		"shimPatternMatrix": perServer("shimPatternMatrix", func(ctx context.Context, server string) error {
+			_, err := s.PatternMatrixService.GetShimLatestPatternMatrixResults(ctx, server, null.NewInt(0, false))
+			return err
+		}),
+		"shimTrend": perServer("shimTrend", func(ctx context.Context, server string) error {
+			_, err := s.TrendService.GetShimSavedTrendResults(ctx, server)
+			return err
+		}),
+	}
+}
+
+// Start runs the warm-up in the background.
+func (s *Warmup) Start() {
+	targets := s.targets()
+	tasks := make([]*warmupTask, 0)
+	for _, target := range s.Config.WarmupTargets {
+		if target == "" {
+			continue
+		}
+		targetTasks, ok := targets[target]
+		if !ok {
+			log.Warn().Str("target", target).Msg("unknown warm-up target, skipping")
+			continue
+		}
+		tasks = append(tasks, targetTasks...)
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	s.status.State = WarmupStateWarming
+	s.status.StartedAt = &now
+	s.status.Tasks = len(tasks)
+	s.pending = make(map[string]struct{}, len(tasks))
+	for _, task := range tasks {
+		s.pending[task.name] = struct{}{}
+	}
+	s.mu.Unlock()
+
+	go s.run(tasks)
+}
+
+func (s *Warmup) run(tasks []*warmupTask) {
+	log.Info().Int("tasks", len(tasks)).Dur("timeout", s.Config.WarmupTimeout).Msg("cache warm-up started")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = async.Map(tasks, s.Config.WarmupConcurrency, func(task *warmupTask) (struct{}, error) {
+			started := time.Now()
+			err := task.run(context.Background())
+			completed := s.finish(task.name, err)
+
+			logger := log.With().Str("task", task.name).Int("completed", completed).Int("tasks", len(tasks)).Logger()
+			if err != nil {
+				logger.Error().Err(err).Msg("cache warm-up task failed")
+			} else {
+				logger.Info().Dur("took", time.Since(started)).Msg("cache warm-up task finished")
+			}
+			return struct{}{}, nil
+		})
+	}()
+
+	timer := time.NewTimer(s.Config.WarmupTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.FinishedAt = &now
+	if len(s.status.Failed) == 0 && len(s.pending) == 0 {
+		s.status.State = WarmupStateReady
+		log.Info().Dur("took", now.Sub(*s.status.StartedAt)).Msg("cache warm-up finished")
+	} else {
+		s.status.State = WarmupStateDegraded
+		log.Warn().
+			Dur("took", now.Sub(*s.status.StartedAt)).
+			Strs("failed", s.status.Failed).
+			Int("pending", len(s.pending)).
+			Msg("cache warm-up finished incompletely, serving in a degraded state")
+	}
+}
+
+// finish records the task as finished, returning the number of tasks finished so far.
+func (s *Warmup) finish(name string, err error) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, name)
+	s.status.Completed++
+	if err != nil {
+		s.status.Failed = append(s.status.Failed, name)
+	}
+	return s.status.Completed
+}
+
+// Ready reports whether the warm-up is over, whether it is complete or not.
+func (s *Warmup) Ready() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status.State == WarmupStateReady || s.status.State == WarmupStateDegraded
+}
+
+func (s *Warmup) Status() WarmupStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := s.status
+	status.Failed = append([]string(nil), s.status.Failed...)
+	status.Pending = make([]string, 0, len(s.pending))
+	for name := range s.pending {
+		status.Pending = append(status.Pending, name)
+	}
+	sort.Strings(status.Pending)
+	return status
+}
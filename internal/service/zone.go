@@ -22,7 +22,7 @@ func NewZone(zoneRepo *repo.Zone) *Zone {
 	}
 }
 
-// Cache: (singular) zones, 24hrs
+// Cache: (singular) zones, 24hrs; records last modified time
 func (s *Zone) GetZones(ctx context.Context) ([]*model.Zone, error) {
 	var zones []*model.Zone
 	err := cache.Zones.Get(&zones)
@@ -34,7 +34,8 @@ func (s *Zone) GetZones(ctx context.Context) ([]*model.Zone, error) {
 	if err != nil {
 		return nil, err
 	}
-	go cache.Zones.Set(zones, time.Hour)
+	cache.Zones.Set(zones, time.Hour)
+	cache.LastModifiedTime.Set("[zones]", time.Now(), 0)
 	return zones, nil
 }
 
@@ -60,6 +60,21 @@ func RoundFloat64(f float64, n int) float64 {
 	return math.Round(f*pow10_n) / pow10_n
 }
 
+// WilsonScoreInterval returns the Wilson score interval of a binomial proportion, given the number of successes out of
+// trials and the standard normal quantile z of the desired confidence level. It stays within [0, 1] and remains
+// meaningful for proportions close to 0 or 1 and for few trials, where the normal approximation does not.
+func WilsonScoreInterval(successes int, trials int, z float64) (lower float64, upper float64) {
+	if trials <= 0 {
+		return 0, 1
+	}
+	n := float64(trials)
+	p := float64(successes) / n
+	z2 := z * z
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	margin := z / (1 + z2/n) * math.Sqrt(p*(1-p)/n+z2/(4*n*n))
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
 func (bundle *StatsBundle) calcSquareAvg() float64 {
 	return math.Pow(bundle.Avg, 2) + math.Pow(bundle.StdDev, 2)
 }
@@ -0,0 +1,35 @@
+package util
+
+import (
+	"math"
+	"testing"
+)
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestWilsonScoreInterval(t *testing.T) {
+	tests := []struct {
+		name      string
+		successes int
+		trials    int
+		lower     float64
+		upper     float64
+	}{
+		{name: "zero trials", successes: 0, trials: 0, lower: 0, upper: 1},
+		{name: "no successes", successes: 0, trials: 10, lower: 0, upper: 0.277540},
+		// the lower bound of all successes out of n is n / (n + z^2)
+		{name: "all successes", successes: 10, trials: 10, lower: 0.722460, upper: 1},
+		{name: "known value", successes: 81, trials: 263, lower: 0.255288, upper: 0.366211},
+	}
+	for _, tt := range tests {
+		lower, upper := WilsonScoreInterval(tt.successes, tt.trials, 1.96)
+		if !approxEqual(lower, tt.lower) || !approxEqual(upper, tt.upper) {
+			t.Errorf("%s: expected [%f, %f], got [%f, %f]", tt.name, tt.lower, tt.upper, lower, upper)
+		}
+		if lower < 0 || upper > 1 || lower > upper {
+			t.Errorf("%s: interval [%f, %f] is not within [0, 1]", tt.name, lower, upper)
+		}
+	}
+}
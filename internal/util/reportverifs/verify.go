@@ -2,8 +2,12 @@ package reportverifs
 
 import (
 	"context"
+	"strconv"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/penguin-statistics/backend-next/internal/model/types"
 	"github.com/penguin-statistics/backend-next/internal/pkg/observability"
 )
@@ -32,9 +36,24 @@ func (verifiers ReportVerifiers) Verify(ctx context.Context, reportTask *types.R
 			start := time.Now()
 
 			name := pipe.Name()
-			rejection := pipe.Verify(ctx, report, reportTask)
+			spanCtx, span := observability.Tracer.Start(ctx, "verify."+name, trace.WithAttributes(
+				attribute.Int("report.index", reportIndex),
+			))
+			rejection := pipe.Verify(spanCtx, report, reportTask)
+			if rejection != nil {
+				span.SetAttributes(attribute.Int("report.reliability", rejection.Reliability))
+			}
+			span.End()
+
+			observability.ReportVerifyDuration.
+				WithLabelValues(name).
+				Observe(time.Since(start).Seconds())
 
 			if rejection != nil {
+				observability.ReportRejected.
+					WithLabelValues(name, strconv.Itoa(rejection.Reliability)).
+					Inc()
+
 				violations[reportIndex] = &Violation{
 					Name:      name,
 					Rejection: *rejection,
@@ -42,10 +61,6 @@ func (verifiers ReportVerifiers) Verify(ctx context.Context, reportTask *types.R
 
 				break
 			}
-
-			observability.ReportVerifyDuration.
-				WithLabelValues(name).
-				Observe(time.Since(start).Seconds())
 		}
 	}
 
@@ -0,0 +1,52 @@
+package util
+
+import "math"
+
+// RateSample is the observed quantity of an item over a number of runs of a stage.
+type RateSample struct {
+	Quantity int
+	Times    int
+}
+
+// EstimateGammaPrior fits a Gamma prior for per-run drop rates to the given samples of comparable stages with the
+// method of moments, assuming the quantity of each sample is Poisson distributed given its rate. It returns the
+// prior mean, and the prior strength in pseudo-runs, i.e. the rate parameter of the Gamma distribution.
+//
+// ok is false if the samples are not enough to fit a variance; the prior mean is still returned in that case, but
+// the strength should be substituted with a default one.
+func EstimateGammaPrior(samples []RateSample) (mean float64, strength float64, ok bool) {
+	var sumQuantity, sumTimes, sumTimesSquared float64
+	for _, sample := range samples {
+		sumQuantity += float64(sample.Quantity)
+		sumTimes += float64(sample.Times)
+		sumTimesSquared += float64(sample.Times) * float64(sample.Times)
+	}
+	if sumTimes == 0 {
+		return 0, 0, false
+	}
+	mean = sumQuantity / sumTimes
+	if len(samples) < 3 || mean == 0 {
+		return mean, 0, false
+	}
+
+	// between-stage variance of rates, after removing the expected Poisson sampling variance
+	var weightedSquares float64
+	for _, sample := range samples {
+		if sample.Times == 0 {
+			continue
+		}
+		rate := float64(sample.Quantity) / float64(sample.Times)
+		weightedSquares += float64(sample.Times) * (rate - mean) * (rate - mean)
+	}
+	variance := (weightedSquares - float64(len(samples)-1)*mean) / (sumTimes - sumTimesSquared/sumTimes)
+	if variance <= 0 || math.IsNaN(variance) {
+		// rates are as homogeneous as sampling noise allows: the prior could be arbitrarily strong
+		return mean, math.Inf(1), true
+	}
+	return mean, mean / variance, true
+}
+
+// ShrinkRate returns the posterior mean of a per-run drop rate under a Gamma prior with the given mean and strength.
+func ShrinkRate(quantity int, times int, priorMean float64, priorStrength float64) float64 {
+	return (float64(quantity) + priorStrength*priorMean) / (float64(times) + priorStrength)
+}
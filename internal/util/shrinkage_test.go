@@ -0,0 +1,62 @@
+package util
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEstimateGammaPrior(t *testing.T) {
+	tests := []struct {
+		name     string
+		samples  []RateSample
+		mean     float64
+		strength float64
+		ok       bool
+	}{
+		{name: "no samples", samples: nil, mean: 0, strength: 0, ok: false},
+		{name: "no runs", samples: []RateSample{{0, 0}, {0, 0}, {0, 0}}, mean: 0, strength: 0, ok: false},
+		{name: "too few samples", samples: []RateSample{{10, 100}, {30, 100}}, mean: 0.2, strength: 0, ok: false},
+		{name: "never dropped", samples: []RateSample{{0, 10}, {0, 20}, {0, 30}}, mean: 0, strength: 0, ok: false},
+		// equal rates leave no variance beyond sampling noise
+		{name: "homogeneous", samples: []RateSample{{10, 100}, {20, 200}, {30, 300}}, mean: 0.1, strength: math.Inf(1), ok: true},
+		{name: "heterogeneous", samples: []RateSample{{10, 100}, {50, 100}, {90, 100}}, mean: 0.5, strength: 3.225806, ok: true},
+	}
+	for _, tt := range tests {
+		mean, strength, ok := EstimateGammaPrior(tt.samples)
+		if ok != tt.ok {
+			t.Errorf("%s: expected ok to be %v, got %v", tt.name, tt.ok, ok)
+		}
+		if !approxEqual(mean, tt.mean) {
+			t.Errorf("%s: expected mean %f, got %f", tt.name, tt.mean, mean)
+		}
+		if math.IsInf(tt.strength, 1) {
+			if !math.IsInf(strength, 1) {
+				t.Errorf("%s: expected infinite strength, got %f", tt.name, strength)
+			}
+		} else if !approxEqual(strength, tt.strength) {
+			t.Errorf("%s: expected strength %f, got %f", tt.name, tt.strength, strength)
+		}
+	}
+}
+
+func TestShrinkRate(t *testing.T) {
+	tests := []struct {
+		name          string
+		quantity      int
+		times         int
+		priorMean     float64
+		priorStrength float64
+		rate          float64
+	}{
+		{name: "no runs", quantity: 0, times: 0, priorMean: 0.2, priorStrength: 10, rate: 0.2},
+		{name: "no prior", quantity: 3, times: 10, priorMean: 0.2, priorStrength: 0, rate: 0.3},
+		{name: "halfway", quantity: 3, times: 10, priorMean: 0.2, priorStrength: 10, rate: 0.25},
+		// many runs outweigh the prior
+		{name: "well sampled", quantity: 3000, times: 10000, priorMean: 0.2, priorStrength: 10, rate: 0.2999},
+	}
+	for _, tt := range tests {
+		if rate := ShrinkRate(tt.quantity, tt.times, tt.priorMean, tt.priorStrength); math.Abs(rate-tt.rate) > 1e-4 {
+			t.Errorf("%s: expected rate %f, got %f", tt.name, tt.rate, rate)
+		}
+	}
+}
@@ -4,129 +4,135 @@ import (
 	"context"
 	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/rs/zerolog/log"
 	"go.uber.org/fx"
 
 	"github.com/penguin-statistics/backend-next/internal/config"
 	"github.com/penguin-statistics/backend-next/internal/constant"
+	"github.com/penguin-statistics/backend-next/internal/pkg/leader"
 	"github.com/penguin-statistics/backend-next/internal/service"
 )
 
 type WorkerDeps struct {
 	fx.In
+	Redis                *redis.Client
+	ConfigReloader       *config.Reloader
 	DropMatrixService    *service.DropMatrix
 	PatternMatrixService *service.PatternMatrix
 	TrendService         *service.Trend
 	SiteStatsService     *service.SiteStats
+	FurnitureService     *service.Furniture
 }
 
-type Worker struct {
-	// count counts batches worker has completed so far
-	count int
-
-	// sep describes the separation time in-between different jobs
-	sep time.Duration
-
-	// interval describes the interval in-between different batches of job running
-	interval time.Duration
-
-	// timeout describes the timeout for the worker
-	timeout time.Duration
+// New creates the scheduler of the calculation worker, with a job for every job type and server, and also for every
+// source category in conf.MatrixWorkerSourceCategories for the job types calculating saved elements. Jobs follow
+// changes to the worker settings upon configuration reloads.
+func New(conf *config.Config, deps WorkerDeps) *Scheduler {
+	scheduler := NewScheduler(conf.WorkerConcurrency)
+	scheduler.Sync(jobs(deps.ConfigReloader.Current(), deps))
+	deps.ConfigReloader.OnReload(func(_ *config.Config, next *config.Config) {
+		scheduler.Sync(jobs(next, deps))
+	})
+
+	// recorded in redis rather than in the scheduler only, as the leader may change in-between runs, while the
+	// readiness check of every instance looks at them
+	scheduler.SetOnSuccess(func(job *Job, at time.Time) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		if err := deps.Redis.HSet(ctx, constant.WorkerLastSuccessKey, job.Name, at.Unix()).Err(); err != nil {
+			log.Warn().Err(err).Str("job", job.Name).Msg("failed to record worker job success")
+		}
+	})
 
-	WorkerDeps
+	return scheduler
 }
 
-func Start(conf *config.Config, deps WorkerDeps) {
-	if conf.WorkerEnabled {
-		(&Worker{
-			sep:        conf.WorkerSeparation,
-			interval:   conf.WorkerInterval,
-			timeout:    conf.WorkerTimeout,
-			WorkerDeps: deps,
-		}).do(conf.MatrixWorkerSourceCategories)
-	} else {
-		log.Info().Msg("worker is disabled due to configuration")
+// jobs returns the jobs of the calculation worker according to the configuration
+func jobs(conf *config.Config, deps WorkerDeps) []*Job {
+	var jobs []*Job
+	add := func(jobType string, server string, sourceCategory string, run func(ctx context.Context) error) {
+		name := jobType + "." + server
+		if sourceCategory != "" {
+			name += "." + sourceCategory
+		}
+		interval, ok := conf.WorkerJobIntervals[jobType]
+		if !ok {
+			interval = conf.WorkerInterval
+		}
+		jobs = append(jobs, &Job{
+			Name:           name,
+			Type:           jobType,
+			Server:         server,
+			SourceCategory: sourceCategory,
+			Interval:       interval,
+			Timeout:        conf.WorkerTimeout,
+			MaxRetries:     conf.WorkerJobMaxRetries,
+			RetryBackoff:   conf.WorkerJobRetryBackoff,
+			run:            run,
+		})
 	}
-}
-
-func (w *Worker) do(sourceCategories []string) {
-	ctx := context.Background()
-
-	go func() {
-		time.Sleep(time.Second * 3)
-
-		for {
-			log.Info().
-				Int("count", w.count).
-				Msg("worker batch started")
 
-			func() {
-				sessCtx, sessCancel := context.WithTimeout(ctx, w.timeout)
-				defer func() {
-					w.count++
-					time.Sleep(w.interval)
-					sessCancel()
-				}()
-
-				errChan := make(chan error)
-				go func() {
-					for _, server := range constant.Servers {
-						log.Info().Str("server", server).Str("service", "DropMatrixService").Msg("worker microtask started calculating")
-						if err := w.DropMatrixService.RefreshAllDropMatrixElements(sessCtx, server, sourceCategories); err != nil {
-							log.Error().Err(err).Str("server", server).Str("service", "DropMatrixService").Msg("worker microtask failed")
-							errChan <- err
-							return
-						}
-						log.Info().Str("server", server).Str("service", "DropMatrixService").Msg("worker microtask finished")
-						time.Sleep(w.sep)
-
-						log.Info().Str("server", server).Str("service", "PatternMatrixService").Msg("worker microtask started calculating")
-						if err := w.PatternMatrixService.RefreshAllPatternMatrixElements(sessCtx, server, sourceCategories); err != nil {
-							log.Error().Err(err).Str("server", server).Str("service", "PatternMatrixService").Msg("worker microtask failed")
-							errChan <- err
-							return
-						}
-						log.Info().Str("server", server).Str("service", "PatternMatrixService").Msg("worker microtask finished")
-						time.Sleep(w.sep)
-
-						log.Info().Str("server", server).Str("service", "TrendService").Msg("worker microtask started calculating")
-						if err := w.TrendService.RefreshTrendElements(sessCtx, server, sourceCategories); err != nil {
-							log.Error().Err(err).Str("server", server).Str("service", "TrendService").Msg("worker microtask failed")
-							errChan <- err
-							return
-						}
-						log.Info().Str("server", server).Str("service", "TrendService").Msg("worker microtask finished")
-						time.Sleep(w.sep)
+	for _, server := range constant.Servers {
+		server := server
+		// each run also deletes the elements of the categories removed from the configuration since, which no job
+		// refreshes anymore; the categories are read upon every run, as Sync keeps the runs of existing jobs
+		for _, sourceCategory := range conf.MatrixWorkerSourceCategories {
+			sourceCategories := []string{sourceCategory}
+			add(constant.WorkerJobTypeDropMatrix, server, sourceCategory, func(ctx context.Context) error {
+				return deps.DropMatrixService.RefreshAllDropMatrixElements(ctx, server, sourceCategories, deps.ConfigReloader.Current().MatrixWorkerSourceCategories)
+			})
+			add(constant.WorkerJobTypePatternMatrix, server, sourceCategory, func(ctx context.Context) error {
+				return deps.PatternMatrixService.RefreshAllPatternMatrixElements(ctx, server, sourceCategories, deps.ConfigReloader.Current().MatrixWorkerSourceCategories)
+			})
+			add(constant.WorkerJobTypeTrend, server, sourceCategory, func(ctx context.Context) error {
+				return deps.TrendService.RefreshTrendElements(ctx, server, sourceCategories, deps.ConfigReloader.Current().MatrixWorkerSourceCategories)
+			})
+		}
+		add(constant.WorkerJobTypeSiteStats, server, "", func(ctx context.Context) error {
+			if _, err := deps.SiteStatsService.RefreshShimSiteStats(ctx, server); err != nil {
+				return err
+			}
+			_, err := deps.SiteStatsService.RefreshSiteStats(ctx, server)
+			return err
+		})
+		add(constant.WorkerJobTypeFurniture, server, "", func(ctx context.Context) error {
+			_, err := deps.FurnitureService.RefreshFurnitureDrops(ctx, server)
+			return err
+		})
+	}
+	return jobs
+}
 
-						log.Info().Str("server", server).Str("service", "SiteStatsService").Msg("worker microtask started calculating")
-						if _, err := w.SiteStatsService.RefreshShimSiteStats(sessCtx, server); err != nil {
-							log.Error().Err(err).Str("server", server).Str("service", "SiteStatsService").Msg("worker microtask failed")
-							errChan <- err
-							return
-						}
-						log.Info().Str("server", server).Str("service", "SiteStatsService").Msg("worker microtask finished")
-						time.Sleep(w.sep)
-					}
-					errChan <- nil
-				}()
+func Start(conf *config.Config, scheduler *Scheduler, elector *leader.Elector, lc fx.Lifecycle) {
+	// manual runs are gated as well, so that they never run alongside the runs of the leader. Instances with the
+	// worker disabled never become the leader, and refuse manual triggers.
+	scheduler.SetGate(elector)
 
-				select {
-				case <-sessCtx.Done():
-					log.Error().Err(sessCtx.Err()).Int("count", w.count).Msg("worker timeout reached")
-					return
-				case err := <-errChan:
-					if err != nil {
-						log.Error().Err(err).Int("count", w.count).Msg("worker unexpected error occurred while running batch")
-						return
-					}
-				}
+	if !conf.WorkerEnabled {
+		log.Info().Msg("worker is disabled due to configuration; jobs only run on the leader among the instances with the worker enabled")
+		scheduler.Start(time.Second*3, conf.WorkerSeparation, true)
+		return
+	}
 
-				log.Info().Int("count", w.count).Msg("worker batch finished")
-			}()
-		}
+	// only the elected leader among instances with the worker enabled runs jobs
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		elector.Run(ctx)
+		close(done)
 	}()
-}
-
-func (w *Worker) Count() int {
-	return w.count
+	lc.Append(fx.Hook{
+		OnStop: func(stopCtx context.Context) error {
+			// resign so that another instance takes over right away instead of waiting for the lease to expire
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+
+	scheduler.Start(time.Second*3, conf.WorkerSeparation, false)
 }
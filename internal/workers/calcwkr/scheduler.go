@@ -0,0 +1,528 @@
+package calcwkr
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/penguin-statistics/backend-next/internal/pkg/observability"
+)
+
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+
+	// historySize is the number of latest runs kept for each job
+	historySize = 20
+)
+
+var (
+	ErrJobNotFound      = errors.New("job not found")
+	ErrJobAlreadyQueued = errors.New("job already has a manual run queued")
+	ErrNotLeader        = errors.New("this instance is not the leader")
+)
+
+// Gate decides whether jobs may run on this instance. Jobs run within the returned context, which is expected to be
+// cancelled once this instance is no longer allowed to run them.
+type Gate interface {
+	LeaderContext() (context.Context, bool)
+}
+
+// Job is a calculation running periodically for a server, and for a source category if applicable.
+type Job struct {
+	// Name identifies the job, in the form of type.server[.sourceCategory], e.g. dropMatrix.CN.all
+	Name           string
+	Type           string
+	Server         string
+	SourceCategory string
+
+	// the settings below may be updated by Scheduler.Sync once the job is added, and are then guarded by
+	// Scheduler.mu
+
+	// Interval is the time in-between the end of a run and the start of the next scheduled one
+	Interval time.Duration
+	// Timeout is the timeout of a single attempt
+	Timeout time.Duration
+	// MaxRetries is the number of times a failed attempt is retried within the same run
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubled for every following one
+	RetryBackoff time.Duration
+
+	run func(ctx context.Context) error
+
+	// manual receives manual triggers; it is buffered so that at most one manual run is queued
+	manual chan struct{}
+	// rescheduled is signalled when the interval changes, so that the pending scheduled run is rescheduled
+	rescheduled chan struct{}
+	// removed is closed once the job is removed from the scheduler
+	removed chan struct{}
+
+	// the fields below are guarded by Scheduler.mu
+	paused          bool
+	running         bool
+	nextRunAt       time.Time
+	lastSucceededAt time.Time
+	history         []*JobRun
+}
+
+// JobRun is the record of a single run of a job, including all of its attempts.
+type JobRun struct {
+	Trigger    string    `json:"trigger"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	// DurationMs is the duration of the run in milliseconds, including the waits in-between retries
+	DurationMs int64  `json:"durationMs"`
+	Attempts   int    `json:"attempts"`
+	Succeeded  bool   `json:"succeeded"`
+	Error      string `json:"error,omitempty"`
+}
+
+// JobStatus is a snapshot of the configuration and state of a job.
+type JobStatus struct {
+	Name           string     `json:"name"`
+	Type           string     `json:"type"`
+	Server         string     `json:"server"`
+	SourceCategory string     `json:"sourceCategory,omitempty"`
+	Interval       string     `json:"interval"`
+	Timeout        string     `json:"timeout"`
+	MaxRetries     int        `json:"maxRetries"`
+	Paused         bool       `json:"paused"`
+	Running        bool       `json:"running"`
+	NextRunAt      *time.Time `json:"nextRunAt,omitempty"`
+	// LastSucceededAt is when the latest successful run on this instance finished; it may be older than LastRun
+	LastSucceededAt *time.Time `json:"lastSucceededAt,omitempty"`
+	LastRun         *JobRun    `json:"lastRun,omitempty"`
+	// History lists the latest runs from the latest to the earliest; only present when requesting a single job
+	History []*JobRun `json:"history,omitempty"`
+}
+
+// Scheduler runs every job on its own schedule. Jobs share a limited number of slots, so that no more than a
+// configured number of calculations hit the database at the same time.
+type Scheduler struct {
+	mu      sync.RWMutex
+	jobs    []*Job
+	jobsMap map[string]*Job
+
+	slots chan struct{}
+
+	// gate, if set, lets jobs run only while it allows to
+	gate Gate
+
+	// onSuccess, if set, is called after every successful run
+	onSuccess func(job *Job, at time.Time)
+
+	// the fields below are set by Start, and guarded by mu
+	started      bool
+	paused       bool
+	initialDelay time.Duration
+	separation   time.Duration
+}
+
+// jobSettings is a snapshot of the settings of a job
+type jobSettings struct {
+	interval     time.Duration
+	timeout      time.Duration
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+func NewScheduler(concurrency int) *Scheduler {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Scheduler{
+		jobsMap: make(map[string]*Job),
+		slots:   make(chan struct{}, concurrency),
+	}
+}
+
+// Add registers a job. Jobs added after Start are scheduled right away, with their first run delayed by the
+// initial delay given to Start.
+func (s *Scheduler) Add(job *Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(job, s.initialDelay)
+}
+
+// add must be called with s.mu held
+func (s *Scheduler) add(job *Job, delay time.Duration) {
+	job.manual = make(chan struct{}, 1)
+	job.rescheduled = make(chan struct{}, 1)
+	job.removed = make(chan struct{})
+	s.jobs = append(s.jobs, job)
+	s.jobsMap[job.Name] = job
+	if s.started {
+		job.paused = s.paused
+		go s.loop(job, delay)
+	}
+}
+
+// Remove unregisters a job and stops scheduling it. A run already in progress is not affected.
+func (s *Scheduler) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.remove(name)
+}
+
+// remove must be called with s.mu held
+func (s *Scheduler) remove(name string) error {
+	job, ok := s.jobsMap[name]
+	if !ok {
+		return ErrJobNotFound
+	}
+	delete(s.jobsMap, name)
+	for i, j := range s.jobs {
+		if j == job {
+			s.jobs = append(s.jobs[:i], s.jobs[i+1:]...)
+			break
+		}
+	}
+	close(job.removed)
+	return nil
+}
+
+// Sync makes the jobs of the scheduler match the given ones, identified by name: the settings of the jobs already
+// registered are updated and take effect from their next run, new jobs are added, and jobs not given are removed.
+// A changed interval takes effect right away, by rescheduling the pending scheduled run to the new interval after
+// the end of the previous run.
+// It is used to apply configuration changes without a restart. New jobs added after Start have their first runs
+// separated in the same way as on Start.
+func (s *Scheduler) Sync(jobs []*Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wanted := make(map[string]struct{}, len(jobs))
+	added := 0
+	for _, job := range jobs {
+		wanted[job.Name] = struct{}{}
+		if existing, ok := s.jobsMap[job.Name]; ok {
+			if existing.Interval != job.Interval {
+				select {
+				case existing.rescheduled <- struct{}{}:
+				default:
+				}
+			}
+			existing.Interval = job.Interval
+			existing.Timeout = job.Timeout
+			existing.MaxRetries = job.MaxRetries
+			existing.RetryBackoff = job.RetryBackoff
+			continue
+		}
+		s.add(job, s.initialDelay+time.Duration(added)*s.separation)
+		added++
+	}
+
+	for _, job := range append([]*Job(nil), s.jobs...) {
+		if _, ok := wanted[job.Name]; !ok {
+			_ = s.remove(job.Name)
+		}
+	}
+}
+
+// SetGate makes jobs, both scheduled and manually triggered, run only while the gate allows to. It must be called
+// before Start.
+func (s *Scheduler) SetGate(gate Gate) {
+	s.gate = gate
+}
+
+// SetOnSuccess makes fn be called after every successful run of a job. It must be called before Start.
+func (s *Scheduler) SetOnSuccess(fn func(job *Job, at time.Time)) {
+	s.onSuccess = fn
+}
+
+// Start schedules every job, with the first run of the i-th job delayed by initialDelay + i * separation, so that
+// the first runs follow the order in which jobs are added. If paused is true every job starts paused, and only
+// runs when triggered manually.
+func (s *Scheduler) Start(initialDelay time.Duration, separation time.Duration, paused bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.started = true
+	s.paused = paused
+	s.initialDelay = initialDelay
+	s.separation = separation
+	for i, job := range s.jobs {
+		job.paused = paused
+		go s.loop(job, initialDelay+time.Duration(i)*separation)
+	}
+}
+
+func (s *Scheduler) loop(job *Job, delay time.Duration) {
+	s.setNextRunAt(job, time.Now().Add(delay))
+	timer := time.NewTimer(delay)
+	// waitingSince is when the wait for the pending scheduled run started; it is zero during the initial delay,
+	// which does not depend on the interval
+	var waitingSince time.Time
+
+	for {
+		trigger := TriggerSchedule
+		select {
+		case <-timer.C:
+		case <-job.manual:
+			trigger = TriggerManual
+			stopTimer(timer)
+		case <-job.rescheduled:
+			if !waitingSince.IsZero() {
+				next := waitingSince.Add(s.settings(job).interval)
+				s.setNextRunAt(job, next)
+				stopTimer(timer)
+				timer.Reset(time.Until(next))
+			}
+			continue
+		case <-job.removed:
+			timer.Stop()
+			return
+		}
+
+		if trigger == TriggerSchedule && s.isPaused(job) {
+			waitingSince = time.Now()
+			interval := s.settings(job).interval
+			s.setNextRunAt(job, waitingSince.Add(interval))
+			timer.Reset(interval)
+			continue
+		}
+
+		s.run(job, trigger)
+
+		waitingSince = time.Now()
+		interval := s.settings(job).interval
+		s.setNextRunAt(job, waitingSince.Add(interval))
+		timer.Reset(interval)
+	}
+}
+
+// stopTimer stops the timer, draining its channel if it has already fired, so that it can be reset
+func stopTimer(timer *time.Timer) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+}
+
+// run runs the job, retrying failed attempts. A slot is held for every attempt, but not while backing off in-between
+// attempts, so that a failing job does not hold up the others.
+func (s *Scheduler) run(job *Job, trigger string) {
+	s.slots <- struct{}{}
+	defer func() { <-s.slots }()
+
+	ctx := context.Background()
+	if s.gate != nil {
+		var ok bool
+		if ctx, ok = s.gate.LeaderContext(); !ok {
+			log.Debug().Str("job", job.Name).Str("trigger", trigger).Msg("worker job skipped as this instance is not the leader")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	job.running = true
+	settings := jobSettingsOf(job)
+	s.mu.Unlock()
+
+	logger := log.With().Str("job", job.Name).Str("trigger", trigger).Logger()
+	logger.Info().Msg("worker job started")
+
+	record := &JobRun{
+		Trigger:   trigger,
+		StartedAt: time.Now(),
+	}
+	var err error
+	for attempt := 0; attempt <= settings.maxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := settings.retryBackoff << (attempt - 1)
+			logger.Warn().Err(err).Int("attempt", attempt).Dur("backoff", backoff).Msg("worker job failed, retrying")
+			<-s.slots
+			sleep(ctx, backoff)
+			s.slots <- struct{}{}
+		}
+		if ctx.Err() != nil {
+			// leadership has been lost in-between attempts
+			err = errors.New("leadership lost: " + ctx.Err().Error())
+			break
+		}
+		record.Attempts++
+		err = s.attempt(ctx, job, settings.timeout)
+		if err == nil {
+			break
+		}
+	}
+	record.FinishedAt = time.Now()
+	record.DurationMs = record.FinishedAt.Sub(record.StartedAt).Milliseconds()
+	record.Succeeded = err == nil
+	observability.WorkerJobDuration.
+		WithLabelValues(job.Type, job.Server, job.SourceCategory).
+		Observe(record.FinishedAt.Sub(record.StartedAt).Seconds())
+	if err != nil {
+		observability.WorkerJobFailures.
+			WithLabelValues(job.Type, job.Server, job.SourceCategory).
+			Inc()
+		record.Error = err.Error()
+		logger.Error().Err(err).Int("attempts", record.Attempts).Msg("worker job failed")
+	} else {
+		logger.Info().Int("attempts", record.Attempts).Int64("durationMs", record.DurationMs).Msg("worker job finished")
+		if s.onSuccess != nil {
+			s.onSuccess(job, record.FinishedAt)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job.running = false
+	if record.Succeeded {
+		job.lastSucceededAt = record.FinishedAt
+	}
+	job.history = append(job.history, record)
+	if len(job.history) > historySize {
+		job.history = job.history[len(job.history)-historySize:]
+	}
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+func (s *Scheduler) attempt(ctx context.Context, job *Job, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return job.run(ctx)
+}
+
+// Trigger queues a manual run of the job, which starts as soon as a slot is available. A manual run happens
+// regardless of whether the job is paused, but only on the leader if the scheduler is gated.
+func (s *Scheduler) Trigger(name string) error {
+	job, ok := s.job(name)
+	if !ok {
+		return ErrJobNotFound
+	}
+	if s.gate != nil {
+		if _, ok := s.gate.LeaderContext(); !ok {
+			return ErrNotLeader
+		}
+	}
+	select {
+	case job.manual <- struct{}{}:
+		return nil
+	default:
+		return ErrJobAlreadyQueued
+	}
+}
+
+// SetPaused pauses or resumes the scheduled runs of the job. A run already in progress is not affected.
+func (s *Scheduler) SetPaused(name string, paused bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobsMap[name]
+	if !ok {
+		return ErrJobNotFound
+	}
+	job.paused = paused
+	return nil
+}
+
+// Statuses returns the status of every job, sorted by name.
+func (s *Scheduler) Statuses() []*JobStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]*JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		statuses = append(statuses, s.status(job, false))
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// Status returns the status of the job, along with the history of its latest runs.
+func (s *Scheduler) Status(name string) (*JobStatus, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.jobsMap[name]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return s.status(job, true), nil
+}
+
+// status must be called with s.mu held
+func (s *Scheduler) status(job *Job, withHistory bool) *JobStatus {
+	status := &JobStatus{
+		Name:           job.Name,
+		Type:           job.Type,
+		Server:         job.Server,
+		SourceCategory: job.SourceCategory,
+		Interval:       job.Interval.String(),
+		Timeout:        job.Timeout.String(),
+		MaxRetries:     job.MaxRetries,
+		Paused:         job.paused,
+		Running:        job.running,
+	}
+	if !job.nextRunAt.IsZero() {
+		nextRunAt := job.nextRunAt
+		status.NextRunAt = &nextRunAt
+	}
+	if !job.lastSucceededAt.IsZero() {
+		lastSucceededAt := job.lastSucceededAt
+		status.LastSucceededAt = &lastSucceededAt
+	}
+	if len(job.history) > 0 {
+		lastRun := *job.history[len(job.history)-1]
+		status.LastRun = &lastRun
+	}
+	if withHistory {
+		status.History = make([]*JobRun, 0, len(job.history))
+		for i := len(job.history) - 1; i >= 0; i-- {
+			run := *job.history[i]
+			status.History = append(status.History, &run)
+		}
+	}
+	return status
+}
+
+func (s *Scheduler) job(name string) (*Job, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.jobsMap[name]
+	return job, ok
+}
+
+func (s *Scheduler) settings(job *Job) jobSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return jobSettingsOf(job)
+}
+
+// jobSettingsOf must be called with s.mu held
+func jobSettingsOf(job *Job) jobSettings {
+	return jobSettings{
+		interval:     job.Interval,
+		timeout:      job.Timeout,
+		maxRetries:   job.MaxRetries,
+		retryBackoff: job.RetryBackoff,
+	}
+}
+
+func (s *Scheduler) isPaused(job *Job) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return job.paused
+}
+
+func (s *Scheduler) setNextRunAt(job *Job, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job.nextRunAt = t
+}
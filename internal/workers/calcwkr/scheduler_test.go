@@ -0,0 +1,230 @@
+package calcwkr
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testGate allows jobs to run until its context is cancelled
+type testGate struct {
+	ctx context.Context
+}
+
+func (g *testGate) LeaderContext() (context.Context, bool) {
+	return g.ctx, g.ctx.Err() == nil
+}
+
+func newTestJob(name string, interval time.Duration, run func(ctx context.Context) error) *Job {
+	return &Job{
+		Name:     name,
+		Type:     "test",
+		Server:   "CN",
+		Interval: interval,
+		Timeout:  time.Second,
+		run:      run,
+	}
+}
+
+// waitForRun waits for the job to have finished the given number of runs, and returns its status.
+func waitForRun(t *testing.T, s *Scheduler, name string, runs int) *JobStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		status, err := s.Status(name)
+		if err != nil {
+			t.Fatalf("expected job %s to exist, got %v", name, err)
+		}
+		if len(status.History) >= runs {
+			return status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d runs of job %s", runs, name)
+	return nil
+}
+
+func TestSchedulerInterval(t *testing.T) {
+	s := NewScheduler(1)
+	s.Add(newTestJob("interval", 20*time.Millisecond, func(ctx context.Context) error {
+		return nil
+	}))
+	started := time.Now()
+	s.Start(0, 0, false)
+	defer s.Sync(nil)
+
+	waitForRun(t, s, "interval", 3)
+	// runs are separated by the interval from the end of the previous one
+	if elapsed := time.Since(started); elapsed < 40*time.Millisecond {
+		t.Errorf("expected 3 runs to take at least 40ms, took %s", elapsed)
+	}
+}
+
+func TestSchedulerSyncInterval(t *testing.T) {
+	run := func(ctx context.Context) error {
+		return nil
+	}
+	s := NewScheduler(1)
+	s.Add(newTestJob("sync", time.Hour, run))
+	s.Start(0, 0, false)
+	defer s.Sync(nil)
+
+	waitForRun(t, s, "sync", 1)
+	// the pending run is rescheduled without waiting for the previous interval to elapse
+	s.Sync([]*Job{newTestJob("sync", 20*time.Millisecond, run)})
+	status := waitForRun(t, s, "sync", 2)
+	if status.Interval != "20ms" {
+		t.Errorf("expected the interval to be updated to 20ms, got %s", status.Interval)
+	}
+}
+
+func TestSchedulerPaused(t *testing.T) {
+	var runs int32
+	s := NewScheduler(1)
+	s.Add(newTestJob("paused", 10*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	}))
+	s.Start(0, 0, true)
+	defer s.Sync(nil)
+
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&runs); n != 0 {
+		t.Fatalf("expected a paused job not to run on schedule, ran %d times", n)
+	}
+	if err := s.Trigger("paused"); err != nil {
+		t.Fatalf("expected no error triggering the job, got %v", err)
+	}
+	if status := waitForRun(t, s, "paused", 1); status.LastRun.Trigger != TriggerManual {
+		t.Errorf("expected a manual run, got %s", status.LastRun.Trigger)
+	}
+}
+
+func TestSchedulerRetry(t *testing.T) {
+	var calls int32
+	s := NewScheduler(1)
+	job := newTestJob("retry", time.Hour, func(ctx context.Context) error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	job.MaxRetries = 2
+	job.RetryBackoff = time.Millisecond
+	s.Add(job)
+	s.Start(0, 0, false)
+	defer s.Sync(nil)
+
+	status := waitForRun(t, s, "retry", 1)
+	if !status.LastRun.Succeeded || status.LastRun.Attempts != 3 {
+		t.Errorf("expected a successful run after 3 attempts, got succeeded = %v after %d attempts",
+			status.LastRun.Succeeded, status.LastRun.Attempts)
+	}
+	if status.LastSucceededAt == nil {
+		t.Errorf("expected the last successful run to be recorded")
+	}
+}
+
+func TestSchedulerRetryExhausted(t *testing.T) {
+	s := NewScheduler(1)
+	job := newTestJob("exhausted", time.Hour, func(ctx context.Context) error {
+		return errors.New("failed")
+	})
+	job.MaxRetries = 1
+	job.RetryBackoff = time.Millisecond
+	s.Add(job)
+	s.Start(0, 0, false)
+	defer s.Sync(nil)
+
+	status := waitForRun(t, s, "exhausted", 1)
+	if status.LastRun.Succeeded || status.LastRun.Attempts != 2 || status.LastRun.Error != "failed" {
+		t.Errorf("expected a failed run after 2 attempts, got succeeded = %v after %d attempts with error %q",
+			status.LastRun.Succeeded, status.LastRun.Attempts, status.LastRun.Error)
+	}
+}
+
+func TestSchedulerConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	run := func(ctx context.Context) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	}
+
+	s := NewScheduler(2)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		s.Add(newTestJob(name, time.Hour, run))
+	}
+	s.Start(0, 0, false)
+	defer s.Sync(nil)
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		waitForRun(t, s, name, 1)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if maxRunning != 2 {
+		t.Errorf("expected at most 2 jobs to run at the same time, and 2 to do so, got %d", maxRunning)
+	}
+}
+
+func TestSchedulerBackoffReleasesSlot(t *testing.T) {
+	var failed int32
+	s := NewScheduler(1)
+	failing := newTestJob("failing", time.Hour, func(ctx context.Context) error {
+		if atomic.AddInt32(&failed, 1) == 1 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	failing.MaxRetries = 1
+	failing.RetryBackoff = time.Second
+	s.Add(failing)
+	s.Add(newTestJob("other", time.Hour, func(ctx context.Context) error {
+		return nil
+	}))
+	// the other job starts once the failing one is backing off
+	s.Start(0, 50*time.Millisecond, false)
+	defer s.Sync(nil)
+
+	waitForRun(t, s, "other", 1)
+	if atomic.LoadInt32(&failed) != 1 {
+		t.Errorf("expected the other job to run while the failing one backs off")
+	}
+}
+
+func TestSchedulerBackoffInterruptedByLeadershipLoss(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewScheduler(1)
+	s.SetGate(&testGate{ctx: ctx})
+	job := newTestJob("leader", time.Hour, func(ctx context.Context) error {
+		return errors.New("failed")
+	})
+	job.MaxRetries = 1
+	job.RetryBackoff = time.Hour
+	s.Add(job)
+	s.Start(0, 0, false)
+	defer s.Sync(nil)
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	status := waitForRun(t, s, "leader", 1)
+	if status.LastRun.Attempts != 1 || !strings.HasPrefix(status.LastRun.Error, "leadership lost") {
+		t.Errorf("expected the run to stop backing off once leadership is lost, got %d attempts with error %q",
+			status.LastRun.Attempts, status.LastRun.Error)
+	}
+}
@@ -0,0 +1,73 @@
+package exportwkr
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.uber.org/fx"
+
+	"github.com/penguin-statistics/backend-next/internal/config"
+	"github.com/penguin-statistics/backend-next/internal/service"
+)
+
+type WorkerDeps struct {
+	fx.In
+	ExportService *service.Export
+}
+
+type Worker struct {
+	// count counts exports worker has completed so far
+	count int
+
+	// interval describes the interval in-between different export runs. It also acts as the timeout of a single run.
+	interval time.Duration
+
+	WorkerDeps
+}
+
+func Start(conf *config.Config, deps WorkerDeps) {
+	if conf.ExportEnabled {
+		(&Worker{
+			interval:   conf.ExportInterval,
+			WorkerDeps: deps,
+		}).do()
+	} else {
+		log.Info().Msg("export worker is disabled due to configuration")
+	}
+}
+
+func (w *Worker) do() {
+	ctx := context.Background()
+
+	go func() {
+		// let the calculation worker finish its first batch before exporting
+		time.Sleep(time.Minute)
+
+		for {
+			log.Info().Int("count", w.count).Msg("export worker run started")
+
+			func() {
+				sessCtx, sessCancel := context.WithTimeout(ctx, w.interval)
+				defer func() {
+					w.count++
+					sessCancel()
+				}()
+
+				started := time.Now()
+				if err := w.ExportService.Run(sessCtx, started); err != nil {
+					log.Error().Err(err).Int("count", w.count).Msg("export worker unexpected error occurred while running export")
+					return
+				}
+
+				log.Info().Int("count", w.count).Dur("duration", time.Since(started)).Msg("export worker run finished")
+			}()
+
+			time.Sleep(w.interval)
+		}
+	}()
+}
+
+func (w *Worker) Count() int {
+	return w.count
+}
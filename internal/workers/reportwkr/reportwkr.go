@@ -9,10 +9,15 @@ import (
 	"github.com/nats-io/nats.go"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"gopkg.in/guregu/null.v3"
 
 	"github.com/penguin-statistics/backend-next/internal/config"
+	"github.com/penguin-statistics/backend-next/internal/constant"
 	"github.com/penguin-statistics/backend-next/internal/model"
 	"github.com/penguin-statistics/backend-next/internal/model/types"
 	"github.com/penguin-statistics/backend-next/internal/pkg/observability"
@@ -64,7 +69,7 @@ func Start(conf *config.Config, deps WorkerDeps) {
 func (w *Worker) Consumer(ctx context.Context, ch chan error) error {
 	msgChan := make(chan *nats.Msg, 16)
 
-	_, err := w.ReportServices.NatsJS.ChanQueueSubscribe("REPORT.*", "penguin-reports", msgChan, nats.AckWait(time.Second*10), nats.MaxAckPending(128))
+	_, err := w.ReportServices.NatsJS.ChanQueueSubscribe("REPORT.*", constant.ReportConsumerName, msgChan, nats.AckWait(time.Second*10), nats.MaxAckPending(128))
 	if err != nil {
 		log.Err(err).Msg("failed to subscribe to REPORT.*")
 		return err
@@ -95,15 +100,34 @@ func (w *Worker) Consumer(ctx context.Context, ch chan error) error {
 					return
 				}
 
+				// continue the trace of the request which published the task
+				spanCtx, span := observability.Tracer.Start(observability.ExtractTraceHeader(taskCtx, msg.Header), "report.consume",
+					trace.WithSpanKind(trace.SpanKindConsumer),
+					trace.WithAttributes(
+						semconv.MessagingSystemKey.String("nats"),
+						semconv.MessagingDestinationKey.String(msg.Subject),
+						semconv.MessagingOperationProcess,
+						attribute.String("report.task_id", reportTask.TaskID),
+						attribute.Int("report.count", len(reportTask.Reports)),
+					),
+				)
+				defer span.End()
+
 				start := time.Now()
+				sourceCategory := observability.SourceCategory(reportTask.Source)
 				defer func() {
 					observability.ReportConsumeDuration.
-						WithLabelValues().
+						WithLabelValues(reportTask.Server, sourceCategory).
 						Observe(time.Since(start).Seconds())
 				}()
 
-				err = w.consumeReport(taskCtx, reportTask)
+				err = w.consumeReport(spanCtx, reportTask)
 				if err != nil {
+					span.RecordError(err)
+					span.SetStatus(codes.Error, err.Error())
+					observability.ReportFailed.
+						WithLabelValues(reportTask.Server, sourceCategory, observability.ReportPhaseConsume).
+						Add(float64(len(reportTask.Reports)))
 					log.Error().
 						Err(err).
 						Str("taskId", reportTask.TaskID).
@@ -113,6 +137,10 @@ func (w *Worker) Consumer(ctx context.Context, ch chan error) error {
 					return
 				}
 
+				observability.ReportPersisted.
+					WithLabelValues(reportTask.Server, sourceCategory).
+					Add(float64(len(reportTask.Reports)))
+
 				log.Info().
 					Str("taskId", reportTask.TaskID).
 					Dur("duration", time.Since(start)).
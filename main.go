@@ -1,6 +1,9 @@
 package main
 
 import (
+	"os"
+
+	"github.com/penguin-statistics/backend-next/cmd/migrate"
 	"github.com/penguin-statistics/backend-next/cmd/service"
 )
 
@@ -24,5 +27,10 @@ import (
 // @name                        Authorization
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		migrate.Run(os.Args[2:])
+		return
+	}
+
 	service.Bootstrap()
 }